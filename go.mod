@@ -4,7 +4,10 @@ go 1.25.1
 
 require (
 	github.com/PuerkitoBio/goquery v1.10.3
+	github.com/gin-gonic/gin v1.11.0
 	github.com/xuri/excelize/v2 v2.9.1
+	go.etcd.io/bbolt v1.4.3
+	go.uber.org/zap v1.27.0
 )
 
 require (
@@ -14,7 +17,6 @@ require (
 	github.com/cloudwego/base64x v0.1.6 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.8 // indirect
 	github.com/gin-contrib/sse v1.1.0 // indirect
-	github.com/gin-gonic/gin v1.11.0 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/go-playground/validator/v10 v10.27.0 // indirect
@@ -38,7 +40,6 @@ require (
 	github.com/xuri/nfp v0.0.1 // indirect
 	go.uber.org/mock v0.5.0 // indirect
 	go.uber.org/multierr v1.10.0 // indirect
-	go.uber.org/zap v1.27.0 // indirect
 	golang.org/x/arch v0.20.0 // indirect
 	golang.org/x/crypto v0.40.0 // indirect
 	golang.org/x/mod v0.25.0 // indirect
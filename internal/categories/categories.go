@@ -0,0 +1,126 @@
+package categories
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Category - категория закупок из файла категорий
+type Category struct {
+	// Name - ключ категории в форме, статистике и API (vent, doors...)
+	Name string `json:"name"`
+	// Title и Description выводятся в форме поиска
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Sheet - название листа в Excel
+	Sheet string `json:"sheet"`
+	// Default - категория выбрана в форме по умолчанию
+	Default bool `json:"default"`
+	// Search - поисковые строки по площадкам (ключ - sources.TenderSource.Name())
+	Search map[string][]string `json:"search"`
+	// Include и Exclude - регулярные выражения для отсева названий,
+	// применяются вместе с файлом правил (см. пакет rules)
+	Include string `json:"include"`
+	Exclude string `json:"exclude"`
+	// MinPrice - минимальная цена по умолчанию
+	MinPrice int `json:"min_price"`
+}
+
+// SearchStrings возвращает поисковые строки для площадки
+func (c *Category) SearchStrings(source string) []string {
+	return c.Search[source]
+}
+
+// List - категории в порядке вывода
+type List []*Category
+
+func (l List) Get(name string) *Category {
+	for _, category := range l {
+		if category.Name == name {
+			return category
+		}
+	}
+	return nil
+}
+
+func (l List) Names() []string {
+	names := make([]string, len(l))
+	for i, category := range l {
+		names[i] = category.Name
+	}
+	return names
+}
+
+var (
+	namePattern       = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	sheetInvalidChars = `:\/?*[]`
+)
+
+// Load читает и проверяет файл категорий
+func Load(filename string) (List, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var list List
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	if err := list.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return list, nil
+}
+
+func (l List) compile() error {
+	if len(l) == 0 {
+		return fmt.Errorf("не задано ни одной категории")
+	}
+
+	names := make(map[string]bool)
+	sheets := make(map[string]bool)
+
+	for i, category := range l {
+		if !namePattern.MatchString(category.Name) {
+			return fmt.Errorf("категория %d: некорректный ключ %q (допустимы a-z, 0-9 и _)", i+1, category.Name)
+		}
+		if names[category.Name] {
+			return fmt.Errorf("категория %q указана дважды", category.Name)
+		}
+		names[category.Name] = true
+
+		if category.Title == "" {
+			category.Title = category.Name
+		}
+		if category.Sheet == "" {
+			category.Sheet = category.Title
+		}
+		if utf8.RuneCountInString(category.Sheet) > 31 || strings.ContainsAny(category.Sheet, sheetInvalidChars) {
+			return fmt.Errorf("категория %q: недопустимое название листа Excel %q", category.Name, category.Sheet)
+		}
+		if sheets[category.Sheet] {
+			return fmt.Errorf("категория %q: лист %q уже используется", category.Name, category.Sheet)
+		}
+		sheets[category.Sheet] = true
+
+		if category.MinPrice < 0 {
+			return fmt.Errorf("категория %q: отрицательная минимальная цена", category.Name)
+		}
+
+		if _, err := regexp.Compile(category.Include); err != nil {
+			return fmt.Errorf("категория %q: include: %w", category.Name, err)
+		}
+		if _, err := regexp.Compile(category.Exclude); err != nil {
+			return fmt.Errorf("категория %q: exclude: %w", category.Name, err)
+		}
+	}
+
+	return nil
+}
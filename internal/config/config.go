@@ -0,0 +1,360 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"tendertracker/internal/fetcher"
+	"tendertracker/internal/notify"
+	"tendertracker/internal/sources"
+	"tendertracker/internal/telegram"
+)
+
+// Config - настройки приложения. Значения берутся по умолчанию, затем из файла,
+// переменных окружения и флагов командной строки, каждый следующий перекрывает предыдущий
+type Config struct {
+	Server  ServerConfig  `json:"server"`
+	Log     LogConfig     `json:"log"`
+	Paths   PathsConfig   `json:"paths"`
+	Search  SearchConfig  `json:"search"`
+	Sources SourcesConfig `json:"sources"`
+	// SMTP и Telegram - каналы уведомлений, без настроек канал выключен
+	SMTP     SMTPConfig     `json:"smtp"`
+	Telegram TelegramConfig `json:"telegram"`
+}
+
+type ServerConfig struct {
+	// Addr - адрес веб-сервера
+	Addr         string `json:"addr"`
+	StaticDir    string `json:"static_dir"`
+	TemplatesDir string `json:"templates_dir"`
+}
+
+type LogConfig struct {
+	// Level - debug, info, warn или error
+	Level string `json:"level"`
+}
+
+type PathsConfig struct {
+	Categories string `json:"categories"`
+	// Rules - правила отсева закупок по названию. Если файла нет, отсев
+	// идет только по include и exclude из файла категорий
+	Rules    string `json:"rules"`
+	Database string `json:"database"`
+	Reports  string `json:"reports"`
+	// Documents - каталог скачанных документов закупок, по папке на закупку
+	Documents string `json:"documents"`
+}
+
+type SearchConfig struct {
+	// Timeout - ограничение времени поиска по умолчанию, 0 - без ограничения
+	Timeout Duration `json:"timeout"`
+	// ReportRetention - сколько хранить отчеты
+	ReportRetention Duration `json:"report_retention"`
+	// JobRetention - сколько хранить завершенные задачи поиска
+	JobRetention Duration `json:"job_retention"`
+	// RulesReload - как часто проверять файл правил на изменения, 0 - не проверять
+	RulesReload Duration `json:"rules_reload"`
+}
+
+// SourcesConfig - настройки площадок, ключи совпадают с sources.TenderSource.Name()
+type SourcesConfig struct {
+	ZakupkiGovRu SourceConfig `json:"ZakupkiGovRu"`
+	Sber         SourceConfig `json:"Sber"`
+	Bidzaar      SourceConfig `json:"Bidzaar"`
+}
+
+// SourceConfig - настройки обращения к площадке
+type SourceConfig struct {
+	HTTPTimeout Duration `json:"http_timeout"`
+	PageDelay   Duration `json:"page_delay"`
+	// MaxPages - предел страниц на одну поисковую строку, 0 - без предела
+	MaxPages int `json:"max_pages"`
+	// LookBackDays - за сколько дней назад искать закупки
+	LookBackDays int `json:"look_back_days"`
+	// RequestsPerSecond - средняя частота запросов к площадке, 0 - без ограничения
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	// Burst - сколько запросов можно отправить подряд без ожидания
+	Burst int `json:"burst"`
+	// MaxConcurrent - предел одновременных запросов к площадке, 0 - без предела
+	MaxConcurrent int `json:"max_concurrent"`
+	// MaxAttempts - попыток на один запрос при сетевых ошибках и ответах 429, 503
+	MaxAttempts int `json:"max_attempts"`
+	// DetailWorkers - сколько страниц извещений загружать одновременно,
+	// DetailCacheTTL - сколько хранить их разбор. Сейчас используются только ZakupkiGovRu
+	DetailWorkers  int      `json:"detail_workers"`
+	DetailCacheTTL Duration `json:"detail_cache_ttl"`
+}
+
+// SMTPConfig - почтовый сервер для рассылки дайджестов. Пароль лучше
+// задавать переменной TENDERTRACKER_SMTP_PASSWORD, а не в файле
+type SMTPConfig struct {
+	Host string `json:"host"`
+	// Port - 0 означает 25
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+	// TLS - подключение сразу по TLS (обычно порт 465), иначе STARTTLS
+	TLS bool `json:"tls"`
+}
+
+// Settings переводит настройки почты в notify.SMTPConfig
+func (c SMTPConfig) Settings() notify.SMTPConfig {
+	return notify.SMTPConfig{
+		Host:     c.Host,
+		Port:     c.Port,
+		Username: c.Username,
+		Password: c.Password,
+		From:     c.From,
+		TLS:      c.TLS,
+	}
+}
+
+// TelegramConfig - бот Telegram. Без токена бот не запускается
+type TelegramConfig struct {
+	Token string `json:"token"`
+	// APIURL - адрес Bot API, по умолчанию api.telegram.org
+	APIURL string `json:"api_url"`
+	// AllowedChats - чаты, которым бот отвечает и шлет уведомления. Команды
+	// из других чатов отклоняются
+	AllowedChats []int64 `json:"allowed_chats"`
+}
+
+// Settings переводит настройки бота в telegram.Config
+func (c TelegramConfig) Settings() telegram.Config {
+	return telegram.Config{Token: c.Token, APIURL: c.APIURL, AllowedChats: c.AllowedChats}
+}
+
+// Settings переводит настройки площадки в sources.Settings с общим HTTP-клиентом
+// и постоянным кэшем (может быть nil)
+func (c SourceConfig) Settings(client *fetcher.Fetcher, cache sources.Cache) sources.Settings {
+	return sources.Settings{
+		HTTPTimeout:       c.HTTPTimeout.Duration(),
+		PageDelay:         c.PageDelay.Duration(),
+		MaxPages:          c.MaxPages,
+		LookBackDays:      c.LookBackDays,
+		RequestsPerSecond: c.RequestsPerSecond,
+		Burst:             c.Burst,
+		MaxConcurrent:     c.MaxConcurrent,
+		MaxAttempts:       c.MaxAttempts,
+		Fetcher:           client,
+		DetailWorkers:     c.DetailWorkers,
+		DetailCacheTTL:    c.DetailCacheTTL.Duration(),
+		Cache:             cache,
+	}
+}
+
+// named возвращает настройки площадок вместе с их ключами
+func (c *SourcesConfig) named() []struct {
+	name   string
+	config *SourceConfig
+} {
+	return []struct {
+		name   string
+		config *SourceConfig
+	}{
+		{"ZakupkiGovRu", &c.ZakupkiGovRu},
+		{"Sber", &c.Sber},
+		{"Bidzaar", &c.Bidzaar},
+	}
+}
+
+// Default - настройки, с которыми приложение работало до появления файла настроек
+func Default() Config {
+	source := SourceConfig{
+		HTTPTimeout:       Duration(60 * time.Second),
+		PageDelay:         Duration(time.Second),
+		LookBackDays:      365,
+		RequestsPerSecond: 2,
+		Burst:             2,
+		MaxConcurrent:     2,
+		MaxAttempts:       4,
+		DetailWorkers:     4,
+		DetailCacheTTL:    Duration(30 * 24 * time.Hour),
+	}
+
+	// На zakupki.gov.ru кроме выдачи открывается извещение каждой закупки
+	govru := source
+	govru.RequestsPerSecond = 4
+	govru.Burst = 4
+	govru.MaxConcurrent = 4
+
+	sber := source
+	sber.MaxPages = 50
+	sber.LookBackDays = 730
+
+	bidzaar := source
+	bidzaar.MaxPages = 50
+
+	return Config{
+		Server: ServerConfig{
+			Addr:         ":8081",
+			StaticDir:    "./static",
+			TemplatesDir: "templates",
+		},
+		Log: LogConfig{Level: "debug"},
+		Paths: PathsConfig{
+			Categories: "categories.json",
+			Rules:      "rules.txt",
+			Database:   "data/tenders.db",
+			Reports:    "data/reports",
+			Documents:  "data/documents",
+		},
+		Search: SearchConfig{
+			Timeout:         Duration(30 * time.Minute),
+			ReportRetention: Duration(30 * 24 * time.Hour),
+			JobRetention:    Duration(24 * time.Hour),
+			RulesReload:     Duration(5 * time.Second),
+		},
+		Sources: SourcesConfig{
+			ZakupkiGovRu: govru,
+			Sber:         sber,
+			Bidzaar:      bidzaar,
+		},
+	}
+}
+
+// Validate проверяет настройки и возвращает все найденные ошибки
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Addr == "" {
+		fail("server.addr: не задан адрес веб-сервера")
+	}
+	if c.Server.StaticDir == "" {
+		fail("server.static_dir: не задан каталог статики")
+	}
+	if c.Server.TemplatesDir == "" {
+		fail("server.templates_dir: не задан каталог шаблонов")
+	}
+
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		fail("log.level: неизвестный уровень %q, допустимо debug, info, warn, error", c.Log.Level)
+	}
+
+	if c.Paths.Categories == "" {
+		fail("paths.categories: не задан файл категорий")
+	}
+	if c.Paths.Rules == "" {
+		fail("paths.rules: не задан файл правил отсева")
+	}
+	if c.Paths.Database == "" {
+		fail("paths.database: не задан файл базы тендеров")
+	}
+	if c.Paths.Reports == "" {
+		fail("paths.reports: не задан каталог отчетов")
+	}
+	if c.Paths.Documents == "" {
+		fail("paths.documents: не задан каталог документов закупок")
+	}
+
+	if c.Search.Timeout < 0 {
+		fail("search.timeout: отрицательное значение")
+	}
+	if c.Search.ReportRetention <= 0 {
+		fail("search.report_retention: должно быть больше нуля")
+	}
+	if c.Search.JobRetention <= 0 {
+		fail("search.job_retention: должно быть больше нуля")
+	}
+	if c.Search.RulesReload < 0 {
+		fail("search.rules_reload: отрицательное значение")
+	}
+
+	for _, source := range c.Sources.named() {
+		if source.config.HTTPTimeout <= 0 {
+			fail("sources.%s.http_timeout: должно быть больше нуля", source.name)
+		}
+		if source.config.PageDelay < 0 {
+			fail("sources.%s.page_delay: отрицательное значение", source.name)
+		}
+		if source.config.MaxPages < 0 {
+			fail("sources.%s.max_pages: отрицательное значение", source.name)
+		}
+		if source.config.LookBackDays <= 0 {
+			fail("sources.%s.look_back_days: должно быть больше нуля", source.name)
+		}
+		if source.config.RequestsPerSecond < 0 {
+			fail("sources.%s.requests_per_second: отрицательное значение", source.name)
+		}
+		if source.config.Burst < 0 {
+			fail("sources.%s.burst: отрицательное значение", source.name)
+		}
+		if source.config.MaxConcurrent < 0 {
+			fail("sources.%s.max_concurrent: отрицательное значение", source.name)
+		}
+		if source.config.MaxAttempts <= 0 {
+			fail("sources.%s.max_attempts: должно быть больше нуля", source.name)
+		}
+		if source.config.DetailWorkers <= 0 {
+			fail("sources.%s.detail_workers: должно быть больше нуля", source.name)
+		}
+		if source.config.DetailCacheTTL < 0 {
+			fail("sources.%s.detail_cache_ttl: отрицательное значение", source.name)
+		}
+	}
+
+	if c.SMTP.Port < 0 || c.SMTP.Port > 65535 {
+		fail("smtp.port: некорректный порт %d", c.SMTP.Port)
+	}
+	if c.SMTP.Host != "" && c.SMTP.From == "" {
+		fail("smtp.from: не задан адрес отправителя при заданном smtp.host")
+	}
+	if c.SMTP.From != "" {
+		if c.SMTP.Host == "" {
+			fail("smtp.host: не задан почтовый сервер при заданном smtp.from")
+		}
+		if _, err := mail.ParseAddress(c.SMTP.From); err != nil {
+			fail("smtp.from: некорректный адрес %q", c.SMTP.From)
+		}
+	}
+	if c.SMTP.Password != "" && c.SMTP.Username == "" {
+		fail("smtp.username: не задан пользователь при заданном smtp.password")
+	}
+
+	if c.Telegram.APIURL != "" {
+		if parsed, err := url.Parse(c.Telegram.APIURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			fail("telegram.api_url: некорректный адрес %q", c.Telegram.APIURL)
+		}
+	}
+	if c.Telegram.Token != "" && len(c.Telegram.AllowedChats) == 0 {
+		fail("telegram.allowed_chats: не задан ни один чат при заданном telegram.token")
+	}
+
+	return errors.Join(errs...)
+}
+
+// Duration - time.Duration, который в json записывается строкой вида "60s" или "24h"
+type Duration time.Duration
+
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("длительность задается строкой, например \"60s\" или \"24h\"")
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultFile читается, если путь к файлу настроек не указан и файл существует
+	DefaultFile = "config.json"
+	envPrefix   = "TENDERTRACKER_"
+)
+
+// Flags - флаги командной строки, перекрывающие файл и переменные окружения
+type Flags struct {
+	fs   *flag.FlagSet
+	file string
+	// set - применение флагов, которые есть у команды
+	set map[string]func(*Config, string)
+}
+
+// RegisterFlags добавляет общие флаги настроек в набор флагов команды
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{fs: fs, set: make(map[string]func(*Config, string))}
+
+	fs.StringVar(&f.file, "config", "", "файл настроек (по умолчанию $"+envPrefix+"CONFIG или "+DefaultFile+", если он есть)")
+	f.String("log-level", "уровень журнала: debug, info, warn, error", func(c *Config, v string) {
+		c.Log.Level = v
+	})
+	f.String("categories-file", "файл категорий", func(c *Config, v string) {
+		c.Paths.Categories = v
+	})
+	f.String("rules-file", "файл правил отсева закупок по названию", func(c *Config, v string) {
+		c.Paths.Rules = v
+	})
+
+	return f
+}
+
+// RegisterServerFlags добавляет флаги веб-сервера
+func (f *Flags) RegisterServerFlags() {
+	f.String("addr", "адрес веб-сервера", func(c *Config, v string) {
+		c.Server.Addr = v
+	})
+	f.String("db", "файл базы тендеров", func(c *Config, v string) {
+		c.Paths.Database = v
+	})
+	f.String("reports-dir", "каталог отчетов", func(c *Config, v string) {
+		c.Paths.Reports = v
+	})
+	f.String("documents-dir", "каталог документов закупок", func(c *Config, v string) {
+		c.Paths.Documents = v
+	})
+}
+
+// String добавляет строковый флаг, который применяется функцией apply.
+// Значение по умолчанию берется из файла настроек, поэтому в самом флаге его нет
+func (f *Flags) String(name, usage string, apply func(*Config, string)) {
+	f.fs.String(name, "", usage)
+	f.set[name] = apply
+}
+
+// Load читает настройки поверх defaults и проверяет их.
+// Вызывается после разбора флагов
+func (f *Flags) Load(defaults Config) (*Config, error) {
+	config := defaults
+
+	path, required := f.file, true
+	if path == "" {
+		path = os.Getenv(envPrefix + "CONFIG")
+	}
+	if path == "" {
+		path, required = DefaultFile, false
+	}
+
+	if err := loadFile(&config, path, required); err != nil {
+		return nil, err
+	}
+
+	if err := applyEnv(&config); err != nil {
+		return nil, err
+	}
+
+	f.fs.Visit(func(fl *flag.Flag) {
+		if apply, ok := f.set[fl.Name]; ok {
+			apply(&config, fl.Value.String())
+		}
+	})
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("некорректные настройки:\n%w", err)
+	}
+
+	return &config, nil
+}
+
+// loadFile читает файл настроек. Поля, которых нет в файле, сохраняют прежние значения
+func loadFile(config *Config, path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !required && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("не удалось прочитать файл настроек: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	return nil
+}
+
+// applyEnv применяет переменные окружения TENDERTRACKER_*
+func applyEnv(config *Config) error {
+	var errs []error
+
+	str := func(name string, target *string) {
+		if value, ok := os.LookupEnv(envPrefix + name); ok {
+			*target = value
+		}
+	}
+	duration := func(name string, target *Duration) {
+		if value, ok := os.LookupEnv(envPrefix + name); ok {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: %w", envPrefix, name, err))
+				return
+			}
+			*target = Duration(parsed)
+		}
+	}
+	integer := func(name string, target *int) {
+		if value, ok := os.LookupEnv(envPrefix + name); ok {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: некорректное число %q", envPrefix, name, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+	boolean := func(name string, target *bool) {
+		if value, ok := os.LookupEnv(envPrefix + name); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: некорректное значение %q, допустимо true или false", envPrefix, name, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+	// Список чисел через запятую: TENDERTRACKER_TELEGRAM_ALLOWED_CHATS=123,-100456
+	int64s := func(name string, target *[]int64) {
+		if value, ok := os.LookupEnv(envPrefix + name); ok {
+			var parsed []int64
+			for _, item := range strings.Split(value, ",") {
+				item = strings.TrimSpace(item)
+				if item == "" {
+					continue
+				}
+				number, err := strconv.ParseInt(item, 10, 64)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s%s: некорректное число %q", envPrefix, name, item))
+					return
+				}
+				parsed = append(parsed, number)
+			}
+			*target = parsed
+		}
+	}
+	float := func(name string, target *float64) {
+		if value, ok := os.LookupEnv(envPrefix + name); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s%s: некорректное число %q", envPrefix, name, value))
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	str("ADDR", &config.Server.Addr)
+	str("STATIC_DIR", &config.Server.StaticDir)
+	str("TEMPLATES_DIR", &config.Server.TemplatesDir)
+	str("LOG_LEVEL", &config.Log.Level)
+	str("CATEGORIES_FILE", &config.Paths.Categories)
+	str("RULES_FILE", &config.Paths.Rules)
+	str("DB", &config.Paths.Database)
+	str("REPORTS_DIR", &config.Paths.Reports)
+	str("DOCUMENTS_DIR", &config.Paths.Documents)
+	duration("SEARCH_TIMEOUT", &config.Search.Timeout)
+	duration("REPORT_RETENTION", &config.Search.ReportRetention)
+	duration("JOB_RETENTION", &config.Search.JobRetention)
+	duration("RULES_RELOAD", &config.Search.RulesReload)
+	str("SMTP_HOST", &config.SMTP.Host)
+	integer("SMTP_PORT", &config.SMTP.Port)
+	str("SMTP_USERNAME", &config.SMTP.Username)
+	str("SMTP_PASSWORD", &config.SMTP.Password)
+	str("SMTP_FROM", &config.SMTP.From)
+	boolean("SMTP_TLS", &config.SMTP.TLS)
+	str("TELEGRAM_TOKEN", &config.Telegram.Token)
+	str("TELEGRAM_API_URL", &config.Telegram.APIURL)
+	int64s("TELEGRAM_ALLOWED_CHATS", &config.Telegram.AllowedChats)
+
+	// TENDERTRACKER_SBER_MAX_PAGES и т.п.
+	for _, source := range config.Sources.named() {
+		prefix := strings.ToUpper(source.name) + "_"
+		duration(prefix+"HTTP_TIMEOUT", &source.config.HTTPTimeout)
+		duration(prefix+"PAGE_DELAY", &source.config.PageDelay)
+		integer(prefix+"MAX_PAGES", &source.config.MaxPages)
+		integer(prefix+"LOOKBACK_DAYS", &source.config.LookBackDays)
+		float(prefix+"REQUESTS_PER_SECOND", &source.config.RequestsPerSecond)
+		integer(prefix+"BURST", &source.config.Burst)
+		integer(prefix+"MAX_CONCURRENT", &source.config.MaxConcurrent)
+		integer(prefix+"MAX_ATTEMPTS", &source.config.MaxAttempts)
+		integer(prefix+"DETAIL_WORKERS", &source.config.DetailWorkers)
+		duration(prefix+"DETAIL_CACHE_TTL", &source.config.DetailCacheTTL)
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package districts
+
+import (
+	"strings"
+	"unicode"
+
+	"tendertracker/internal/parsersber"
+)
+
+// Codes - коды федеральных округов в порядке вывода
+var Codes = []string{"OKER30", "OKER31", "OKER37", "OKER38", "OKER33", "OKER34", "OKER35", "OKER36"}
+
+// Code возвращает код округа (OKER30...) или пустую строку
+func Code(value string) string {
+	code := strings.ToUpper(value)
+	if _, ok := parsersber.FederalDistrictCodes[code]; ok {
+		return code
+	}
+	return ""
+}
+
+// Title возвращает название округа по коду
+func Title(code string) string {
+	if title, ok := parsersber.FederalDistrictCodes[code]; ok {
+		return title
+	}
+	return code
+}
+
+// Contains проверяет, что регион тендера относится к одному из округов.
+// Регион площадки - свободный текст ("г Москва", "Московская обл", адрес),
+// поэтому сравнивается первое значимое слово названия субъекта или его
+// другое название ("Якутия", "Кузбасс").
+// Тендер без региона пропускается, чтобы не потерять его
+func Contains(region string, codes []string) bool {
+	if len(codes) == 0 || region == "" {
+		return true
+	}
+
+	words := make(map[string]bool)
+	for _, word := range regionWords(region) {
+		words[word] = true
+	}
+
+	for _, code := range codes {
+		for _, subject := range parsersber.FederalDistricts[Title(code)] {
+			for _, key := range subjectKeys(subject) {
+				if words[key] {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// typeWords - слова вида субъекта, полные и сокращенные, как их пишут площадки:
+// "Респ Татарстан", "Московская обл", "г Москва", "Ханты-Мансийский АО"
+var typeWords = map[string]bool{
+	"республика": true, "респ": true, "область": true, "обл": true, "край": true,
+	"город": true, "г": true, "автономный": true, "автономная": true, "округ": true,
+	"ао": true, "аобл": true,
+}
+
+// aliases - другие названия субъектов по ключевому слову: площадки пишут
+// и "Республика Саха (Якутия)", и "Якутия", и "Кемеровская область - Кузбасс"
+var aliases = map[string][]string{
+	"саха":                 {"якутия"},
+	"чувашская":            {"чувашия"},
+	"кемеровская":          {"кузбасс"},
+	"ханты-мансийский":     {"югра"},
+	"северная":             {"осетия", "алания"},
+	"удмуртская":           {"удмуртия"},
+	"кабардино-балкарская": {"кабардино-балкария"},
+	"карачаево-черкесская": {"карачаево-черкесия"},
+	"тыва":                 {"тува"},
+	"санкт-петербург":      {"петербург"},
+}
+
+// subjectKeys - ключевые слова субъекта: первое слово названия без слов вида
+// субъекта ("Республика Карелия" - "карелия", "Московская область" - "московская")
+// и его другие названия
+func subjectKeys(subject string) []string {
+	for _, word := range regionWords(subject) {
+		if !typeWords[word] {
+			return append([]string{word}, aliases[word]...)
+		}
+	}
+	return nil
+}
+
+// regionWords делит название на слова, дефис остается внутри слова
+func regionWords(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	})
+}
@@ -0,0 +1,58 @@
+package districts
+
+import (
+	"testing"
+
+	"tendertracker/internal/parsersber"
+)
+
+func TestContains(t *testing.T) {
+	tests := []struct {
+		region string
+		codes  []string
+		want   bool
+	}{
+		{"Респ Татарстан", []string{"OKER33"}, true},
+		{"Респ Татарстан", []string{"OKER36"}, false},
+		{"Республика Татарстан", []string{"OKER36"}, false},
+		{"Респ Саха /Якутия/", []string{"OKER36"}, true},
+		{"Республика Саха (Якутия)", []string{"OKER36"}, true},
+		{"Якутия", []string{"OKER36"}, true},
+		{"Респ Саха /Якутия/", []string{"OKER30"}, false},
+		{"Чувашская Республика - Чувашия", []string{"OKER33"}, true},
+		{"Чувашия", []string{"OKER33"}, true},
+		{"Кузбасс", []string{"OKER35"}, true},
+		{"Кемеровская область - Кузбасс", []string{"OKER35"}, true},
+		{"Ханты-Мансийский Автономный округ - Югра АО", []string{"OKER34"}, true},
+		{"Югра", []string{"OKER34"}, true},
+		{"Респ Северная Осетия - Алания", []string{"OKER38"}, true},
+		{"г Москва", []string{"OKER30"}, true},
+		{"г Москва", []string{"OKER31"}, false},
+		{"Московская обл", []string{"OKER30"}, true},
+		{"г Санкт-Петербург", []string{"OKER31"}, true},
+		{"Алтайский край", []string{"OKER35"}, true},
+		{"Респ Крым", []string{"OKER37", "OKER38"}, true},
+		{"Респ Крым", []string{"OKER33", "OKER36"}, false},
+		{"Приморский край, г Владивосток", []string{"OKER36"}, true},
+		// Без региона или без отбора тендер не теряется
+		{"", []string{"OKER36"}, true},
+		{"Респ Татарстан", nil, true},
+	}
+
+	for _, tt := range tests {
+		if got := Contains(tt.region, tt.codes); got != tt.want {
+			t.Errorf("Contains(%q, %v) = %v, want %v", tt.region, tt.codes, got, tt.want)
+		}
+	}
+}
+
+func TestSubjectKeysSkipTypeWords(t *testing.T) {
+	for _, code := range Codes {
+		for _, subject := range parsersber.FederalDistricts[Title(code)] {
+			keys := subjectKeys(subject)
+			if len(keys) == 0 || typeWords[keys[0]] {
+				t.Errorf("subjectKeys(%q) = %v: ключ - слово вида субъекта", subject, keys)
+			}
+		}
+	}
+}
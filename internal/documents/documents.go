@@ -0,0 +1,823 @@
+package documents
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"tendertracker/internal/fetcher"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+)
+
+const (
+	manifestFile = "manifest.json"
+	filesDir     = "files"
+
+	// maxFileSize - предел одного скачанного или распакованного файла
+	maxFileSize = 200 << 20
+	// maxUnpackedSize - предел распакованного содержимого всех архивов закупки
+	maxUnpackedSize = 1 << 30
+	// maxArchiveDepth - сколько уровней вложенных архивов распаковывается
+	maxArchiveDepth = 2
+	// unpackTimeout - сколько может работать unrar или 7z над одним архивом
+	unpackTimeout = 5 * time.Minute
+	// downloadIdleTimeout - сколько ждать очередной части скачиваемого файла.
+	// Общего предела у скачивания нет: большой чертеж по медленной ссылке
+	// качается долго, но без остановок
+	downloadIdleTimeout = time.Minute
+)
+
+// ErrNoDocuments - у закупки нет скачанных документов
+var ErrNoDocuments = errors.New("документы закупки не загружены")
+
+// File - файл в папке закупки
+type File struct {
+	Name string `json:"name"`
+	// Path - путь внутри папки files, через "/"
+	Path string `json:"path"`
+	URL  string `json:"url,omitempty"`
+	Size int64  `json:"size"`
+	// Archive - путь архива, из которого извлечен файл
+	Archive string `json:"archive,omitempty"`
+	// Error - почему файл не скачан или архив не распакован
+	Error string `json:"error,omitempty"`
+}
+
+// Manifest - опись папки документов закупки
+type Manifest struct {
+	TenderID     string    `json:"tender_id"`
+	Title        string    `json:"title"`
+	Link         string    `json:"link"`
+	DownloadedAt time.Time `json:"downloaded_at"`
+	Files        []File    `json:"files"`
+	// Indexed - текст документов добавлен в индекс поиска
+	Indexed bool `json:"indexed"`
+}
+
+// Archive - папки с документами закупок в каталоге данных, по одной на закупку
+type Archive struct {
+	// Index получает текст скачанных документов, nil - без поиска по документам
+	Index Index
+
+	root    string
+	fetcher *fetcher.Fetcher
+
+	mu sync.Mutex
+	// busy - закупки, документы которых сейчас скачиваются
+	busy map[string]bool
+}
+
+// Open открывает (или создает) каталог документов
+func Open(root string, client *fetcher.Fetcher) (*Archive, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога документов: %w", err)
+	}
+
+	return &Archive{root: root, fetcher: client, busy: make(map[string]bool)}, nil
+}
+
+// Root - каталог документов
+func (a *Archive) Root() string {
+	return a.root
+}
+
+// Dir - папка документов закупки с ключом storage.TenderID
+func (a *Archive) Dir(id string) string {
+	return filepath.Join(a.root, FolderName(id))
+}
+
+var unsafeChars = regexp.MustCompile(`[^\p{L}\p{N}._-]+`)
+
+// FolderName - имя папки закупки из ее ключа. Ключ из длинной ссылки
+// сокращается, к нему добавляется хэш, чтобы разные ключи не совпали
+func FolderName(id string) string {
+	name := strings.Trim(unsafeChars.ReplaceAllString(id, "_"), "._")
+	if runes := []rune(name); len(runes) > 80 || name == "" {
+		sum := sha1.Sum([]byte(id))
+		name = string(runes[:min(len(runes), 60)]) + "_" + hex.EncodeToString(sum[:8])
+	}
+	return name
+}
+
+// Manifest возвращает опись документов закупки или ErrNoDocuments
+func (a *Archive) Manifest(id string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(a.Dir(id), manifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoDocuments
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("ошибка чтения описи %s: %w", id, err)
+	}
+	return &manifest, nil
+}
+
+// Download скачивает документы закупки в ее папку, распаковывает архивы и
+// заменяет прежнее содержимое папки. Ошибки отдельных файлов записываются в опись
+func (a *Archive) Download(ctx context.Context, id string, tender models.Tender, documents []models.Document) (*Manifest, error) {
+	if !a.lock(id) {
+		return nil, fmt.Errorf("документы %s уже скачиваются", id)
+	}
+	defer a.unlock(id)
+
+	dir := a.Dir(id)
+	tmp := dir + ".tmp"
+	os.RemoveAll(tmp)
+	if err := os.MkdirAll(filepath.Join(tmp, filesDir), 0o755); err != nil {
+		return nil, fmt.Errorf("ошибка создания папки документов: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	manifest := &Manifest{
+		TenderID:     id,
+		Title:        tender.Title,
+		Link:         tender.Link,
+		DownloadedAt: time.Now(),
+		Files:        []File{},
+	}
+	names := make(map[string]bool)
+	budget := &unpackBudget{left: maxUnpackedSize}
+
+	for _, document := range documents {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		file := a.download(ctx, filepath.Join(tmp, filesDir), document, names)
+		manifest.Files = append(manifest.Files, file)
+		if file.Error != "" {
+			logger.SugaredLogger.Warnf("%s: не удалось скачать %s: %s", id, document.URL, file.Error)
+			continue
+		}
+
+		unpacked, err := unpack(ctx, filepath.Join(tmp, filesDir), file.Path, 1, budget)
+		if err != nil {
+			logger.SugaredLogger.Warnf("%s: не удалось распаковать %s: %v", id, file.Name, err)
+			manifest.Files[len(manifest.Files)-1].Error = "не распакован: " + err.Error()
+		}
+		manifest.Files = append(manifest.Files, unpacked...)
+	}
+
+	if err := writeManifest(tmp, manifest); err != nil {
+		return nil, err
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("ошибка удаления прежних документов: %w", err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения документов: %w", err)
+	}
+
+	a.index(ctx, manifest)
+	return manifest, nil
+}
+
+// index извлекает текст документов закупки и передает его в Index.
+// Файлы, из которых текст не извлекся, пропускаются. При отмене ctx опись
+// остается без отметки Indexed, и документы добавит IndexMissing
+func (a *Archive) index(ctx context.Context, manifest *Manifest) {
+	if a.Index == nil {
+		return
+	}
+
+	dir := filepath.Join(a.Dir(manifest.TenderID), filesDir)
+	texts := []Text{}
+	for _, file := range manifest.Files {
+		if ctx.Err() != nil {
+			return
+		}
+		if file.Path == "" || !Extractable(file.Path) {
+			continue
+		}
+
+		text, err := ExtractText(filepath.Join(dir, filepath.FromSlash(file.Path)))
+		if err != nil {
+			logger.SugaredLogger.Warnf("%s: не удалось извлечь текст %s: %v", manifest.TenderID, file.Path, err)
+			continue
+		}
+		if strings.TrimSpace(text) != "" {
+			texts = append(texts, Text{Path: file.Path, Name: file.Name, Text: text})
+		}
+	}
+
+	if err := a.Index.IndexDocuments(manifest.TenderID, texts); err != nil {
+		logger.SugaredLogger.Warnf("%s: ошибка индексации документов: %v", manifest.TenderID, err)
+		return
+	}
+
+	manifest.Indexed = true
+	if err := writeManifest(a.Dir(manifest.TenderID), manifest); err != nil {
+		logger.SugaredLogger.Warnf("%s: ошибка записи описи: %v", manifest.TenderID, err)
+	}
+}
+
+// IndexMissing добавляет в индекс документы, скачанные без индекса,
+// например до включения поиска по документам. Останавливается при отмене ctx
+func (a *Archive) IndexMissing(ctx context.Context) {
+	if a.Index == nil {
+		return
+	}
+
+	entries, err := os.ReadDir(a.root)
+	if err != nil {
+		logger.SugaredLogger.Warnf("Ошибка чтения каталога документов: %v", err)
+		return
+	}
+
+	indexed := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		if !entry.IsDir() || strings.HasSuffix(entry.Name(), ".tmp") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(a.root, entry.Name(), manifestFile))
+		if err != nil {
+			continue
+		}
+		var manifest Manifest
+		if err := json.Unmarshal(data, &manifest); err != nil || manifest.Indexed || manifest.TenderID == "" {
+			continue
+		}
+
+		if !a.lock(manifest.TenderID) {
+			continue
+		}
+		a.index(ctx, &manifest)
+		a.unlock(manifest.TenderID)
+		if manifest.Indexed {
+			indexed++
+		}
+	}
+
+	if indexed > 0 {
+		logger.SugaredLogger.Infof("Добавлено в индекс документов закупок: %d", indexed)
+	}
+}
+
+func (a *Archive) lock(id string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.busy[id] {
+		return false
+	}
+	a.busy[id] = true
+	return true
+}
+
+func (a *Archive) unlock(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.busy, id)
+}
+
+// download скачивает один документ в dir под уникальным именем
+func (a *Archive) download(ctx context.Context, dir string, document models.Document, names map[string]bool) File {
+	file := File{Name: document.Name, URL: document.URL}
+
+	req, err := http.NewRequestWithContext(fetcher.WithIdleTimeout(ctx, downloadIdleTimeout), "GET", document.URL, nil)
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+
+	resp, err := a.fetcher.Do(req, nil)
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		file.Error = "статус код " + strconv.Itoa(resp.StatusCode)
+		return file
+	}
+
+	file.Name = fileName(resp, document)
+	file.Path = uniqueName(names, safeName(file.Name))
+
+	size, err := writeFile(filepath.Join(dir, filepath.FromSlash(file.Path)), resp.Body)
+	if err != nil {
+		file.Error = err.Error()
+		return file
+	}
+	file.Size = size
+	return file
+}
+
+// fileName - имя файла из Content-Disposition, названия документа или ссылки
+func fileName(resp *http.Response, document models.Document) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := strings.TrimSpace(params["filename"]); name != "" {
+			return name
+		}
+	}
+
+	if name := strings.TrimSpace(document.Name); name != "" {
+		return name
+	}
+
+	if u, err := url.Parse(document.URL); err == nil {
+		if name := path.Base(u.Path); name != "." && name != "/" {
+			return name
+		}
+	}
+	return "document"
+}
+
+// safeName убирает из имени файла пути и символы, недопустимые в файловых системах
+func safeName(name string) string {
+	name = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_").Replace(name)
+	name = strings.Trim(strings.TrimSpace(name), ".")
+	if name == "" {
+		return "document"
+	}
+	return name
+}
+
+// uniqueName добавляет к имени номер, если такое имя уже занято
+func uniqueName(names map[string]bool, name string) string {
+	candidate := name
+	ext := path.Ext(name)
+	for i := 2; names[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+	names[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func writeFile(filename string, r io.Reader) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return 0, err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := io.Copy(file, io.LimitReader(r, maxFileSize+1))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && size > maxFileSize {
+		err = fmt.Errorf("файл больше %d МБ", maxFileSize>>20)
+	}
+	if err != nil {
+		os.Remove(filename)
+		return 0, err
+	}
+	return size, nil
+}
+
+func writeManifest(dir string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, manifestFile), data, 0o644)
+}
+
+// unpackBudget - сколько еще можно распаковать из архивов одной закупки,
+// общий для всех архивов и уровней вложенности
+type unpackBudget struct {
+	left int64
+}
+
+func (b *unpackBudget) take(size int64) error {
+	b.left -= size
+	if b.left < 0 {
+		return fmt.Errorf("распакованные архивы закупки больше %d МБ", maxUnpackedSize>>20)
+	}
+	return nil
+}
+
+// unpack распаковывает архив filesDir/name в папку рядом с ним и возвращает
+// извлеченные файлы. zip распаковывается сам, rar и 7z - программами unrar
+// или 7z, если они установлены. Остальные файлы не трогаются. Вложенные
+// архивы распаковываются до глубины maxArchiveDepth, depth - уровень name
+func unpack(ctx context.Context, dir, name string, depth int, budget *unpackBudget) ([]File, error) {
+	ext := strings.ToLower(path.Ext(name))
+	if ext != ".zip" && ext != ".rar" && ext != ".7z" {
+		return nil, nil
+	}
+	if depth > maxArchiveDepth {
+		return nil, fmt.Errorf("архив вложен глубже %d уровней", maxArchiveDepth)
+	}
+
+	archive := filepath.Join(dir, filepath.FromSlash(name))
+	target := strings.TrimSuffix(name, path.Ext(name)) + "_files"
+	targetDir := filepath.Join(dir, filepath.FromSlash(target))
+
+	var err error
+	if ext == ".zip" {
+		err = unzip(archive, targetDir, budget)
+	} else {
+		err = unpackExternal(ctx, archive, targetDir, budget)
+	}
+	if err != nil {
+		os.RemoveAll(targetDir)
+		return nil, err
+	}
+
+	var files []File
+	err = filepath.WalkDir(targetDir, func(filename string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(dir, filename)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, File{
+			Name:    entry.Name(),
+			Path:    filepath.ToSlash(relative),
+			Size:    info.Size(),
+			Archive: name,
+		})
+
+		nested, err := unpack(ctx, dir, filepath.ToSlash(relative), depth+1, budget)
+		if err != nil {
+			files[len(files)-1].Error = "не распакован: " + err.Error()
+		}
+		files = append(files, nested...)
+		return nil
+	})
+
+	return files, err
+}
+
+func unzip(archive, targetDir string, budget *unpackBudget) error {
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, entry := range reader.File {
+		// Ссылки и другие особые файлы не извлекаются
+		if !entry.Mode().IsRegular() {
+			continue
+		}
+
+		name := entry.Name
+		// Архивы из Windows часто хранят имена в cp866 без флага UTF-8
+		if entry.NonUTF8 {
+			name = decodeCP866(name)
+		}
+
+		// Пути вида ../ не должны выходить за пределы папки
+		filename := filepath.Join(targetDir, filepath.FromSlash(path.Clean("/"+name)))
+		if !strings.HasPrefix(filename, filepath.Clean(targetDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("недопустимый путь в архиве: %s", entry.Name)
+		}
+
+		file, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		size, err := writeFile(filename, io.LimitReader(file, max(budget.left, 0)+1))
+		file.Close()
+		if err != nil {
+			return err
+		}
+
+		if err := budget.take(size); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unpackExternal распаковывает rar и 7z программой unrar или 7z. Перед
+// распаковкой содержимое архива проверяется по его оглавлению, чтобы архив-бомба
+// не заполнил диск. Распакованное еще раз проверяется после программы
+func unpackExternal(ctx context.Context, archive, targetDir string, budget *unpackBudget) error {
+	ctx, cancel := context.WithTimeout(ctx, unpackTimeout)
+	defer cancel()
+
+	var list, extract *exec.Cmd
+	var parseList func(string) ([]archiveEntry, error)
+	if unrar, err := exec.LookPath("unrar"); err == nil && strings.EqualFold(filepath.Ext(archive), ".rar") {
+		list = exec.CommandContext(ctx, unrar, "lt", "-y", archive)
+		extract = exec.CommandContext(ctx, unrar, "x", "-o+", "-y", archive, targetDir+string(os.PathSeparator))
+		parseList = parseUnrarList
+	} else if sevenZip, err := findCommand("7z", "7za", "7zz"); err == nil {
+		list = exec.CommandContext(ctx, sevenZip, "l", "-slt", archive)
+		extract = exec.CommandContext(ctx, sevenZip, "x", "-y", "-o"+targetDir, archive)
+		parseList = parse7zList
+	} else {
+		return fmt.Errorf("не установлена программа unrar или 7z")
+	}
+
+	output, err := list.Output()
+	if err != nil {
+		return fmt.Errorf("%s: не удалось прочитать оглавление: %v", filepath.Base(list.Path), err)
+	}
+	entries, err := parseList(string(output))
+	if err != nil {
+		return err
+	}
+	if err := checkListing(entries, budget); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(targetDir, 0o755); err != nil {
+		return err
+	}
+	if output, err := extract.CombinedOutput(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s: распаковка дольше %v", filepath.Base(extract.Path), unpackTimeout)
+		}
+		return fmt.Errorf("%s: %v: %s", filepath.Base(extract.Path), err, strings.TrimSpace(string(output)))
+	}
+
+	return checkExtracted(targetDir, budget)
+}
+
+// archiveEntry - запись оглавления архива
+type archiveEntry struct {
+	Name string
+	Size int64
+	// Type - "file", "dir" или тип особой записи из оглавления (ссылка и т.п.)
+	Type string
+}
+
+// checkListing проверяет оглавление до распаковки: только файлы и папки,
+// без выхода за пределы папки, размеры в пределах maxFileSize и бюджета
+func checkListing(entries []archiveEntry, budget *unpackBudget) error {
+	var total int64
+	for _, entry := range entries {
+		name := filepath.ToSlash(entry.Name)
+		if path.IsAbs(name) || filepath.IsAbs(entry.Name) || name == ".." || strings.HasPrefix(name, "../") || strings.Contains(name, "/../") {
+			return fmt.Errorf("недопустимый путь в архиве: %s", entry.Name)
+		}
+
+		switch entry.Type {
+		case "dir":
+		case "file":
+			if entry.Size > maxFileSize {
+				return fmt.Errorf("файл %s в архиве больше %d МБ", entry.Name, maxFileSize>>20)
+			}
+			total += entry.Size
+		default:
+			return fmt.Errorf("архив содержит ссылку или особый файл: %s (%s)", entry.Name, entry.Type)
+		}
+	}
+
+	if total > budget.left {
+		return fmt.Errorf("распакованные архивы закупки больше %d МБ", maxUnpackedSize>>20)
+	}
+	return nil
+}
+
+// parseUnrarList разбирает вывод "unrar lt": записи из строк "Ключ: значение",
+// запись начинается с Name
+func parseUnrarList(output string) ([]archiveEntry, error) {
+	var entries []archiveEntry
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ": ")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Name":
+			entries = append(entries, archiveEntry{Name: value})
+		case "Type":
+			if len(entries) == 0 {
+				continue
+			}
+			switch value {
+			case "File":
+				entries[len(entries)-1].Type = "file"
+			case "Directory":
+				entries[len(entries)-1].Type = "dir"
+			default:
+				entries[len(entries)-1].Type = value
+			}
+		case "Size":
+			if len(entries) == 0 {
+				continue
+			}
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unrar: некорректный размер %q в оглавлении", value)
+			}
+			entries[len(entries)-1].Size = size
+		}
+	}
+
+	return checkTypes(entries)
+}
+
+// parse7zList разбирает вывод "7z l -slt": после строки из "-" идут записи
+// из строк "Ключ = значение", разделенные пустой строкой
+func parse7zList(output string) ([]archiveEntry, error) {
+	_, body, ok := strings.Cut(output, "\n----------")
+	if !ok {
+		return nil, fmt.Errorf("7z: не найдено оглавление архива")
+	}
+
+	var entries []archiveEntry
+	for _, line := range strings.Split(body, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), " = ")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "Path":
+			entries = append(entries, archiveEntry{Name: value, Type: "file"})
+		case "Folder":
+			if len(entries) > 0 && value == "+" {
+				entries[len(entries)-1].Type = "dir"
+			}
+		case "Size":
+			if len(entries) == 0 || value == "" {
+				continue
+			}
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("7z: некорректный размер %q в оглавлении", value)
+			}
+			entries[len(entries)-1].Size = size
+		case "Attributes":
+			// Права unix после атрибутов Windows: "A_ -rw-r--r--", у ссылки "lrwxrwxrwx"
+			for _, field := range strings.Fields(value) {
+				if len(field) == 10 && field[0] != '-' && field[0] != 'd' && strings.Trim(field[1:], "rwxsStT-") == "" {
+					if len(entries) > 0 {
+						entries[len(entries)-1].Type = "unix " + field
+					}
+				}
+			}
+		case "Symbolic Link", "Hard Link":
+			if len(entries) > 0 && value != "" {
+				entries[len(entries)-1].Type = strings.ToLower(key)
+			}
+		}
+	}
+
+	return checkTypes(entries)
+}
+
+// checkTypes отклоняет оглавление, в котором у записи не найден тип
+func checkTypes(entries []archiveEntry) ([]archiveEntry, error) {
+	for _, entry := range entries {
+		if entry.Type == "" {
+			return nil, fmt.Errorf("не удалось определить тип записи %s в оглавлении архива", entry.Name)
+		}
+	}
+	return entries, nil
+}
+
+// checkExtracted проверяет то, что распаковала внешняя программа: только
+// обычные файлы и папки внутри targetDir. Ссылка могла бы указывать за пределы
+// папки закупки, и ее отдали бы при скачивании zip или прочитали при индексации
+func checkExtracted(targetDir string, budget *unpackBudget) error {
+	root := filepath.Clean(targetDir)
+
+	return filepath.WalkDir(root, func(filename string, _ os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if filename != root && !strings.HasPrefix(filename, root+string(os.PathSeparator)) {
+			return fmt.Errorf("недопустимый путь в архиве: %s", filename)
+		}
+
+		info, err := os.Lstat(filename)
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.IsDir():
+			return nil
+		case info.Mode().IsRegular():
+			if info.Size() > maxFileSize {
+				return fmt.Errorf("файл %s в архиве больше %d МБ", info.Name(), maxFileSize>>20)
+			}
+			return budget.take(info.Size())
+		default:
+			relative, _ := filepath.Rel(root, filename)
+			return fmt.Errorf("архив содержит ссылку или особый файл: %s", filepath.ToSlash(relative))
+		}
+	})
+}
+
+func findCommand(names ...string) (string, error) {
+	for _, name := range names {
+		if found, err := exec.LookPath(name); err == nil {
+			return found, nil
+		}
+	}
+	return "", exec.ErrNotFound
+}
+
+// cp866 - вторая половина кодовой страницы 866 (байты 0x80-0xFF)
+var cp866 = []rune("АБВГДЕЖЗИЙКЛМНОПРСТУФХЦЧШЩЪЫЬЭЮЯабвгдежзийклмноп" +
+	"░▒▓│┤╡╢╖╕╣║╗╝╜╛┐└┴┬├─┼╞╟╚╔╩╦╠═╬╧╨╤╥╙╘╒╓╫╪┘┌█▄▌▐▀" +
+	"рстуфхцчшщъыьэюяЁёЄєЇїЎў°∙·√№¤■ ")
+
+func decodeCP866(name string) string {
+	var result strings.Builder
+	for i := 0; i < len(name); i++ {
+		if b := name[i]; b < 0x80 {
+			result.WriteByte(b)
+		} else {
+			result.WriteRune(cp866[b-0x80])
+		}
+	}
+	return result.String()
+}
+
+// WriteZip записывает все документы закупки одним zip-архивом
+func (a *Archive) WriteZip(id string, w io.Writer) error {
+	manifest, err := a.Manifest(id)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(a.Dir(id), filesDir)
+	var paths []string
+	for _, file := range manifest.Files {
+		if file.Error == "" || file.Size > 0 {
+			paths = append(paths, file.Path)
+		}
+	}
+	sort.Strings(paths)
+
+	writer := zip.NewWriter(w)
+	for _, name := range paths {
+		if err := addToZip(writer, filepath.Join(dir, filepath.FromSlash(name)), name); err != nil {
+			writer.Close()
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+func addToZip(writer *zip.Writer, filename, name string) error {
+	file, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	entry, err := writer.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, file)
+	return err
+}
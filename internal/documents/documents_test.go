@@ -0,0 +1,299 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// zipData собирает zip из имен и содержимого файлов
+func zipData(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeTestFile(t *testing.T, filename string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnpackDepth(t *testing.T) {
+	dir := t.TempDir()
+
+	third := zipData(t, map[string][]byte{"deep.txt": []byte("deep")})
+	second := zipData(t, map[string][]byte{"second.txt": []byte("2"), "third.zip": third})
+	first := zipData(t, map[string][]byte{"first.txt": []byte("1"), "second.zip": second})
+	writeTestFile(t, filepath.Join(dir, "docs.zip"), first)
+
+	files, err := unpack(context.Background(), dir, "docs.zip", 1, &unpackBudget{left: maxUnpackedSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byPath := make(map[string]File)
+	for _, file := range files {
+		byPath[file.Path] = file
+	}
+	for _, path := range []string{"docs_files/first.txt", "docs_files/second.zip", "docs_files/second_files/second.txt", "docs_files/second_files/third.zip"} {
+		if _, ok := byPath[path]; !ok {
+			t.Errorf("нет файла %s: %+v", path, files)
+		}
+	}
+	if file := byPath["docs_files/second_files/third.zip"]; !strings.Contains(file.Error, "глубже") {
+		t.Errorf("третий уровень распакован или без ошибки: %+v", file)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "docs_files/second_files/third_files")); !os.IsNotExist(err) {
+		t.Errorf("третий уровень распакован: %v", err)
+	}
+}
+
+func TestUnpackBudget(t *testing.T) {
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("a"), 600)
+	writeTestFile(t, filepath.Join(dir, "a.zip"), zipData(t, map[string][]byte{"a.txt": data}))
+	writeTestFile(t, filepath.Join(dir, "b.zip"), zipData(t, map[string][]byte{"b.txt": data}))
+
+	// Предел общий для всех архивов закупки
+	budget := &unpackBudget{left: 1000}
+	if _, err := unpack(context.Background(), dir, "a.zip", 1, budget); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unpack(context.Background(), dir, "b.zip", 1, budget); err == nil || !strings.Contains(err.Error(), "больше") {
+		t.Fatalf("второй архив сверх предела: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "b_files")); !os.IsNotExist(err) {
+		t.Errorf("папка архива сверх предела не удалена: %v", err)
+	}
+}
+
+func TestUnzipSkipsLinksAndEscapes(t *testing.T) {
+	dir := t.TempDir()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	header := &zip.FileHeader{Name: "passwd"}
+	header.SetMode(os.ModeSymlink | 0o777)
+	w, _ := writer.CreateHeader(header)
+	w.Write([]byte("/etc/passwd"))
+	w, _ = writer.Create("doc.txt")
+	w.Write([]byte("text"))
+	writer.Close()
+	writeTestFile(t, filepath.Join(dir, "links.zip"), buf.Bytes())
+
+	files, err := unpack(context.Background(), dir, "links.zip", 1, &unpackBudget{left: maxUnpackedSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != "links_files/doc.txt" {
+		t.Errorf("файлы %+v, want только links_files/doc.txt", files)
+	}
+
+	writeTestFile(t, filepath.Join(dir, "escape.zip"), zipData(t, map[string][]byte{"../../escape.txt": []byte("x")}))
+	files, err = unpack(context.Background(), dir, "escape.zip", 1, &unpackBudget{left: maxUnpackedSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path != "escape_files/escape.txt" {
+		t.Errorf("файлы %+v, want escape_files/escape.txt", files)
+	}
+}
+
+func TestCheckExtracted(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	writeTestFile(t, outside, []byte("secret"))
+
+	tests := []struct {
+		name    string
+		prepare func(dir string) error
+		wantErr string
+	}{
+		{"обычные файлы", func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "sub", "doc.txt"), []byte("text"), 0o644)
+		}, ""},
+		{"ссылка за пределы папки", func(dir string) error {
+			return os.Symlink(outside, filepath.Join(dir, "sub", "secret.txt"))
+		}, "sub/secret.txt"},
+		{"ссылка на папку", func(dir string) error {
+			return os.Symlink(filepath.Dir(outside), filepath.Join(dir, "up"))
+		}, "up"},
+		{"сверх предела", func(dir string) error {
+			return os.WriteFile(filepath.Join(dir, "big.txt"), bytes.Repeat([]byte("a"), 2000), 0o644)
+		}, "больше"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := tt.prepare(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		err := checkExtracted(dir, &unpackBudget{left: 1000})
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: ошибка %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+const unrarList = `
+UNRAR 6.24 freeware      Copyright (c) 1993-2023 Alexander Roshal
+
+Archive: docs.rar
+Details: RAR 5
+
+        Name: ТЗ.docx
+        Type: File
+        Size: 12345
+ Packed size: 10000
+       Ratio: 81%
+       mtime: 2026-10-01 09:00:00,000000000
+  Attributes: -rw-r--r--
+       CRC32: 5A0C2C1F
+     Host OS: Unix
+ Compression: RAR 5.0(v50) -m3 -md=4M
+
+        Name: Смета
+        Type: Directory
+  Attributes: drwxr-xr-x
+
+        Name: Смета/расчет.xlsx
+        Type: File
+        Size: 2048
+`
+
+const sevenZipList = `
+7-Zip 23.01 (x64) : Copyright (c) 1999-2023 Igor Pavlov : 2023-06-20
+
+Scanning the drive for archives:
+1 file, 1234 bytes (2 KiB)
+
+Listing archive: docs.7z
+
+--
+Path = docs.7z
+Type = 7z
+Physical Size = 1234
+Solid = +
+
+----------
+Path = ТЗ.docx
+Size = 12345
+Packed Size = 1000
+Modified = 2026-10-01 09:00:00
+Attributes = A_ -rw-r--r--
+
+Path = Смета
+Size = 0
+Folder = +
+Attributes = D_ drwxr-xr-x
+
+Path = Смета/расчет.xlsx
+Size = 2048
+Attributes = A_ -rw-r--r--
+`
+
+func TestParseArchiveLists(t *testing.T) {
+	want := []archiveEntry{
+		{Name: "ТЗ.docx", Size: 12345, Type: "file"},
+		{Name: "Смета", Type: "dir"},
+		{Name: "Смета/расчет.xlsx", Size: 2048, Type: "file"},
+	}
+
+	tests := []struct {
+		name  string
+		parse func(string) ([]archiveEntry, error)
+		text  string
+	}{
+		{"unrar", parseUnrarList, unrarList},
+		{"7z", parse7zList, sevenZipList},
+	}
+
+	for _, tt := range tests {
+		entries, err := tt.parse(tt.text)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(entries) != len(want) {
+			t.Errorf("%s: записей %d, want %d: %+v", tt.name, len(entries), len(want), entries)
+			continue
+		}
+		for i, entry := range entries {
+			if entry != want[i] {
+				t.Errorf("%s: запись %d: %+v, want %+v", tt.name, i, entry, want[i])
+			}
+		}
+	}
+}
+
+func TestCheckListing(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+		// err - подстрока ошибки, пусто - архив можно распаковывать
+		err string
+	}{
+		{"files", []archiveEntry{{Name: "a.pdf", Size: 400, Type: "file"}, {Name: "b", Type: "dir"}, {Name: "b/c.pdf", Size: 600, Type: "file"}}, ""},
+		{"budget", []archiveEntry{{Name: "a.pdf", Size: 600, Type: "file"}, {Name: "b.pdf", Size: 401, Type: "file"}}, "больше"},
+		{"file size", []archiveEntry{{Name: "bomb.bin", Size: maxFileSize + 1, Type: "file"}}, "больше 200 МБ"},
+		{"parent", []archiveEntry{{Name: "../evil.sh", Size: 1, Type: "file"}}, "недопустимый путь"},
+		{"nested parent", []archiveEntry{{Name: "a/../../evil.sh", Size: 1, Type: "file"}}, "недопустимый путь"},
+		{"absolute", []archiveEntry{{Name: "/etc/cron.d/evil", Size: 1, Type: "file"}}, "недопустимый путь"},
+		{"unrar link", []archiveEntry{{Name: "passwd", Type: "Unix symbolic link"}}, "ссылку"},
+		{"7z link", []archiveEntry{{Name: "passwd", Type: "unix lrwxrwxrwx"}}, "ссылку"},
+	}
+
+	for _, tt := range tests {
+		err := checkListing(tt.entries, &unpackBudget{left: 1000})
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: ошибка %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestParse7zListLinks(t *testing.T) {
+	text := "--\nPath = links.7z\n\n----------\nPath = passwd\nSize = 11\nAttributes = A_ lrwxrwxrwx\n\nPath = hard\nSize = 1\nAttributes = A_ -rw-r--r--\nHard Link = passwd\n"
+
+	entries, err := parse7zList(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkListing(entries[:1], &unpackBudget{left: 1000}); err == nil {
+		t.Errorf("ссылка не отклонена: %+v", entries[0])
+	}
+	if err := checkListing(entries[1:], &unpackBudget{left: 1000}); err == nil {
+		t.Errorf("жесткая ссылка не отклонена: %+v", entries[1])
+	}
+}
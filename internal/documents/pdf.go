@@ -0,0 +1,958 @@
+package documents
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Разбор PDF здесь нужен только для поиска: текст собирается из операторов
+// вывода текста страниц, без раскладки по строкам и столбцам. Поддерживаются
+// потоки FlateDecode, потоки объектов и шрифты с ToUnicode. Без ToUnicode
+// однобайтовые коды читаются как Windows-1251 с учетом /Differences
+
+// maxStreamSize - предел распакованного потока PDF
+const maxStreamSize = 64 << 20
+
+var errEncryptedPDF = errors.New("PDF зашифрован")
+
+var objectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+type pdfName string
+
+type pdfRef int
+
+type pdfObject struct {
+	value  any
+	stream []byte
+}
+
+type pdfDocument struct {
+	objects map[int]*pdfObject
+	fonts   map[any]*pdfFont
+}
+
+// pdfText извлекает текст страниц PDF
+func pdfText(data []byte) (string, error) {
+	doc := &pdfDocument{objects: make(map[int]*pdfObject), fonts: make(map[any]*pdfFont)}
+	doc.parseObjects(data)
+
+	for _, object := range doc.objects {
+		if dict, ok := object.value.(map[string]any); ok && dict["Encrypt"] != nil {
+			return "", errEncryptedPDF
+		}
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) && doc.trailerEncrypted(data) {
+		return "", errEncryptedPDF
+	}
+
+	var text strings.Builder
+	for _, page := range doc.pages() {
+		content := doc.contents(page.dict["Contents"])
+		doc.showText(&text, content, page.resources, 0)
+		text.WriteString("\n")
+	}
+	return text.String(), nil
+}
+
+// trailerEncrypted проверяет словари trailer, которых нет среди объектов
+func (d *pdfDocument) trailerEncrypted(data []byte) bool {
+	for offset := 0; ; {
+		i := bytes.Index(data[offset:], []byte("trailer"))
+		if i < 0 {
+			return false
+		}
+		offset += i + len("trailer")
+		lexer := &pdfLexer{data: data, pos: offset}
+		if dict, ok := lexer.value().(map[string]any); ok && dict["Encrypt"] != nil {
+			return true
+		}
+	}
+}
+
+// parseObjects находит все объекты файла, в том числе в потоках объектов.
+// Объекты поздних обновлений файла заменяют ранние
+func (d *pdfDocument) parseObjects(data []byte) {
+	for _, match := range objectHeader.FindAllSubmatchIndex(data, -1) {
+		number, err := strconv.Atoi(string(data[match[2]:match[3]]))
+		if err != nil {
+			continue
+		}
+
+		lexer := &pdfLexer{data: data, pos: match[1]}
+		object := &pdfObject{value: lexer.value()}
+
+		if token := lexer.peek(); token.kind == tokenKeyword && token.text == "stream" {
+			lexer.next()
+			object.stream = streamData(data, lexer.pos, object.value)
+		}
+		d.objects[number] = object
+	}
+
+	for _, object := range d.objectList() {
+		dict, ok := object.value.(map[string]any)
+		if !ok || dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		d.parseObjectStream(dict, d.decode(object))
+	}
+}
+
+// streamData возвращает сырые данные потока, начинающиеся после ключевого слова stream
+func streamData(data []byte, pos int, value any) []byte {
+	if pos < len(data) && data[pos] == '\r' {
+		pos++
+	}
+	if pos < len(data) && data[pos] == '\n' {
+		pos++
+	}
+
+	if dict, ok := value.(map[string]any); ok {
+		if length, ok := dict["Length"].(float64); ok && length >= 0 && pos+int(length) <= len(data) {
+			end := pos + int(length)
+			if bytes.HasPrefix(bytes.TrimLeft(data[end:min(end+16, len(data))], "\r\n \t"), []byte("endstream")) {
+				return data[pos:end]
+			}
+		}
+	}
+
+	end := bytes.Index(data[pos:], []byte("endstream"))
+	if end < 0 {
+		return data[pos:]
+	}
+	return bytes.TrimRight(data[pos:pos+end], "\r\n")
+}
+
+// parseObjectStream читает объекты потока объектов: в начале потока N пар
+// "номер смещение", смещения отсчитываются от /First
+func (d *pdfDocument) parseObjectStream(dict map[string]any, stream []byte) {
+	count, _ := d.resolve(dict["N"]).(float64)
+	first, _ := d.resolve(dict["First"]).(float64)
+	if stream == nil || count <= 0 || first <= 0 {
+		return
+	}
+
+	header := &pdfLexer{data: stream[:min(int(first), len(stream))]}
+	for range int(count) {
+		number, offset := header.next(), header.next()
+		if number.kind != tokenNumber || offset.kind != tokenNumber {
+			return
+		}
+		if _, ok := d.objects[int(number.number)]; ok {
+			continue
+		}
+
+		pos := int(first) + int(offset.number)
+		if pos < 0 || pos >= len(stream) {
+			continue
+		}
+		lexer := &pdfLexer{data: stream, pos: pos}
+		d.objects[int(number.number)] = &pdfObject{value: lexer.value()}
+	}
+}
+
+func (d *pdfDocument) objectList() []*pdfObject {
+	numbers := make([]int, 0, len(d.objects))
+	for number := range d.objects {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	list := make([]*pdfObject, 0, len(numbers))
+	for _, number := range numbers {
+		list = append(list, d.objects[number])
+	}
+	return list
+}
+
+// resolve заменяет ссылку на объект его значением
+func (d *pdfDocument) resolve(value any) any {
+	for range 8 {
+		ref, ok := value.(pdfRef)
+		if !ok {
+			return value
+		}
+		object := d.objects[int(ref)]
+		if object == nil {
+			return nil
+		}
+		value = object.value
+	}
+	return nil
+}
+
+func (d *pdfDocument) dict(value any) map[string]any {
+	dict, _ := d.resolve(value).(map[string]any)
+	return dict
+}
+
+// decode распаковывает поток объекта. Потоки с фильтрами, кроме FlateDecode,
+// (изображения и шрифты) не нужны для текста и пропускаются
+func (d *pdfDocument) decode(object *pdfObject) []byte {
+	if object == nil || object.stream == nil {
+		return nil
+	}
+
+	var filters []any
+	if dict, ok := object.value.(map[string]any); ok {
+		switch filter := d.resolve(dict["Filter"]).(type) {
+		case pdfName:
+			filters = []any{filter}
+		case []any:
+			filters = filter
+		}
+	}
+
+	data := object.stream
+	for _, filter := range filters {
+		if d.resolve(filter) != pdfName("FlateDecode") {
+			return nil
+		}
+		reader, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil
+		}
+		// Оборванные потоки встречаются часто, прочитанного до ошибки достаточно
+		decoded, _ := io.ReadAll(io.LimitReader(reader, maxStreamSize))
+		reader.Close()
+		data = decoded
+	}
+	return data
+}
+
+// pdfPage - страница с унаследованными ресурсами
+type pdfPage struct {
+	dict      map[string]any
+	resources map[string]any
+}
+
+// pages обходит дерево страниц от каталога. Если дерево не найдено,
+// страницы берутся в порядке номеров объектов
+func (d *pdfDocument) pages() []pdfPage {
+	var pages []pdfPage
+	visited := make(map[any]bool)
+
+	var walk func(node any, resources map[string]any, depth int)
+	walk = func(node any, resources map[string]any, depth int) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		dict := d.dict(node)
+		if dict == nil || depth > 64 {
+			return
+		}
+		if own := d.dict(dict["Resources"]); own != nil {
+			resources = own
+		}
+
+		if dict["Type"] == pdfName("Page") || (dict["Kids"] == nil && dict["Contents"] != nil) {
+			pages = append(pages, pdfPage{dict: dict, resources: resources})
+			return
+		}
+		if kids, ok := d.resolve(dict["Kids"]).([]any); ok {
+			for _, kid := range kids {
+				walk(kid, resources, depth+1)
+			}
+		}
+	}
+
+	for _, object := range d.objectList() {
+		if dict, ok := object.value.(map[string]any); ok && dict["Type"] == pdfName("Catalog") {
+			walk(dict["Pages"], nil, 0)
+		}
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	for _, object := range d.objectList() {
+		if dict, ok := object.value.(map[string]any); ok && dict["Type"] == pdfName("Page") {
+			pages = append(pages, pdfPage{dict: dict, resources: d.dict(dict["Resources"])})
+		}
+	}
+	return pages
+}
+
+// contents склеивает потоки содержимого страницы
+func (d *pdfDocument) contents(value any) []byte {
+	var refs []any
+	switch contents := value.(type) {
+	case pdfRef:
+		if list, ok := d.resolve(contents).([]any); ok {
+			refs = list
+		} else {
+			refs = []any{contents}
+		}
+	case []any:
+		refs = contents
+	}
+
+	var content []byte
+	for _, ref := range refs {
+		if ref, ok := ref.(pdfRef); ok {
+			content = append(content, d.decode(d.objects[int(ref)])...)
+			content = append(content, '\n')
+		}
+	}
+	return content
+}
+
+// showText выполняет операторы текста потока content и пишет выведенный текст в out.
+// Формы (Do) разбираются рекурсивно со своими ресурсами
+func (d *pdfDocument) showText(out *strings.Builder, content []byte, resources map[string]any, depth int) {
+	if depth > 8 || len(content) == 0 {
+		return
+	}
+
+	fonts := d.dict(resources["Font"])
+	var font *pdfFont
+	var operands []any
+	// lastShown - длина последнего выведенного куска: отдельные буквы,
+	// расставленные по одной, не разделяются пробелами
+	lastShown := 0
+
+	show := func(value any) {
+		text, ok := value.([]byte)
+		if !ok || font == nil {
+			return
+		}
+		decoded := font.decode(text)
+		out.WriteString(decoded)
+		lastShown = len([]rune(decoded))
+	}
+	separate := func(newline bool) {
+		switch {
+		case newline:
+			out.WriteString("\n")
+		case lastShown > 1:
+			out.WriteString(" ")
+		}
+	}
+
+	lexer := &pdfLexer{data: content}
+	for {
+		token := lexer.peek()
+		if token.kind == tokenEOF {
+			return
+		}
+		if token.kind != tokenKeyword {
+			operands = append(operands, lexer.value())
+			continue
+		}
+		lexer.next()
+
+		switch token.text {
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok && fonts != nil {
+					font = d.font(fonts[string(name)])
+				}
+			}
+		case "Tj":
+			if len(operands) > 0 {
+				show(operands[len(operands)-1])
+			}
+		case "'", "\"":
+			separate(true)
+			if len(operands) > 0 {
+				show(operands[len(operands)-1])
+			}
+		case "TJ":
+			if len(operands) == 0 {
+				break
+			}
+			array, _ := operands[len(operands)-1].([]any)
+			for _, item := range array {
+				if offset, ok := item.(float64); ok {
+					// Сдвиг больше 0,15 кегля - пробел между словами, меньший - кернинг
+					if offset < -150 {
+						out.WriteString(" ")
+					}
+					continue
+				}
+				show(item)
+			}
+		case "Td", "TD":
+			newline := false
+			if len(operands) >= 2 {
+				ty, _ := operands[len(operands)-1].(float64)
+				newline = ty != 0
+			}
+			separate(newline)
+		case "T*":
+			separate(true)
+		case "Tm":
+			separate(false)
+		case "ET":
+			out.WriteString(" ")
+			lastShown = 0
+		case "Do":
+			if len(operands) > 0 {
+				if name, ok := operands[len(operands)-1].(pdfName); ok {
+					d.showForm(out, resources, string(name), depth)
+				}
+			}
+		case "BI":
+			lexer.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+func (d *pdfDocument) showForm(out *strings.Builder, resources map[string]any, name string, depth int) {
+	xobjects := d.dict(resources["XObject"])
+	if xobjects == nil {
+		return
+	}
+	ref, ok := xobjects[name].(pdfRef)
+	if !ok {
+		return
+	}
+	object := d.objects[int(ref)]
+	if object == nil {
+		return
+	}
+	dict, ok := object.value.(map[string]any)
+	if !ok || dict["Subtype"] != pdfName("Form") {
+		return
+	}
+
+	formResources := d.dict(dict["Resources"])
+	if formResources == nil {
+		formResources = resources
+	}
+	d.showText(out, d.decode(object), formResources, depth+1)
+}
+
+// pdfFont переводит коды символов шрифта в текст
+type pdfFont struct {
+	// toUnicode - коды из ToUnicode, codeLength - длина кода в байтах
+	toUnicode  map[uint32]string
+	codeLength int
+	// simple - однобайтовая кодировка для шрифтов без ToUnicode
+	simple [256]rune
+	// composite - шрифт Type0, без ToUnicode его коды не перевести
+	composite bool
+}
+
+func (d *pdfDocument) font(value any) *pdfFont {
+	key := value
+	if _, ok := value.(pdfRef); !ok {
+		key = nil
+	}
+	if font, ok := d.fonts[key]; ok && key != nil {
+		return font
+	}
+
+	dict := d.dict(value)
+	font := &pdfFont{codeLength: 1, simple: cp1251}
+	if dict != nil {
+		font.composite = dict["Subtype"] == pdfName("Type0")
+		if font.composite {
+			font.codeLength = 2
+		}
+
+		if ref, ok := dict["ToUnicode"].(pdfRef); ok {
+			font.parseCMap(d.decode(d.objects[int(ref)]))
+		}
+
+		if encoding := d.dict(dict["Encoding"]); encoding != nil {
+			if differences, ok := d.resolve(encoding["Differences"]).([]any); ok {
+				font.applyDifferences(differences)
+			}
+		}
+	}
+
+	if key != nil {
+		d.fonts[key] = font
+	}
+	return font
+}
+
+func (f *pdfFont) decode(text []byte) string {
+	var result strings.Builder
+
+	if f.toUnicode != nil {
+		for i := 0; i+f.codeLength <= len(text); i += f.codeLength {
+			var code uint32
+			for _, b := range text[i : i+f.codeLength] {
+				code = code<<8 | uint32(b)
+			}
+			if value, ok := f.toUnicode[code]; ok {
+				result.WriteString(value)
+			} else if f.codeLength == 1 {
+				result.WriteRune(f.simple[code])
+			}
+		}
+		return result.String()
+	}
+
+	if f.composite {
+		return ""
+	}
+	for _, b := range text {
+		result.WriteRune(f.simple[b])
+	}
+	return result.String()
+}
+
+// parseCMap читает соответствия bfchar и bfrange из CMap ToUnicode
+func (f *pdfFont) parseCMap(data []byte) {
+	if data == nil {
+		return
+	}
+
+	f.toUnicode = make(map[uint32]string)
+	lexer := &pdfLexer{data: data}
+	var operands []any
+	for {
+		token := lexer.peek()
+		if token.kind == tokenEOF {
+			return
+		}
+		if token.kind != tokenKeyword {
+			operands = append(operands, lexer.value())
+			continue
+		}
+		lexer.next()
+
+		switch token.text {
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if code, ok := operands[0].([]byte); ok && len(code) > 0 {
+					f.codeLength = len(code)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].([]byte)
+				dst, ok2 := operands[i+1].([]byte)
+				if ok1 && ok2 {
+					f.toUnicode[codeOf(src)] = utf16Text(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].([]byte)
+				hi, ok2 := operands[i+1].([]byte)
+				if !ok1 || !ok2 {
+					continue
+				}
+				first, last := codeOf(lo), codeOf(hi)
+				if last < first || last-first > 0xFFFF {
+					continue
+				}
+
+				switch dst := operands[i+2].(type) {
+				case []byte:
+					runes := utf16.Decode(utf16Units(dst))
+					if len(runes) == 0 {
+						continue
+					}
+					for code := first; code <= last; code++ {
+						shifted := append([]rune{}, runes...)
+						shifted[len(shifted)-1] += rune(code - first)
+						f.toUnicode[code] = string(shifted)
+					}
+				case []any:
+					for j, item := range dst {
+						if value, ok := item.([]byte); ok && first+uint32(j) <= last {
+							f.toUnicode[first+uint32(j)] = utf16Text(value)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// applyDifferences меняет коды однобайтовой кодировки по массиву /Differences
+func (f *pdfFont) applyDifferences(differences []any) {
+	code := 0
+	for _, item := range differences {
+		switch value := item.(type) {
+		case float64:
+			code = int(value)
+		case pdfName:
+			if code >= 0 && code < 256 {
+				if r, ok := glyphRune(string(value)); ok {
+					f.simple[code] = r
+				}
+			}
+			code++
+		}
+	}
+}
+
+func codeOf(data []byte) uint32 {
+	var code uint32
+	for _, b := range data {
+		code = code<<8 | uint32(b)
+	}
+	return code
+}
+
+func utf16Units(data []byte) []uint16 {
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, uint16(data[i])<<8|uint16(data[i+1]))
+	}
+	return units
+}
+
+func utf16Text(data []byte) string {
+	return string(utf16.Decode(utf16Units(data)))
+}
+
+var glyphNames = map[string]rune{
+	"space": ' ', "period": '.', "comma": ',', "colon": ':', "semicolon": ';', "hyphen": '-',
+	"endash": '–', "emdash": '—', "parenleft": '(', "parenright": ')', "slash": '/', "quotedbl": '"',
+	"quotesingle": '\'', "numbersign": '#', "percent": '%', "numero": '№', "guillemotleft": '«',
+	"guillemotright": '»', "zero": '0', "one": '1', "two": '2', "three": '3', "four": '4',
+	"five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9',
+}
+
+// glyphRune переводит имя глифа в символ: uniXXXX, кириллические afiiNNNNN,
+// латинские буквы и частые знаки
+func glyphRune(name string) (rune, bool) {
+	if r, ok := glyphNames[name]; ok {
+		return r, true
+	}
+	if len(name) == 1 && (name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z') {
+		return rune(name[0]), true
+	}
+	if hex, ok := strings.CutPrefix(name, "uni"); ok && len(hex) == 4 {
+		if code, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return rune(code), true
+		}
+	}
+	if number, ok := strings.CutPrefix(name, "afii"); ok {
+		code, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case code == 10023:
+			return 'Ё', true
+		case code == 10071:
+			return 'ё', true
+		case code >= 10017 && code <= 10022:
+			return 'А' + rune(code-10017), true
+		case code >= 10024 && code <= 10049:
+			return 'Ж' + rune(code-10024), true
+		case code >= 10065 && code <= 10070:
+			return 'а' + rune(code-10065), true
+		case code >= 10072 && code <= 10097:
+			return 'ж' + rune(code-10072), true
+		}
+	}
+	return 0, false
+}
+
+// cp1251 - однобайтовая кодировка по умолчанию: ASCII и кириллица Windows-1251
+var cp1251 = func() [256]rune {
+	var table [256]rune
+	for i := range table {
+		table[i] = rune(i)
+	}
+	for i := 0xC0; i <= 0xFF; i++ {
+		table[i] = 'А' + rune(i-0xC0)
+	}
+	table[0xA8], table[0xB8] = 'Ё', 'ё'
+	table[0xB9], table[0xAB], table[0xBB] = '№', '«', '»'
+	table[0x96], table[0x97], table[0x85] = '–', '—', '…'
+	table[0x93], table[0x94], table[0xA0] = '“', '”', ' '
+	return table
+}()
+
+// Лексер PDF
+
+const (
+	tokenEOF = iota
+	tokenNumber
+	tokenName
+	tokenString
+	tokenArrayStart
+	tokenArrayEnd
+	tokenDictStart
+	tokenDictEnd
+	tokenKeyword
+)
+
+type pdfToken struct {
+	kind   int
+	text   string
+	number float64
+	data   []byte
+}
+
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\r' || b == '\t' || b == '\f' || b == 0
+}
+
+func isPDFDelimiter(b byte) bool {
+	return strings.IndexByte("()<>[]{}/%", b) >= 0
+}
+
+func (l *pdfLexer) peek() pdfToken {
+	pos := l.pos
+	token := l.next()
+	l.pos = pos
+	return token
+}
+
+func (l *pdfLexer) next() pdfToken {
+	data := l.data
+	for l.pos < len(data) {
+		if isPDFSpace(data[l.pos]) {
+			l.pos++
+			continue
+		}
+		if data[l.pos] == '%' {
+			for l.pos < len(data) && data[l.pos] != '\n' && data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		break
+	}
+	if l.pos >= len(data) {
+		return pdfToken{kind: tokenEOF}
+	}
+
+	switch c := data[l.pos]; {
+	case c == '(':
+		return pdfToken{kind: tokenString, data: l.literalString()}
+	case c == '<' && l.pos+1 < len(data) && data[l.pos+1] == '<':
+		l.pos += 2
+		return pdfToken{kind: tokenDictStart}
+	case c == '>' && l.pos+1 < len(data) && data[l.pos+1] == '>':
+		l.pos += 2
+		return pdfToken{kind: tokenDictEnd}
+	case c == '<':
+		return pdfToken{kind: tokenString, data: l.hexString()}
+	case c == '[':
+		l.pos++
+		return pdfToken{kind: tokenArrayStart}
+	case c == ']':
+		l.pos++
+		return pdfToken{kind: tokenArrayEnd}
+	case c == '/':
+		l.pos++
+		return pdfToken{kind: tokenName, text: l.name()}
+	case c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9':
+		word := l.word()
+		if number, err := strconv.ParseFloat(word, 64); err == nil {
+			return pdfToken{kind: tokenNumber, number: number}
+		}
+		return pdfToken{kind: tokenKeyword, text: word}
+	case isPDFDelimiter(c):
+		// {, }, одиночная ) и > - пропускаются
+		l.pos++
+		return l.next()
+	default:
+		return pdfToken{kind: tokenKeyword, text: l.word()}
+	}
+}
+
+func (l *pdfLexer) word() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+func (l *pdfLexer) name() string {
+	word := l.word()
+	if !strings.Contains(word, "#") {
+		return word
+	}
+
+	var result strings.Builder
+	for i := 0; i < len(word); i++ {
+		if word[i] == '#' && i+2 < len(word) {
+			if b, err := strconv.ParseUint(word[i+1:i+3], 16, 8); err == nil {
+				result.WriteByte(byte(b))
+				i += 2
+				continue
+			}
+		}
+		result.WriteByte(word[i])
+	}
+	return result.String()
+}
+
+func (l *pdfLexer) literalString() []byte {
+	data := l.data
+	l.pos++
+	var result []byte
+	depth := 1
+	for l.pos < len(data) {
+		c := data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return result
+			}
+		case '\\':
+			if l.pos >= len(data) {
+				return result
+			}
+			c = data[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r', '\n':
+				// Перенос строки после \ не входит в строку
+				if c == '\r' && l.pos < len(data) && data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					value := int(c - '0')
+					for i := 0; i < 2 && l.pos < len(data) && data[l.pos] >= '0' && data[l.pos] <= '7'; i++ {
+						value = value*8 + int(data[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(value)
+				}
+			}
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+func (l *pdfLexer) hexString() []byte {
+	data := l.data
+	l.pos++
+	var digits []byte
+	for l.pos < len(data) && data[l.pos] != '>' {
+		if c := data[l.pos]; c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F' {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	result := make([]byte, len(digits)/2)
+	for i := range result {
+		b, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		result[i] = byte(b)
+	}
+	return result
+}
+
+// value читает объект: число, имя, строку, массив, словарь или ссылку "N G R"
+func (l *pdfLexer) value() any {
+	token := l.next()
+	switch token.kind {
+	case tokenNumber:
+		// Ссылка - два целых числа и R
+		pos := l.pos
+		if generation := l.next(); generation.kind == tokenNumber {
+			if r := l.next(); r.kind == tokenKeyword && r.text == "R" {
+				return pdfRef(int(token.number))
+			}
+		}
+		l.pos = pos
+		return token.number
+	case tokenName:
+		return pdfName(token.text)
+	case tokenString:
+		return token.data
+	case tokenArrayStart:
+		array := []any{}
+		for {
+			next := l.peek()
+			if next.kind == tokenArrayEnd {
+				l.next()
+				return array
+			}
+			if next.kind == tokenEOF || next.kind == tokenDictEnd || next.kind == tokenKeyword && next.text != "true" && next.text != "false" && next.text != "null" {
+				return array
+			}
+			array = append(array, l.value())
+		}
+	case tokenDictStart:
+		dict := make(map[string]any)
+		for {
+			key := l.next()
+			if key.kind == tokenDictEnd || key.kind == tokenEOF {
+				return dict
+			}
+			if key.kind != tokenName {
+				continue
+			}
+			if next := l.peek(); next.kind == tokenDictEnd {
+				return dict
+			}
+			dict[key.text] = l.value()
+		}
+	case tokenKeyword:
+		switch token.text {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+		return nil
+	}
+	return nil
+}
+
+// skipInlineImage пропускает данные встроенного изображения BI ... ID ... EI
+func (l *pdfLexer) skipInlineImage() {
+	start := bytes.Index(l.data[l.pos:], []byte("ID"))
+	if start < 0 {
+		l.pos = len(l.data)
+		return
+	}
+	l.pos += start + 2
+
+	for l.pos < len(l.data) {
+		end := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if end < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		l.pos += end + 2
+		before := l.pos - 3
+		if before >= 0 && isPDFSpace(l.data[before]) && (l.pos >= len(l.data) || isPDFSpace(l.data[l.pos])) {
+			return
+		}
+	}
+}
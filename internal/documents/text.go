@@ -0,0 +1,194 @@
+package documents
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// maxTextSize - предел текста одного файла в индексе
+const maxTextSize = 4 << 20
+
+// ErrUnsupported - из файла такого типа текст не извлекается
+var ErrUnsupported = errors.New("формат не поддерживается")
+
+// Text - извлеченный текст одного файла закупки
+type Text struct {
+	// Path - путь файла внутри папки files, как в описи
+	Path string `json:"path"`
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// Index - полнотекстовый индекс документов закупок
+type Index interface {
+	// IndexDocuments заменяет тексты документов закупки id
+	IndexDocuments(id string, texts []Text) error
+}
+
+// Extractable проверяет, извлекается ли текст из файла с таким именем
+func Extractable(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".docx", ".xlsx", ".pdf":
+		return true
+	}
+	return false
+}
+
+// ExtractText извлекает текст из файла DOCX, XLSX или PDF. Сбой разбора
+// поврежденного файла возвращается ошибкой этого файла
+func ExtractText(filename string) (text string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			text, err = "", fmt.Errorf("сбой разбора %s: %v", filepath.Base(filename), r)
+		}
+	}()
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".docx":
+		text, err = docxText(filename)
+	case ".xlsx":
+		text, err = xlsxText(filename)
+	case ".pdf":
+		var data []byte
+		if data, err = os.ReadFile(filename); err == nil {
+			text, err = pdfText(data)
+		}
+	default:
+		return "", ErrUnsupported
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return truncateText(strings.ToValidUTF8(text, ""), maxTextSize), nil
+}
+
+func truncateText(text string, size int) string {
+	if len(text) <= size {
+		return text
+	}
+	text = text[:size]
+	for len(text) > 0 && !utf8.ValidString(text) {
+		text = text[:len(text)-1]
+	}
+	return text
+}
+
+// docxText собирает текст абзацев основного документа, колонтитулов и сносок
+func docxText(filename string) (string, error) {
+	reader, err := zip.OpenReader(filename)
+	if err != nil {
+		return "", fmt.Errorf("ошибка открытия DOCX: %w", err)
+	}
+	defer reader.Close()
+
+	var text strings.Builder
+	found := false
+	for _, file := range reader.File {
+		name := file.Name
+		if name != "word/document.xml" && name != "word/footnotes.xml" && name != "word/endnotes.xml" &&
+			!(strings.HasPrefix(name, "word/header") || strings.HasPrefix(name, "word/footer")) {
+			continue
+		}
+		found = true
+
+		part, err := file.Open()
+		if err != nil {
+			return "", err
+		}
+		err = wordText(&text, io.LimitReader(part, maxStreamSize))
+		part.Close()
+		if err != nil {
+			return "", fmt.Errorf("ошибка чтения %s: %w", name, err)
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("в DOCX нет word/document.xml")
+	}
+
+	return text.String(), nil
+}
+
+// wordText пишет текст из элементов w:t, абзацы разделяются переводом строки
+func wordText(out *strings.Builder, r io.Reader) error {
+	decoder := xml.NewDecoder(r)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				out.WriteString("\t")
+			case "br", "cr":
+				out.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch element.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				out.WriteString("\n")
+			case "tc":
+				out.WriteString("\t")
+			}
+		case xml.CharData:
+			if inText {
+				out.Write(element)
+			}
+		}
+	}
+}
+
+// xlsxText собирает значения ячеек всех листов: ячейки через табуляцию, строки с новой строки
+func xlsxText(filename string) (string, error) {
+	file, err := excelize.OpenFile(filename)
+	if err != nil {
+		return "", fmt.Errorf("ошибка открытия XLSX: %w", err)
+	}
+	defer file.Close()
+
+	var text strings.Builder
+	for _, sheet := range file.GetSheetList() {
+		rows, err := file.Rows(sheet)
+		if err != nil {
+			return "", fmt.Errorf("ошибка чтения листа %s: %w", sheet, err)
+		}
+
+		text.WriteString(sheet)
+		text.WriteString("\n")
+		for rows.Next() && text.Len() <= maxTextSize {
+			columns, err := rows.Columns()
+			if err != nil {
+				rows.Close()
+				return "", fmt.Errorf("ошибка чтения листа %s: %w", sheet, err)
+			}
+			if line := strings.TrimSpace(strings.Join(columns, "\t")); line != "" {
+				text.WriteString(line)
+				text.WriteString("\n")
+			}
+		}
+		rows.Close()
+	}
+
+	return text.String(), nil
+}
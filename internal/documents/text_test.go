@@ -0,0 +1,202 @@
+package documents
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// buildPDF собирает PDF из тел объектов 1, 2, 3... Разбор ищет объекты по
+// заголовкам, поэтому таблица xref не нужна
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	for i, object := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+func pdfStream(dict string, data []byte) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func flate(data string) []byte {
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+	writer.Write([]byte(data))
+	writer.Close()
+	return buf.Bytes()
+}
+
+// cp1251 кодирует русский текст в Windows-1251
+func cp1251Bytes(text string) string {
+	var out []byte
+	for _, r := range text {
+		switch {
+		case r >= 'А' && r <= 'я':
+			out = append(out, byte(0xC0+r-'А'))
+		case r == 'ё':
+			out = append(out, 0xB8)
+		default:
+			out = append(out, byte(r))
+		}
+	}
+	return string(out)
+}
+
+// pdfPages - каталог, дерево из одной страницы и шрифт F1 в объектах 1-4,
+// содержимое страницы - объект 5
+func pdfPages(font string, content string) []string {
+	return []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		font,
+		content,
+	}
+}
+
+func writeDocx(t *testing.T, filename string, parts map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, data := range parts {
+		w, _ := writer.Create(name)
+		w.Write([]byte(data))
+	}
+	writer.Close()
+	writeTestFile(t, filename, buf.Bytes())
+}
+
+func TestExtractText(t *testing.T) {
+	dir := t.TempDir()
+	simpleFont := "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"
+
+	// Шрифт Type0 с двухбайтовыми кодами и ToUnicode
+	cmap := `/CIDInit /ProcSet findresource begin
+begincmap
+1 begincodespacerange <0000> <FFFF> endcodespacerange
+2 beginbfchar
+<0001> <0412>
+<0002> <0020>
+endbfchar
+1 beginbfrange
+<0010> <0015> <0435>
+endbfrange
+endcmap`
+	type0 := buildPDF(append(pdfPages(
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Arial /Encoding /Identity-H /ToUnicode 6 0 R >>",
+		pdfStream("/Filter /FlateDecode", flate("BT /F1 12 Tf <0001001000110012> Tj ET")),
+	), pdfStream("", []byte(cmap)))...)
+
+	files := map[string][]byte{
+		"plain.pdf": buildPDF(pdfPages(simpleFont,
+			pdfStream("", []byte("BT /F1 12 Tf 72 720 Td [(Air)-20(handling) -400 (unit)] TJ 0 -14 Td (second line) Tj ET")))...),
+		"cp1251.pdf": buildPDF(pdfPages(simpleFont,
+			pdfStream("", []byte("BT /F1 12 Tf ("+cp1251Bytes("Монтаж вентиляции, ёмкость")+") Tj ET")))...),
+		"type0.pdf": type0,
+		"encrypted.pdf": buildPDF(append(pdfPages(simpleFont, pdfStream("", []byte("BT (secret) Tj ET"))),
+			"<< /Filter /Standard /V 2 >>")...),
+		"notes.txt":  []byte("текст"),
+		"broken.pdf": []byte("%PDF-1.4 garbage"),
+	}
+	for name, data := range files {
+		writeTestFile(t, filepath.Join(dir, name), data)
+	}
+	// Ссылка на словарь шифрования - в trailer
+	encrypted := bytes.Replace(files["encrypted.pdf"], []byte("<< /Root 1 0 R >>"), []byte("<< /Root 1 0 R /Encrypt 6 0 R >>"), 1)
+	writeTestFile(t, filepath.Join(dir, "encrypted.pdf"), encrypted)
+
+	writeDocx(t, filepath.Join(dir, "spec.docx"), map[string]string{
+		"word/document.xml": `<w:document xmlns:w="w"><w:body>
+			<w:p><w:r><w:t>Техническое</w:t></w:r><w:r><w:t xml:space="preserve"> задание</w:t></w:r></w:p>
+			<w:tbl><w:tr><w:tc><w:p><w:r><w:t>Приточная установка</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>2 шт</w:t></w:r></w:p></w:tc></w:tr></w:tbl>
+		</w:body></w:document>`,
+		"word/footer1.xml": `<w:ftr xmlns:w="w"><w:p><w:r><w:t>Колонтитул</w:t></w:r></w:p></w:ftr>`,
+	})
+	writeDocx(t, filepath.Join(dir, "empty.docx"), map[string]string{"[Content_Types].xml": "<Types/>"})
+
+	book := excelize.NewFile()
+	book.SetCellValue("Sheet1", "A1", "Наименование")
+	book.SetCellValue("Sheet1", "B1", "Количество")
+	book.SetCellValue("Sheet1", "A3", "Вентилятор ВР-80")
+	book.SetCellValue("Sheet1", "B3", 4)
+	book.NewSheet("Смета")
+	book.SetCellValue("Смета", "C2", "Итого")
+	if err := book.SaveAs(filepath.Join(dir, "list.xlsx")); err != nil {
+		t.Fatal(err)
+	}
+	book.Close()
+
+	tests := []struct {
+		name    string
+		want    []string
+		wantErr error
+	}{
+		{name: "plain.pdf", want: []string{"Airhandling unit", "second line"}},
+		{name: "cp1251.pdf", want: []string{"Монтаж вентиляции, ёмкость"}},
+		{name: "type0.pdf", want: []string{"Вежз"}},
+		{name: "spec.docx", want: []string{"Техническое задание\n", "Приточная установка\n", "2 шт", "Колонтитул"}},
+		{name: "list.xlsx", want: []string{"Sheet1\n", "Наименование\tКоличество\n", "Вентилятор ВР-80\t4\n", "Смета\n", "Итого"}},
+		{name: "encrypted.pdf", wantErr: errEncryptedPDF},
+		{name: "notes.txt", wantErr: ErrUnsupported},
+		{name: "empty.docx", wantErr: errors.New("в DOCX нет word/document.xml")},
+	}
+
+	for _, tt := range tests {
+		text, err := ExtractText(filepath.Join(dir, tt.name))
+		if tt.wantErr != nil {
+			if err == nil || (!errors.Is(err, tt.wantErr) && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("%s: ошибка %v, want %v", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(text, want) {
+				t.Errorf("%s: нет %q в %q", tt.name, want, text)
+			}
+		}
+	}
+
+	// Испорченный PDF дает пустой текст, а не сбой
+	if _, err := ExtractText(filepath.Join(dir, "broken.pdf")); err != nil {
+		t.Errorf("broken.pdf: %v", err)
+	}
+	if _, err := ExtractText(filepath.Join(dir, "missing.pdf")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("missing.pdf: %v", err)
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	tests := []struct {
+		text string
+		size int
+		want string
+	}{
+		{"вентиляция", 100, "вентиляция"},
+		{"вентиляция", 4, "ве"},
+		// Обрезка не разрывает символ
+		{"вентиляция", 5, "ве"},
+		{"abc", 0, ""},
+	}
+
+	for _, tt := range tests {
+		if got := truncateText(tt.text, tt.size); got != tt.want {
+			t.Errorf("truncateText(%q, %d) = %q, want %q", tt.text, tt.size, got, tt.want)
+		}
+	}
+}
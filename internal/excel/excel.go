@@ -2,6 +2,8 @@ package excel
 
 import (
 	"strconv"
+	"strings"
+	"tendertracker/internal/categories"
 	"tendertracker/internal/logger"
 	"tendertracker/internal/models"
 	"time"
@@ -9,26 +11,26 @@ import (
 	"github.com/xuri/excelize/v2"
 )
 
-func ToExcel(config models.Config, allTenders *models.TendersFromAllSites) (*excelize.File, error) {
+func ToExcel(config models.Config, allTenders *models.TendersFromAllSites, list categories.List) (*excelize.File, error) {
 	excelFile := excelize.NewFile()
 
-	if config.SearchVent {
-		if err := addTendersAndSheet(excelFile, allTenders.ZakupkiGovRu.Vent, allTenders.ZakupkiSber.Vent, "Вентиляция"); err != nil {
-			logger.SugaredLogger.Warn(err)
+	for _, category := range list {
+		if !config.IsEnabled(category.Name) {
+			continue
 		}
-	}
-	if config.SearchDoors {
-		if err := addTendersAndSheet(excelFile, allTenders.ZakupkiGovRu.Doors, allTenders.ZakupkiSber.Doors, "Двери"); err != nil {
+		if err := addTendersAndSheet(excelFile, allTenders.Sites, category.Name, category.Sheet); err != nil {
 			logger.SugaredLogger.Warn(err)
 		}
 	}
-	if config.SearchBuild {
-		if err := addTendersAndSheet(excelFile, allTenders.ZakupkiGovRu.Build, allTenders.ZakupkiSber.Build, "Строительство"); err != nil {
+
+	if config.EnrichNotices {
+		if err := addNoticesSheet(excelFile, config, allTenders, list); err != nil {
 			logger.SugaredLogger.Warn(err)
 		}
 	}
-	if config.SearchMetal {
-		if err := addTendersAndSheet(excelFile, allTenders.ZakupkiGovRu.Metal, allTenders.ZakupkiSber.Metal, "Металл."); err != nil {
+
+	if config.ReportRejected && len(allTenders.Rejected) > 0 {
+		if err := addRejectedSheet(excelFile, allTenders, list); err != nil {
 			logger.SugaredLogger.Warn(err)
 		}
 	}
@@ -36,7 +38,230 @@ func ToExcel(config models.Config, allTenders *models.TendersFromAllSites) (*exc
 	return excelFile, nil
 }
 
-func addTendersAndSheet(f *excelize.File, tendersZakupkiGovRu, tendersSber []models.Tender, sheet string) error {
+// RejectedSheet - лист с закупками, отсеянными при поиске
+const RejectedSheet = "Отфильтровано"
+
+// addRejectedSheet добавляет лист отсеянных закупок с причиной отсева
+func addRejectedSheet(f *excelize.File, allTenders *models.TendersFromAllSites, list categories.List) error {
+	sheet := RejectedSheet
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+	if index, _ := f.GetSheetIndex("Sheet1"); index != -1 {
+		f.DeleteSheet("Sheet1")
+	}
+
+	style, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center", WrapText: true},
+		Font:      &excelize.Font{Bold: true, Size: 12},
+	})
+	if err != nil {
+		return err
+	}
+
+	f.SetColWidth(sheet, "A", "B", 20)
+	f.SetColWidth(sheet, "C", "C", 26)
+	f.SetColWidth(sheet, "D", "D", 40)
+	f.SetColWidth(sheet, "E", "E", 100)
+	f.SetColWidth(sheet, "F", "F", 20)
+	f.SetColWidth(sheet, "G", "G", 24)
+	f.SetColWidth(sheet, "H", "H", 40)
+	f.SetColWidth(sheet, "I", "I", 16)
+	f.SetCellValue(sheet, "A1", "Площадка")
+	f.SetCellValue(sheet, "B1", "Категория")
+	f.SetCellValue(sheet, "C1", "Причина")
+	f.SetCellValue(sheet, "D1", "Правило")
+	f.SetCellValue(sheet, "E1", "Объект закупки + ссылка")
+	f.SetCellValue(sheet, "F1", "Начальная цена")
+	f.SetCellValue(sheet, "G1", "Номер")
+	f.SetCellValue(sheet, "H1", "Заказчик")
+	f.SetCellValue(sheet, "I1", "Дата размещения")
+	f.SetCellStyle(sheet, "A1", "I1", style)
+
+	titles := make(map[string]string, len(allTenders.Sites))
+	for _, site := range allTenders.Sites {
+		titles[site.Name] = site.Title
+	}
+	sheets := make(map[string]string, len(list))
+	for _, category := range list {
+		sheets[category.Name] = category.Sheet
+	}
+
+	dateStyle, _ := f.NewStyle(&excelize.Style{NumFmt: 14}) // dd.mm.yyyy
+	priceFormat := "#,##0.00"
+	priceStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &priceFormat})
+
+	for i, rejection := range allTenders.Rejected {
+		row := strconv.Itoa(i + 2)
+		tender := rejection.Tender
+
+		f.SetCellValue(sheet, "A"+row, valueOr(titles[rejection.Source], rejection.Source))
+		f.SetCellValue(sheet, "B"+row, valueOr(sheets[rejection.Category], rejection.Category))
+		f.SetCellValue(sheet, "C"+row, rejection.ReasonTitle())
+		f.SetCellValue(sheet, "D"+row, rejection.Detail)
+		f.SetCellValue(sheet, "E"+row, tender.Title)
+		if tender.Link != "" {
+			f.SetCellHyperLink(sheet, "E"+row, tender.Link, "External")
+		}
+		if tender.Price > 0 {
+			f.SetCellValue(sheet, "F"+row, tender.Rubles())
+			f.SetCellStyle(sheet, "F"+row, "F"+row, priceStyle)
+		} else {
+			f.SetCellValue(sheet, "F"+row, tender.PriceText())
+		}
+		f.SetCellValue(sheet, "G"+row, tender.Number)
+		f.SetCellValue(sheet, "H"+row, tender.Customer)
+		setDate(f, sheet, "I"+row, tender.PublishDate, dateStyle)
+	}
+
+	return nil
+}
+
+// NoticesSheet - лист со сведениями из извещений
+const NoticesSheet = "Извещения"
+
+// addNoticesSheet добавляет лист со сведениями извещений по выбранным категориям.
+// Лист не создается, если ни у одной закупки сведений нет
+func addNoticesSheet(f *excelize.File, config models.Config, allTenders *models.TendersFromAllSites, list categories.List) error {
+	type row struct {
+		category string
+		tender   models.Tender
+	}
+
+	var rows []row
+	for _, category := range list {
+		if !config.IsEnabled(category.Name) {
+			continue
+		}
+		for _, site := range allTenders.Sites {
+			for _, tender := range site.Tenders[category.Name] {
+				if tender.Details != nil {
+					rows = append(rows, row{category: category.Sheet, tender: tender})
+				}
+			}
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	sheet := NoticesSheet
+	if _, err := f.NewSheet(sheet); err != nil {
+		return err
+	}
+
+	style, err := f.NewStyle(&excelize.Style{
+		Alignment: &excelize.Alignment{Horizontal: "center", WrapText: true},
+		Font:      &excelize.Font{Bold: true, Size: 12},
+	})
+	if err != nil {
+		return err
+	}
+	wrapStyle, _ := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{WrapText: true, Vertical: "top"}})
+	priceFormat := "#,##0.00"
+	priceStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &priceFormat, Alignment: &excelize.Alignment{Vertical: "top"}})
+
+	headers := []struct {
+		title string
+		width float64
+	}{
+		{"Категория", 20},
+		{"Номер", 24},
+		{"Объект закупки + ссылка", 60},
+		{"Окончание подачи заявок", 22},
+		{"Дата проведения", 22},
+		{"Обеспечение заявки", 18},
+		{"Обеспечение контракта", 18},
+		{"Место поставки", 40},
+		{"Контактное лицо", 30},
+		{"Телефон", 20},
+		{"Email", 28},
+		{"ОКПД2", 20},
+		{"Лоты", 50},
+		{"Документы", 60},
+	}
+	for i, header := range headers {
+		column, _ := excelize.ColumnNumberToName(i + 1)
+		f.SetColWidth(sheet, column, column, header.width)
+		f.SetCellValue(sheet, column+"1", header.title)
+	}
+	f.SetCellStyle(sheet, "A1", "N1", style)
+
+	for i, r := range rows {
+		line := strconv.Itoa(i + 2)
+		tender, details := r.tender, r.tender.Details
+
+		f.SetCellValue(sheet, "A"+line, r.category)
+		f.SetCellValue(sheet, "B"+line, tender.Number)
+		f.SetCellValue(sheet, "C"+line, tender.Title)
+		if tender.Link != "" {
+			f.SetCellHyperLink(sheet, "C"+line, tender.Link, "External")
+		}
+		f.SetCellValue(sheet, "D"+line, formatDateTimeZone(details.SubmissionDeadline))
+		f.SetCellValue(sheet, "E"+line, formatDateTimeZone(details.AuctionDate))
+		setKopecks(f, sheet, "F"+line, details.ApplicationSecurity, priceStyle)
+		setKopecks(f, sheet, "G"+line, details.ContractSecurity, priceStyle)
+		f.SetCellValue(sheet, "H"+line, details.DeliveryPlace)
+		f.SetCellValue(sheet, "I"+line, details.ContactPerson)
+		f.SetCellValue(sheet, "J"+line, details.ContactPhone)
+		f.SetCellValue(sheet, "K"+line, details.ContactEmail)
+		f.SetCellValue(sheet, "L"+line, strings.Join(details.OKPD2, "\n"))
+
+		var lots []string
+		for _, lot := range details.Lots {
+			text := "№ " + lot.Number + " " + lot.Title
+			if lot.Price > 0 {
+				text += " - " + models.Tender{Price: lot.Price}.PriceText()
+			}
+			lots = append(lots, text)
+		}
+		f.SetCellValue(sheet, "M"+line, strings.Join(lots, "\n"))
+
+		var documents []string
+		for _, document := range details.Documents {
+			documents = append(documents, document.Name)
+		}
+		f.SetCellValue(sheet, "N"+line, strings.Join(documents, "\n"))
+
+		f.SetCellStyle(sheet, "H"+line, "N"+line, wrapStyle)
+	}
+
+	return nil
+}
+
+// formatDateTimeZone - дата и время в часовом поясе площадки, "25.10.2026 09:00 МСК+4"
+func formatDateTimeZone(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+
+	text := date.Format("02.01.2006 15:04")
+	switch zone, _ := date.Zone(); {
+	case date.Location() == models.Moscow || zone == "MSK":
+		return text + " МСК"
+	case zone != "":
+		return text + " " + zone
+	}
+	return text
+}
+
+// setKopecks записывает сумму в копейках числом в рублях, 0 - пустая ячейка
+func setKopecks(f *excelize.File, sheet, cell string, kopecks int64, style int) {
+	if kopecks <= 0 {
+		return
+	}
+	f.SetCellValue(sheet, cell, float64(kopecks)/100)
+	f.SetCellStyle(sheet, cell, cell, style)
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func addTendersAndSheet(f *excelize.File, sites []models.SiteTenders, category, sheet string) error {
 	f.NewSheet(sheet)
 
 	index, _ := f.GetSheetIndex("Sheet1")
@@ -60,39 +285,27 @@ func addTendersAndSheet(f *excelize.File, tendersZakupkiGovRu, tendersSber []mod
 		return err
 	}
 
-	err = f.MergeCell(sheet, "A2", "E2")
-	if err != nil {
-		return err
-	}
+	index = 2
 
-	err = f.SetCellStyle(sheet, "A2", "E2", titleStyle)
-	if err != nil {
-		return err
-	}
+	for _, site := range sites {
+		err = f.MergeCell(sheet, "A"+strconv.Itoa(index), "E"+strconv.Itoa(index))
+		if err != nil {
+			return err
+		}
 
-	f.SetCellValue(sheet, "A2", "Zakupki.Gov.ru")
+		err = f.SetCellStyle(sheet, "A"+strconv.Itoa(index), "E"+strconv.Itoa(index), titleStyle)
+		if err != nil {
+			return err
+		}
 
-	index = 3
+		f.SetCellValue(sheet, "A"+strconv.Itoa(index), site.Title)
+		logger.SugaredLogger.Debugf("Added title %s to sheet: %s to line: %d", site.Name, sheet, index)
 
-	setTenderInf(f, sheet, tendersZakupkiGovRu, &index)
+		index++
 
-	err = f.MergeCell(sheet, "A"+strconv.Itoa(index), "E"+strconv.Itoa(index))
-	if err != nil {
-		return err
+		setTenderInf(f, sheet, site.Tenders[category], &index)
 	}
 
-	err = f.SetCellStyle(sheet, "A"+strconv.Itoa(index), "E"+strconv.Itoa(index), titleStyle)
-	if err != nil {
-		return err
-	}
-
-	f.SetCellValue(sheet, "A"+strconv.Itoa(index), "Сбер-АСТ")
-	logger.SugaredLogger.Debugf("Added title Sber to sheet: %s to line: %s", sheet, index)
-
-	index++
-
-	setTenderInf(f, sheet, tendersSber, &index)
-
 	return nil
 }
 
@@ -127,28 +340,61 @@ func CreateHeader(f *excelize.File, sheet string) error {
 	f.SetColWidth(sheet, "D", "D", 40)
 	f.SetColWidth(sheet, "E", "E", 100)
 	f.SetColWidth(sheet, "F", "F", 20)
+	f.SetColWidth(sheet, "G", "G", 24)
+	f.SetColWidth(sheet, "H", "H", 12)
+	f.SetColWidth(sheet, "I", "J", 30)
 	f.SetCellValue(sheet, "A1", "Дата размещения")
 	f.SetCellValue(sheet, "B1", "Дата окончания")
 	f.SetCellValue(sheet, "C1", "Расположение")
 	f.SetCellValue(sheet, "D1", "Заказчик")
 	f.SetCellValue(sheet, "E1", "Объект закупки + ссылка")
 	f.SetCellValue(sheet, "F1", "Начальная цена")
-	f.SetCellStyle(sheet, "A1", "F1", style)
-	f.SetCellValue(sheet, "G1", "Дата создания таблицы: "+time.Now().UTC().Format("02.01.2006"))
+	f.SetCellValue(sheet, "G1", "Номер")
+	f.SetCellValue(sheet, "H1", "Закон")
+	f.SetCellValue(sheet, "I1", "Способ закупки")
+	f.SetCellValue(sheet, "J1", "Статус")
+	f.SetCellStyle(sheet, "A1", "J1", style)
+	f.SetCellValue(sheet, "K1", "Дата создания таблицы: "+time.Now().UTC().Format("02.01.2006"))
 
 	return nil
 }
 
 func setTenderInf(f *excelize.File, sheet string, tender []models.Tender, index *int) {
+	dateStyle, _ := f.NewStyle(&excelize.Style{NumFmt: 14}) // dd.mm.yyyy
+	priceFormat := "#,##0.00"
+	priceStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &priceFormat})
+
 	for _, value := range tender {
-		f.SetCellValue(sheet, "A"+strconv.Itoa(*index), value.PublishDate)
-		f.SetCellValue(sheet, "B"+strconv.Itoa(*index), value.EndDate)
-		f.SetCellValue(sheet, "C"+strconv.Itoa(*index), value.Region)
-		f.SetCellValue(sheet, "D"+strconv.Itoa(*index), value.Customer)
-		f.SetCellValue(sheet, "E"+strconv.Itoa(*index), value.Title)
-		f.SetCellValue(sheet, "F"+strconv.Itoa(*index), value.Price)
-
-		f.SetCellHyperLink(sheet, "E"+strconv.Itoa(*index), value.Link, "External")
+		row := strconv.Itoa(*index)
+
+		setDate(f, sheet, "A"+row, value.PublishDate, dateStyle)
+		setDate(f, sheet, "B"+row, value.EndDate, dateStyle)
+		f.SetCellValue(sheet, "C"+row, value.Region)
+		f.SetCellValue(sheet, "D"+row, value.Customer)
+		f.SetCellValue(sheet, "E"+row, value.Title)
+		if value.Price > 0 {
+			f.SetCellValue(sheet, "F"+row, value.Rubles())
+			f.SetCellStyle(sheet, "F"+row, "F"+row, priceStyle)
+		} else {
+			f.SetCellValue(sheet, "F"+row, value.PriceText())
+		}
+		f.SetCellValue(sheet, "G"+row, value.Number)
+		f.SetCellValue(sheet, "H"+row, value.Law)
+		f.SetCellValue(sheet, "I"+row, value.Method)
+		f.SetCellValue(sheet, "J"+row, value.Status)
+
+		f.SetCellHyperLink(sheet, "E"+row, value.Link, "External")
 		*index++
 	}
 }
+
+// setDate записывает дату ячейкой-датой, чтобы по ней работали сортировка и фильтры Excel
+func setDate(f *excelize.File, sheet, cell string, date time.Time, style int) {
+	if date.IsZero() {
+		return
+	}
+
+	local := date.In(models.Moscow)
+	f.SetCellValue(sheet, cell, time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC))
+	f.SetCellStyle(sheet, cell, cell, style)
+}
@@ -0,0 +1,409 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tendertracker/internal/logger"
+)
+
+// Limit - ограничения обращений к одному хосту
+type Limit struct {
+	// RequestsPerSecond - средняя частота запросов, 0 - без ограничения
+	RequestsPerSecond float64
+	// Burst - сколько запросов можно отправить подряд без ожидания
+	Burst int
+	// MaxConcurrent - предел одновременных запросов, 0 - без предела
+	MaxConcurrent int
+	// Timeout - ограничение времени одного запроса вместе с чтением ответа
+	Timeout time.Duration
+	// MaxAttempts - число попыток запроса, меньше 1 - одна попытка
+	MaxAttempts int
+}
+
+type idleTimeoutKey struct{}
+
+// WithIdleTimeout - для скачивания больших файлов: Limit.Timeout хоста
+// ограничивает только ожидание ответа, а тело читается сколько угодно долго,
+// пока данные приходят не реже раза в idle
+func WithIdleTimeout(ctx context.Context, idle time.Duration) context.Context {
+	return context.WithValue(ctx, idleTimeoutKey{}, idle)
+}
+
+func idleTimeout(ctx context.Context) time.Duration {
+	idle, _ := ctx.Value(idleTimeoutKey{}).(time.Duration)
+	return idle
+}
+
+// RetryFunc получает номер неудачной попытки, паузу перед следующей и причину
+type RetryFunc func(attempt int, wait time.Duration, err error)
+
+// Fetcher - общий HTTP-клиент площадок. Соединения переиспользуются между
+// всеми поисками, а запросы к одному хосту ограничиваются по частоте и числу
+// одновременных. Ответы 429 и 503 и сетевые ошибки повторяются с нарастающей
+// паузой, заголовок Retry-After приостанавливает все запросы к хосту
+type Fetcher struct {
+	// Default - ограничения для хостов без собственных настроек
+	Default Limit
+	// BaseDelay и MaxDelay - пауза после первой неудачной попытки и ее предел
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	client *http.Client
+	mu     sync.Mutex
+	limits map[string]Limit
+	hosts  map[string]*host
+}
+
+func New() *Fetcher {
+	return &Fetcher{
+		Default: Limit{
+			RequestsPerSecond: 2,
+			Burst:             2,
+			MaxConcurrent:     2,
+			Timeout:           60 * time.Second,
+			MaxAttempts:       3,
+		},
+		BaseDelay: 2 * time.Second,
+		MaxDelay:  time.Minute,
+		client:    &http.Client{},
+		limits:    make(map[string]Limit),
+		hosts:     make(map[string]*host),
+	}
+}
+
+// SetLimit задает ограничения для хоста, например "zakupki.gov.ru"
+func (f *Fetcher) SetLimit(hostname string, limit Limit) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.limits[hostname] = limit
+	delete(f.hosts, hostname)
+}
+
+// Do выполняет запрос с ограничениями его хоста и повторами. Тело запроса
+// для повтора берется из req.GetBody, его заполняет http.NewRequest для
+// strings.Reader и bytes.Reader. Ответ с кодом не из числа повторяемых
+// возвращается как есть, Body нужно закрыть
+func (f *Fetcher) Do(req *http.Request, onRetry RetryFunc) (*http.Response, error) {
+	ctx := req.Context()
+	h := f.host(req.URL.Hostname())
+
+	attempts := max(h.limit.MaxAttempts, 1)
+	for attempt := 1; ; attempt++ {
+		attemptReq, err := rewind(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := h.do(f.client, attemptReq)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		retryAfter, retry := retryable(resp, err)
+		if !retry {
+			return resp, err
+		}
+		if attempt >= attempts {
+			if err != nil {
+				return nil, fmt.Errorf("ошибка выполнения запроса после %d попыток: %w", attempts, err)
+			}
+			return resp, nil
+		}
+
+		if err == nil {
+			err = fmt.Errorf("статус код %d", resp.StatusCode)
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		wait := f.backoff(attempt)
+		if retryAfter > 0 {
+			// Площадка сама сказала, когда повторить, - ждут все запросы к ней
+			wait = min(max(wait, retryAfter), f.MaxDelay)
+			h.pause(wait)
+		}
+
+		logger.SugaredLogger.Debugf("%s: попытка %d не удалась, повтор через %v: %v", req.URL.Host, attempt, wait, err)
+		if onRetry != nil {
+			onRetry(attempt, wait, err)
+		}
+
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff - пауза после неудачной попытки: удваивается с каждой попыткой,
+// случайная добавка разводит повторы одновременных запросов
+func (f *Fetcher) backoff(attempt int) time.Duration {
+	delay := min(f.BaseDelay<<(attempt-1), f.MaxDelay)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func (f *Fetcher) host(hostname string) *host {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h, ok := f.hosts[hostname]
+	if !ok {
+		limit, ok := f.limits[hostname]
+		if !ok {
+			limit = f.Default
+		}
+		h = newHost(limit)
+		f.hosts[hostname] = h
+	}
+	return h
+}
+
+// rewind готовит запрос к очередной попытке, заново открывая его тело
+func rewind(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 1 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("тело запроса нельзя отправить повторно")
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// retryable решает, стоит ли повторять запрос, и возвращает паузу из Retry-After
+func retryable(resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		return 0, true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), true
+	case http.StatusBadGateway, http.StatusGatewayTimeout:
+		return 0, true
+	}
+	return 0, false
+}
+
+// parseRetryAfter разбирает Retry-After в секундах или в виде даты
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
+
+// host - очередь запросов к одному хосту
+type host struct {
+	limit Limit
+	// slots - места для одновременных запросов, nil - без предела
+	slots chan struct{}
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func newHost(limit Limit) *host {
+	h := &host{
+		limit:  limit,
+		tokens: float64(max(limit.Burst, 1)),
+		last:   time.Now(),
+	}
+	if limit.MaxConcurrent > 0 {
+		h.slots = make(chan struct{}, limit.MaxConcurrent)
+	}
+	return h
+}
+
+// do выполняет одну попытку: занимает место, дожидается своей очереди по
+// частоте и держит место, пока не закрыто тело ответа
+func (h *host) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if h.slots != nil {
+			<-h.slots
+		}
+	}
+
+	if err := sleep(ctx, h.reserve(time.Now())); err != nil {
+		release()
+		return nil, err
+	}
+
+	cancel := context.CancelFunc(func() {})
+	var watchdog *idleWatchdog
+	if idle := idleTimeout(ctx); idle > 0 {
+		var idleCtx context.Context
+		idleCtx, cancel = context.WithCancel(ctx)
+		watchdog = newIdleWatchdog(idle, h.limit.Timeout, cancel)
+		req = req.WithContext(idleCtx)
+	} else if h.limit.Timeout > 0 {
+		var timeoutCtx context.Context
+		timeoutCtx, cancel = context.WithTimeout(ctx, h.limit.Timeout)
+		req = req.WithContext(timeoutCtx)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		watchdog.stop()
+		cancel()
+		release()
+		return nil, err
+	}
+
+	body := resp.Body
+	if watchdog != nil {
+		body = &idleBody{ReadCloser: body, watchdog: watchdog}
+	}
+	resp.Body = &releaseBody{ReadCloser: body, release: func() {
+		watchdog.stop()
+		cancel()
+		release()
+	}}
+	return resp, nil
+}
+
+// idleWatchdog отменяет запрос, если ответ или очередная часть тела не
+// пришли вовремя
+type idleWatchdog struct {
+	idle  time.Duration
+	timer *time.Timer
+	fired atomic.Bool
+}
+
+// newIdleWatchdog ждет ответа не дольше first (0 - idle), затем каждой части тела - idle
+func newIdleWatchdog(idle, first time.Duration, cancel context.CancelFunc) *idleWatchdog {
+	if first <= 0 {
+		first = idle
+	}
+
+	w := &idleWatchdog{idle: idle}
+	w.timer = time.AfterFunc(first, func() {
+		w.fired.Store(true)
+		cancel()
+	})
+	return w
+}
+
+func (w *idleWatchdog) stop() {
+	if w != nil {
+		w.timer.Stop()
+	}
+}
+
+// idleBody продлевает время ожидания после каждой прочитанной части тела
+type idleBody struct {
+	io.ReadCloser
+	watchdog *idleWatchdog
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.watchdog.timer.Reset(b.watchdog.idle)
+	}
+	if err != nil && err != io.EOF && b.watchdog.fired.Load() {
+		err = fmt.Errorf("данные не приходили дольше %v: %w", b.watchdog.idle, err)
+	}
+	return n, err
+}
+
+// reserve берет токен и возвращает, сколько ждать до отправки запроса
+func (h *host) reserve(now time.Time) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var wait time.Duration
+	if h.pausedUntil.After(now) {
+		wait = h.pausedUntil.Sub(now)
+	}
+
+	rate := h.limit.RequestsPerSecond
+	if rate <= 0 {
+		return wait
+	}
+
+	burst := float64(max(h.limit.Burst, 1))
+	h.tokens = math.Min(burst, h.tokens+now.Sub(h.last).Seconds()*rate)
+	h.last = now
+
+	// Токены уходят в минус - так выстраивается очередь ожидающих
+	h.tokens--
+	if h.tokens < 0 {
+		wait = max(wait, time.Duration(-h.tokens/rate*float64(time.Second)))
+	}
+	return wait
+}
+
+// pause приостанавливает новые запросы к хосту
+func (h *host) pause(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(h.pausedUntil) {
+		h.pausedUntil = until
+	}
+}
+
+// releaseBody освобождает место хоста при закрытии тела ответа
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
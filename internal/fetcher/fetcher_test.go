@@ -0,0 +1,236 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tendertracker/internal/logger"
+)
+
+func newTestFetcher(limit Limit) *Fetcher {
+	f := New()
+	f.Default = limit
+	f.BaseDelay = time.Millisecond
+	f.MaxDelay = 50 * time.Millisecond
+	return f
+}
+
+func get(t *testing.T, ctx context.Context, f *Fetcher, link string) (*http.Response, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Do(req, nil)
+}
+
+// Место хоста занято, пока не закрыто тело ответа: вложенный запрос к тому же
+// хосту при открытом теле ждет, а после закрытия выполняется
+func TestSlotHeldUntilBodyClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.URL.Path)
+	}))
+	defer server.Close()
+
+	f := newTestFetcher(Limit{MaxConcurrent: 1, MaxAttempts: 1, Timeout: 5 * time.Second})
+
+	outer, err := get(t, context.Background(), f, server.URL+"/listing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := get(t, ctx, f, server.URL+"/notice"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("вложенный запрос при открытом теле: %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	io.ReadAll(outer.Body)
+	outer.Body.Close()
+	// Повторное закрытие не освобождает чужое место
+	outer.Body.Close()
+
+	inner, err := get(t, context.Background(), f, server.URL+"/notice")
+	if err != nil {
+		t.Fatalf("запрос после закрытия тела: %v", err)
+	}
+	body, _ := io.ReadAll(inner.Body)
+	inner.Body.Close()
+	if string(body) != "/notice" {
+		t.Errorf("тело %q", body)
+	}
+}
+
+// Запросы, которые закрывают ответ перед следующим запросом, не упираются
+// в предел одновременных, а сам предел соблюдается
+func TestMaxConcurrent(t *testing.T) {
+	var active, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	f := newTestFetcher(Limit{MaxConcurrent: 2, MaxAttempts: 1, Timeout: 5 * time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Выдача и затем извещение, как в парсерах
+			for _, path := range []string{"/listing", "/notice"} {
+				resp, err := get(t, ctx, f, server.URL+path)
+				if err != nil {
+					errs <- err
+					return
+				}
+				io.ReadAll(resp.Body)
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("одновременных запросов %d, предел 2", peak.Load())
+	}
+}
+
+// Ответ 429 закрывается перед повтором, иначе повтор ждал бы место хоста
+func TestRetryAfter(t *testing.T) {
+	logger.InitLogger("error")
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	f := newTestFetcher(Limit{MaxConcurrent: 1, MaxAttempts: 3, Timeout: 5 * time.Second})
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	var retries []int
+	resp, err := f.Do(req, func(attempt int, wait time.Duration, err error) {
+		retries = append(retries, attempt)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 || len(retries) != 1 {
+		t.Errorf("статус %d, запросов %d, повторов %v", resp.StatusCode, calls.Load(), retries)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"-5", 0},
+		{"Sun, 18 Oct 2026 12:00:30 GMT", 30 * time.Second},
+		{"Sun, 18 Oct 2026 11:00:00 GMT", 0},
+		{"скоро", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// Большой файл читается дольше Limit.Timeout, пока данные идут без остановок.
+// Без WithIdleTimeout таймаут хоста обрывает чтение, при остановке потока
+// чтение обрывает WithIdleTimeout
+func TestIdleTimeout(t *testing.T) {
+	logger.InitLogger("error")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		for i := range 10 {
+			if r.URL.Path == "/stall" && i == 1 {
+				select {
+				case <-time.After(time.Second):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			io.WriteString(w, "chunk")
+			flusher.Flush()
+			select {
+			case <-time.After(30 * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	f := newTestFetcher(Limit{MaxAttempts: 1, Timeout: 150 * time.Millisecond})
+
+	tests := []struct {
+		name string
+		path string
+		idle time.Duration
+		ok   bool
+	}{
+		{"host timeout", "/stream", 0, false},
+		{"steady stream", "/stream", 150 * time.Millisecond, true},
+		{"stalled stream", "/stall", 150 * time.Millisecond, false},
+	}
+
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.idle > 0 {
+			ctx = WithIdleTimeout(ctx, tt.idle)
+		}
+
+		resp, err := get(t, ctx, f, server.URL+tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if tt.ok && (err != nil || len(data) != 50) {
+			t.Errorf("%s: прочитано %d байт, ошибка %v", tt.name, len(data), err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("%s: чтение не прервано, прочитано %d байт", tt.name, len(data))
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tendertracker/internal/districts"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// listValues читает параметр, который можно повторять или перечислять через запятую
+func listValues(c *gin.Context, name string) []string {
+	var values []string
+	for _, value := range c.QueryArray(name) {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+	return values
+}
+
+// parseQueryTime читает дату ГГГГ-ММ-ДД по Москве или время в RFC 3339.
+// Для верхней границы (endOfDay) дата без времени означает конец дня
+func parseQueryTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, models.Moscow)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("некорректная дата %q, нужен формат ГГГГ-ММ-ДД", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// parsePrice читает цену в рублях и возвращает копейки
+func parsePrice(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	rubles, err := strconv.ParseFloat(value, 64)
+	if err != nil || rubles < 0 {
+		return 0, fmt.Errorf("некорректная цена %q", value)
+	}
+	return int64(rubles*100 + 0.5), nil
+}
+
+// bindTenderQuery читает параметры отбора тендеров из строки запроса
+func bindTenderQuery(c *gin.Context) (storage.TenderQuery, error) {
+	query := storage.TenderQuery{
+		Categories: listValues(c, "category"),
+		Sources:    listValues(c, "source"),
+		Region:     strings.TrimSpace(c.Query("region")),
+		Text:       strings.TrimSpace(c.Query("q")),
+		Cursor:     c.Query("cursor"),
+		Limit:      defaultPageSize,
+	}
+
+	for _, value := range listValues(c, "district") {
+		code := districts.Code(value)
+		if code == "" {
+			return query, fmt.Errorf("неизвестный федеральный округ %q", value)
+		}
+		query.Districts = append(query.Districts, code)
+	}
+
+	var err error
+	if query.PriceFrom, err = parsePrice(c.Query("price_min")); err != nil {
+		return query, err
+	}
+	if query.PriceTo, err = parsePrice(c.Query("price_max")); err != nil {
+		return query, err
+	}
+
+	if query.PublishedFrom, err = parseQueryTime(c.Query("published_from"), false); err != nil {
+		return query, err
+	}
+	if query.PublishedTo, err = parseQueryTime(c.Query("published_to"), true); err != nil {
+		return query, err
+	}
+	if query.EndFrom, err = parseQueryTime(c.Query("deadline_from"), false); err != nil {
+		return query, err
+	}
+	if query.EndTo, err = parseQueryTime(c.Query("deadline_to"), true); err != nil {
+		return query, err
+	}
+
+	// sort=price по возрастанию, sort=-price по убыванию.
+	// По умолчанию сначала последние опубликованные
+	sortField := c.DefaultQuery("sort", "-"+storage.SortPublishDate)
+	query.Desc = strings.HasPrefix(sortField, "-")
+	query.Sort = strings.TrimPrefix(sortField, "-")
+	if !storage.ValidSort(query.Sort) {
+		return query, fmt.Errorf("неизвестное поле сортировки %q", query.Sort)
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > maxPageSize {
+			return query, fmt.Errorf("limit должен быть от 1 до %d", maxPageSize)
+		}
+	}
+
+	return query, nil
+}
+
+// listTenders возвращает сохраненные тендеры с отбором, сортировкой и постраничным выводом
+func listTenders(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		query, err := bindTenderQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+			return
+		}
+
+		page, err := store.Find(query)
+		if err != nil {
+			if errors.Is(err, storage.ErrInvalidCursor) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+				return
+			}
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query tenders", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// getTender возвращает тендер по ключу storage.TenderID. Ключ может
+// содержать "/", поэтому маршрут читает остаток пути целиком
+func getTender(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimPrefix(c.Param("id"), "/")
+
+		stored, err := store.Get(id)
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read tender", "details": err.Error()})
+			return
+		}
+		if stored == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tender not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, stored)
+	}
+}
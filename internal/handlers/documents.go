@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"tendertracker/internal/documents"
+	"tendertracker/internal/jobs"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
+	"tendertracker/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+func documentsZipURL(id string) string {
+	return "/api/v1/documents-zip/" + id
+}
+
+// getDocuments возвращает опись скачанных документов закупки
+func getDocuments(archive *documents.Archive) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimPrefix(c.Param("id"), "/")
+
+		manifest, err := archive.Manifest(id)
+		if errors.Is(err, documents.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Documents not downloaded"})
+			return
+		}
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read documents", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"documents": manifest, "zip_url": documentsZipURL(id)})
+	}
+}
+
+// downloadDocuments запускает в фоне получение списка документов закупки с
+// ее площадки и их скачивание в папку закупки. Скачивание с распаковкой и
+// индексацией занимает минуты, поэтому сразу возвращается идентификатор
+// задачи, а опись потом отдает getDocuments. Повторный вызов заменяет прежние файлы
+func downloadDocuments(store *storage.Store, archive *documents.Archive, manager *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimPrefix(c.Param("id"), "/")
+
+		stored, err := store.Get(id)
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read tender", "details": err.Error()})
+			return
+		}
+		if stored == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tender not found"})
+			return
+		}
+
+		source, ok := sources.Find(stored.Source).(sources.DocumentSource)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Documents not supported",
+				"details": fmt.Sprintf("площадка %s не отдает документы закупок", stored.Source),
+			})
+			return
+		}
+
+		job := manager.Start(models.Config{}, func(ctx context.Context, job *jobs.Job) (any, error) {
+			list, err := source.Documents(ctx, stored.Tender)
+			if err != nil {
+				logger.SugaredLogger.Warnf("%s: не удалось получить список документов: %v", id, err)
+				return nil, fmt.Errorf("не удалось получить список документов: %w", err)
+			}
+
+			manifest, err := archive.Download(ctx, id, stored.Tender, list)
+			if err != nil {
+				logger.SugaredLogger.Warnf("%s: не удалось скачать документы: %v", id, err)
+				return nil, err
+			}
+
+			logger.SugaredLogger.Infof("%s: скачано документов: %d", id, len(manifest.Files))
+			return gin.H{"documents": manifest, "zip_url": documentsZipURL(id)}, nil
+		})
+
+		response := jobLinks(job)
+		response["documents_url"] = "/api/v1/documents/" + id
+		c.JSON(http.StatusAccepted, response)
+	}
+}
+
+// downloadDocumentsZip отдает все документы закупки одним zip-архивом
+func downloadDocumentsZip(archive *documents.Archive) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimPrefix(c.Param("id"), "/")
+
+		if _, err := archive.Manifest(id); err != nil {
+			if errors.Is(err, documents.ErrNoDocuments) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Documents not downloaded"})
+				return
+			}
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read documents", "details": err.Error()})
+			return
+		}
+
+		filename := "Документы_" + documents.FolderName(id) + ".zip"
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(filename))
+
+		// Заголовки уже отправлены, поэтому ошибку можно только записать в журнал
+		if err := archive.WriteZip(id, c.Writer); err != nil {
+			logger.SugaredLogger.Warnf("%s: ошибка записи архива документов: %v", id, err)
+		}
+	}
+}
+
+// searchDocuments ищет закупки по словам в тексте скачанных документов.
+// q - слова запроса, остальные параметры отбора - как у списка тендеров
+func searchDocuments(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		text := strings.TrimSpace(c.Query("q"))
+		if text == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": "не задан текст поиска q"})
+			return
+		}
+
+		filter, err := bindTenderQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+			return
+		}
+		// q в этом запросе ищется в документах, а не в названии
+		filter.Text = ""
+
+		result, err := store.SearchDocuments(storage.DocumentQuery{Text: text, Filter: filter, Limit: filter.Limit})
+		if errors.Is(err, storage.ErrEmptyQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+			return
+		}
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search documents", "details": err.Error()})
+			return
+		}
+
+		items := make([]gin.H, 0, len(result.Matches))
+		for _, match := range result.Matches {
+			items = append(items, gin.H{
+				"tender":  match.Tender,
+				"files":   match.Files,
+				"zip_url": documentsZipURL(match.Tender.ID),
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{"matches": items, "total": result.Total})
+	}
+}
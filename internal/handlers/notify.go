@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/notify"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testEmail отправляет пробное письмо, чтобы проверить настройки почты
+func testEmail(email *notify.Email) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		addresses, err := notify.ParseEmails(c.PostForm("to"))
+		if err != nil || len(addresses) != 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": "укажите один адрес в поле to"})
+			return
+		}
+
+		if err := email.SendTest(c.Request.Context(), addresses[0]); err != nil {
+			logger.SugaredLogger.Warnf("Пробное письмо на %s не отправлено: %v", addresses[0], err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send email", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "sent"})
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/reports"
+
+	"github.com/gin-gonic/gin"
+)
+
+func downloadURL(id string) string {
+	return "/tender/download?id=" + url.QueryEscape(id)
+}
+
+// downloadReport отдает файл отчета. Принимается только идентификатор
+// из хранилища отчетов, произвольные пути не читаются
+func downloadReport(store *reports.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report, path, err := store.Get(c.Query("id"))
+		if errors.Is(err, reports.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+			return
+		}
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to read report",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		c.FileAttachment(path, report.Filename)
+	}
+}
+
+// listReports возвращает прошлые отчеты с параметрами поиска
+func listReports(store *reports.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := store.List()
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "Failed to list reports",
+				"details": err.Error(),
+			})
+			return
+		}
+
+		items := make([]gin.H, 0, len(list))
+		for _, report := range list {
+			items = append(items, gin.H{
+				"report":       report,
+				"download_url": downloadURL(report.ID),
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{"reports": items})
+	}
+}
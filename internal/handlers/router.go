@@ -1,22 +1,50 @@
 package handlers
 
 import (
-	"regexp"
+	"path/filepath"
+	"tendertracker/internal/categories"
+	"tendertracker/internal/config"
+	"tendertracker/internal/documents"
+	"tendertracker/internal/jobs"
+	"tendertracker/internal/notify"
+	"tendertracker/internal/reports"
+	"tendertracker/internal/rules"
+	"tendertracker/internal/scheduler"
+	"tendertracker/internal/search"
+	"tendertracker/internal/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(re *regexp.Regexp) *gin.Engine {
+// Services - зависимости обработчиков
+type Services struct {
+	Config     *config.Config
+	Searcher   *search.Searcher
+	Categories categories.List
+	Jobs       *jobs.Manager
+	Reports    *reports.Store
+	Store      *storage.Store
+	Scheduler  *scheduler.Scheduler
+	Email      *notify.Email
+	Webhooks   *notify.Webhooks
+	Rules      *rules.Manager
+	Documents  *documents.Archive
+}
+
+func SetupRouter(s *Services) *gin.Engine {
 	router := gin.Default()
 
-	router.Static("/static", "./static")
-	router.LoadHTMLGlob("templates/*")
+	router.Static("/static", s.Config.Server.StaticDir)
+	router.LoadHTMLGlob(filepath.Join(s.Config.Server.TemplatesDir, "*"))
 
 	tenderGroup := router.Group("/tender")
 	{
 		// HTML страница
 		tenderGroup.GET("/", func(c *gin.Context) {
-			c.HTML(200, "index.html", nil)
+			c.HTML(200, "index.html", gin.H{
+				"Categories":    s.Categories,
+				"SearchTimeout": int(s.Searcher.Timeout.Minutes()),
+			})
 		})
 
 		// API endpoints
@@ -24,16 +52,55 @@ func SetupRouter(re *regexp.Regexp) *gin.Engine {
 			c.JSON(200, gin.H{"status": "OK", "service": "tender"})
 		})
 
-		tenderGroup.POST("/searchTenders", searchTenders(re))
-		tenderGroup.GET("/download", func(c *gin.Context) {
-			filename := c.Query("filename")
-			if filename == "" {
-				filename = "Закупки.xlsx"
-			}
+		tenderGroup.POST("/searchTenders", searchTenders(s.Searcher, s.Categories, s.Jobs))
+		tenderGroup.GET("/searchTenders/stream", streamJob(s.Jobs))
+		tenderGroup.GET("/jobs/:id", getJob(s.Jobs))
+		tenderGroup.POST("/jobs/:id/cancel", cancelJob(s.Jobs))
+		tenderGroup.GET("/download", downloadReport(s.Reports))
+		tenderGroup.GET("/reports", listReports(s.Reports))
 
-			c.File(filename)
-		})
+		// Сохраненные поиски по расписанию
+		tenderGroup.GET("/schedules", listSchedules(s.Store))
+		tenderGroup.POST("/schedules", createSchedule(s.Store, s.Categories))
+		tenderGroup.PUT("/schedules/:id", updateSchedule(s.Store, s.Categories))
+		tenderGroup.DELETE("/schedules/:id", deleteSchedule(s.Store))
+		tenderGroup.POST("/schedules/:id/run", runSchedule(s.Store, s.Scheduler))
+		tenderGroup.GET("/schedules/:id/runs", scheduleRuns(s.Store))
+
+		// Уведомления
+		tenderGroup.POST("/notify/email/test", testEmail(s.Email))
+
+		// Вебхуки
+		tenderGroup.GET("/webhooks", listWebhooks(s.Store))
+		tenderGroup.POST("/webhooks", createWebhook(s.Store))
+		tenderGroup.PUT("/webhooks/:id", updateWebhook(s.Store))
+		tenderGroup.DELETE("/webhooks/:id", deleteWebhook(s.Store))
+		tenderGroup.GET("/webhooks/:id/deliveries", webhookDeliveries(s.Store))
+		tenderGroup.POST("/webhooks/:id/test", testWebhook(s.Store, s.Webhooks))
+
+		// Правила отсева
+		tenderGroup.GET("/rules", getRules(s.Rules))
+		tenderGroup.PUT("/rules", saveRules(s.Rules))
+		tenderGroup.POST("/rules/validate", validateRules(s.Rules))
+		tenderGroup.POST("/rules/dry-run", dryRunRules(s.Store, s.Rules, s.Categories))
+		tenderGroup.GET("/rules/versions", listRulesVersions(s.Store))
+		tenderGroup.GET("/rules/versions/:id", getRulesVersion(s.Store))
+		tenderGroup.POST("/rules/versions/:id/restore", restoreRulesVersion(s.Store, s.Rules))
 
 	}
+
+	// JSON API для скриптов и дашбордов
+	api := router.Group("/api/v1")
+	{
+		api.GET("/tenders", listTenders(s.Store))
+		api.GET("/tenders/*id", getTender(s.Store))
+
+		// Документы закупок
+		api.GET("/documents/*id", getDocuments(s.Documents))
+		api.POST("/documents/*id", downloadDocuments(s.Store, s.Documents, s.Jobs))
+		api.GET("/documents-zip/*id", downloadDocumentsZip(s.Documents))
+		api.GET("/documents-search", searchDocuments(s.Store))
+	}
+
 	return router
 }
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tendertracker/internal/categories"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/rules"
+	"tendertracker/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const rulesVersionsLimit = 50
+
+// rulesInput - текст правил в JSON-запросе
+type rulesInput struct {
+	Text string `json:"text"`
+	// Author - кто меняет правила, по умолчанию адрес клиента
+	Author  string `json:"author"`
+	Comment string `json:"comment"`
+}
+
+func (input rulesInput) author(c *gin.Context) string {
+	if author := strings.TrimSpace(input.Author); author != "" {
+		return author
+	}
+	return c.ClientIP()
+}
+
+// rulesErrors отвечает списком ошибок проверки, по одной на строку файла
+func rulesErrors(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":   "Invalid rules",
+		"details": err.Error(),
+		"errors":  strings.Split(err.Error(), "\n"),
+	})
+}
+
+// getRules возвращает текст файла правил и число действующих правил
+func getRules(manager *rules.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		text, err := manager.Text()
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read rules", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"path":  manager.Path(),
+			"text":  text,
+			"rules": manager.Current().Rules,
+		})
+	}
+}
+
+// validateRules проверяет текст правил без сохранения
+func validateRules(manager *rules.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input rulesInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+			return
+		}
+
+		set, err := manager.Validate(input.Text)
+		if err != nil {
+			rulesErrors(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"valid": true, "rules": len(set.Rules)})
+	}
+}
+
+// saveRules проверяет и сохраняет правила. Новые правила действуют сразу,
+// в том числе в уже запущенных поисках
+func saveRules(manager *rules.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input rulesInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+			return
+		}
+
+		if _, err := manager.Validate(input.Text); err != nil {
+			rulesErrors(c, err)
+			return
+		}
+
+		version, err := manager.Save(input.Text, input.author(c), strings.TrimSpace(input.Comment))
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rules", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"version": version, "rules": len(manager.Current().Rules)})
+	}
+}
+
+// ruleChange - сохраненная закупка, для которой новые правила дают другой итог
+type ruleChange struct {
+	ID     string        `json:"id"`
+	Source string        `json:"source"`
+	Tender models.Tender `json:"tender"`
+	// Reason - правило, которое отсеет закупку (для excluded) или отсеивало ее
+	// до изменения (для allowed)
+	Reason string `json:"reason"`
+}
+
+// categoryChanges - итог пробного прогона по одной категории
+type categoryChanges struct {
+	Category string `json:"category"`
+	Title    string `json:"title"`
+	Checked  int    `json:"checked"`
+	// Excluded - будут отсеяны новыми правилами, Allowed - перестанут отсеиваться
+	Excluded []ruleChange `json:"excluded"`
+	Allowed  []ruleChange `json:"allowed"`
+}
+
+// dryRunRules проверяет правила из запроса на закупках, найденных прошлыми
+// поисками, - и прошедших отсев, и отсеянных правилами, - и возвращает по
+// категориям закупки, итог для которых изменится
+func dryRunRules(store *storage.Store, manager *rules.Manager, list categories.List) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input rulesInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+			return
+		}
+
+		candidate, err := manager.Validate(input.Text)
+		if err != nil {
+			rulesErrors(c, err)
+			return
+		}
+
+		stored, err := store.All()
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read stored tenders", "details": err.Error()})
+			return
+		}
+		rejected, err := store.AllRejected()
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read rejected tenders", "details": err.Error()})
+			return
+		}
+
+		current := manager.Current()
+		byCategory := make(map[string]*categoryChanges)
+		for _, category := range list {
+			byCategory[category.Name] = &categoryChanges{
+				Category: category.Name,
+				Title:    category.Title,
+				Excluded: []ruleChange{},
+				Allowed:  []ruleChange{},
+			}
+		}
+
+		checked := 0
+		seen := make(map[string]bool)
+		check := func(id, source, category string, tender models.Tender) {
+			changes := byCategory[category]
+			// Категорию могли удалить из файла категорий. Закупка, отсеянная
+			// в одном поиске и прошедшая в другом, проверяется один раз
+			key := id + "\x00" + category
+			if changes == nil || seen[key] {
+				return
+			}
+			seen[key] = true
+
+			before := current.Check(category, tender.Title)
+			after := candidate.Check(category, tender.Title)
+			changes.Checked++
+			checked++
+
+			change := ruleChange{ID: id, Source: source, Tender: tender}
+			switch {
+			case after.Excluded && !before.Excluded:
+				change.Reason = after.Reason()
+				changes.Excluded = append(changes.Excluded, change)
+			case before.Excluded && !after.Excluded:
+				change.Reason = before.Reason()
+				changes.Allowed = append(changes.Allowed, change)
+			}
+		}
+
+		for _, tender := range stored {
+			for _, category := range tender.Categories {
+				check(tender.ID, tender.Source, category, tender.Tender)
+			}
+		}
+		for _, tender := range rejected {
+			check(tender.ID, tender.Source, tender.Category, tender.Tender)
+		}
+
+		result := make([]*categoryChanges, 0, len(list))
+		excluded, allowed := 0, 0
+		for _, category := range list {
+			changes := byCategory[category.Name]
+			excluded += len(changes.Excluded)
+			allowed += len(changes.Allowed)
+			result = append(result, changes)
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"rules":      len(candidate.Rules),
+			"checked":    checked,
+			"excluded":   excluded,
+			"allowed":    allowed,
+			"categories": result,
+		})
+	}
+}
+
+func listRulesVersions(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		versions, err := store.RulesVersions(rulesVersionsLimit)
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list rules versions", "details": err.Error()})
+			return
+		}
+
+		if versions == nil {
+			versions = []rules.Version{}
+		}
+		c.JSON(http.StatusOK, gin.H{"versions": versions})
+	}
+}
+
+// findRulesVersion находит версию по :id, при ошибке отвечает сам и возвращает nil
+func findRulesVersion(c *gin.Context, store *storage.Store) *rules.Version {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rules version not found"})
+		return nil
+	}
+
+	version, err := store.GetRulesVersion(id)
+	if err != nil {
+		logger.SugaredLogger.Warnf(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read rules version", "details": err.Error()})
+		return nil
+	}
+	if version == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rules version not found"})
+		return nil
+	}
+
+	return version
+}
+
+func getRulesVersion(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if version := findRulesVersion(c, store); version != nil {
+			c.JSON(http.StatusOK, version)
+		}
+	}
+}
+
+// restoreRulesVersion сохраняет текст прежней версии как новую версию
+func restoreRulesVersion(store *storage.Store, manager *rules.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		previous := findRulesVersion(c, store)
+		if previous == nil {
+			return
+		}
+
+		var input rulesInput
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindJSON(&input); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+				return
+			}
+		}
+
+		// Версия могла стать неверной, например после удаления категории
+		if _, err := manager.Validate(previous.Text); err != nil {
+			rulesErrors(c, err)
+			return
+		}
+
+		comment := "восстановлена версия " + strconv.Itoa(previous.ID)
+		version, err := manager.Save(previous.Text, input.author(c), comment)
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rules", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"version": version, "rules": len(manager.Current().Rules)})
+	}
+}
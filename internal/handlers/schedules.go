@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"tendertracker/internal/categories"
+	"tendertracker/internal/jobs"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/notify"
+	"tendertracker/internal/scheduler"
+	"tendertracker/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// savedSearchInput - сохраненный поиск в JSON-запросе
+type savedSearchInput struct {
+	Name     string        `json:"name"`
+	Schedule string        `json:"schedule"`
+	Enabled  *bool         `json:"enabled"`
+	Config   models.Config `json:"config"`
+	// Emails - получатели дайджеста, AttachReport - прикладывать отчет
+	Emails       []string `json:"emails"`
+	AttachReport bool     `json:"attach_report"`
+}
+
+// bindSavedSearch читает сохраненный поиск из JSON или из формы поиска
+// с дополнительными полями name, schedule, enabled, emails и attach_report
+func bindSavedSearch(c *gin.Context, list categories.List, search *storage.SavedSearch) error {
+	if c.ContentType() == "application/json" {
+		var input savedSearchInput
+		if err := c.ShouldBindJSON(&input); err != nil {
+			return err
+		}
+
+		search.Name = input.Name
+		search.Schedule = input.Schedule
+		search.Config = input.Config
+		search.AttachReport = input.AttachReport
+
+		emails, err := notify.ParseEmails(strings.Join(input.Emails, ","))
+		if err != nil {
+			return err
+		}
+		search.Emails = emails
+
+		if input.Enabled != nil {
+			search.Enabled = *input.Enabled
+		}
+	} else {
+		if err := search.Config.Bind(c, list.Names()); err != nil {
+			return err
+		}
+
+		search.Name = c.PostForm("name")
+		search.Schedule = c.PostForm("schedule")
+		search.AttachReport = c.PostForm("attach_report") == "on" || c.PostForm("attach_report") == "true"
+
+		emails, err := notify.ParseEmails(c.PostForm("emails"))
+		if err != nil {
+			return err
+		}
+		search.Emails = emails
+
+		if enabled, ok := c.GetPostForm("enabled"); ok {
+			search.Enabled = enabled == "on" || enabled == "true"
+		}
+	}
+
+	search.Name = strings.TrimSpace(search.Name)
+	if search.Name == "" {
+		return fmt.Errorf("не указано название поиска")
+	}
+	if _, err := scheduler.ParseSchedule(search.Schedule); err != nil {
+		return err
+	}
+	if len(search.Config.Categories) == 0 {
+		return fmt.Errorf("не выбрано ни одной категории")
+	}
+	for _, name := range search.Config.Categories {
+		if list.Get(name) == nil {
+			return fmt.Errorf("неизвестная категория %q", name)
+		}
+	}
+
+	return nil
+}
+
+// scheduleView - сохраненный поиск со временем следующего запуска
+func scheduleView(search storage.SavedSearch) gin.H {
+	view := gin.H{"search": search}
+
+	if schedule, err := scheduler.ParseSchedule(search.Schedule); err == nil && search.Enabled {
+		from := time.Now()
+		if !search.LastRun.IsZero() && search.LastRun.After(from) {
+			from = search.LastRun
+		}
+		view["next_run"] = schedule.Next(from.In(models.Moscow))
+	}
+
+	return view
+}
+
+func listSchedules(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		searches, err := store.Searches()
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list schedules", "details": err.Error()})
+			return
+		}
+
+		items := make([]gin.H, 0, len(searches))
+		for _, search := range searches {
+			items = append(items, scheduleView(search))
+		}
+
+		c.JSON(http.StatusOK, gin.H{"schedules": items})
+	}
+}
+
+func createSchedule(store *storage.Store, list categories.List) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		search := &storage.SavedSearch{Enabled: true}
+		if err := bindSavedSearch(c, list, search); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+			return
+		}
+
+		if err := store.SaveSearch(search); err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save schedule", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, scheduleView(*search))
+	}
+}
+
+func updateSchedule(store *storage.Store, list categories.List) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		search := findSchedule(c, store)
+		if search == nil {
+			return
+		}
+
+		if err := bindSavedSearch(c, list, search); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+			return
+		}
+
+		if err := store.SaveSearch(search); err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save schedule", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, scheduleView(*search))
+	}
+}
+
+func deleteSchedule(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if findSchedule(c, store) == nil {
+			return
+		}
+
+		if err := store.DeleteSearch(c.Param("id")); err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete schedule", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+	}
+}
+
+// runSchedule запускает сохраненный поиск вне расписания
+func runSchedule(store *storage.Store, sched *scheduler.Scheduler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		search := findSchedule(c, store)
+		if search == nil {
+			return
+		}
+
+		job, err := sched.Run(*search)
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start search", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusAccepted, jobLinks(job))
+	}
+}
+
+// scheduleRuns возвращает историю запусков сохраненного поиска
+func scheduleRuns(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if findSchedule(c, store) == nil {
+			return
+		}
+
+		runs, err := store.Runs(c.Param("id"), 50)
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list runs", "details": err.Error()})
+			return
+		}
+
+		items := make([]gin.H, 0, len(runs))
+		for _, run := range runs {
+			item := gin.H{"run": run}
+			if run.ReportID != "" {
+				item["download_url"] = downloadURL(run.ReportID)
+			}
+			items = append(items, item)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"runs": items})
+	}
+}
+
+func findSchedule(c *gin.Context, store *storage.Store) *storage.SavedSearch {
+	search, err := store.GetSearch(c.Param("id"))
+	if err != nil {
+		logger.SugaredLogger.Warnf(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read schedule", "details": err.Error()})
+		return nil
+	}
+	if search == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+		return nil
+	}
+	return search
+}
+
+// jobLinks - ответ на запуск задачи поиска
+func jobLinks(job *jobs.Job) gin.H {
+	return gin.H{
+		"job_id":     job.ID(),
+		"status_url": "/tender/jobs/" + job.ID(),
+		"stream_url": "/tender/searchTenders/stream?job=" + job.ID(),
+	}
+}
@@ -1,33 +1,28 @@
 package handlers
 
 import (
-	"fmt"
+	"context"
+	"io"
 	"net/http"
-	"regexp"
-	"sync"
+	"time"
 
-	"tendertracker/internal/excel"
+	"tendertracker/internal/categories"
+	"tendertracker/internal/jobs"
 	"tendertracker/internal/logger"
 	"tendertracker/internal/models"
-	"tendertracker/internal/parsergovru"
-	"tendertracker/internal/parsersber"
+	"tendertracker/internal/reports"
+	"tendertracker/internal/search"
 
 	"github.com/gin-gonic/gin"
 )
 
-type parseResult struct {
-	name    string
-	tenders []models.Tender
-	err     error
-}
-
-func searchTenders(re *regexp.Regexp) gin.HandlerFunc {
+// searchTenders запускает поиск в фоне и сразу возвращает идентификатор задачи
+func searchTenders(searcher *search.Searcher, list categories.List, manager *jobs.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		logger.SugaredLogger.Infof("Starting search tenders.")
-		allTenders := &models.TendersFromAllSites{}
 		config := &models.Config{}
 
-		if err := config.Bind(c); err != nil {
+		if err := config.Bind(c, list.Names()); err != nil {
 			logger.SugaredLogger.Warnf(err.Error())
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Invalid input data",
@@ -36,314 +31,103 @@ func searchTenders(re *regexp.Regexp) gin.HandlerFunc {
 			return
 		}
 
-		logger.SugaredLogger.Infof("config: %+v", config)
-
-		var wg sync.WaitGroup
-		var mu sync.Mutex
-
-		var statsSber, statsZakupkiGovRu map[string]int
-		var tendersSber, tendersZakupki models.AllTenders
-		var errors []error
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			logger.SugaredLogger.Info("Starting Zakupki.gov.ru search...")
-
-			tenders, stats, err := SearchFromZakupkigovru(re, config)
-
-			mu.Lock()
-			defer mu.Unlock()
-
-			if err != nil {
-				logger.SugaredLogger.Warnf("Zakupki.gov.ru search error: %v", err)
-				errors = append(errors, err)
-			} else {
-				tendersZakupki = tenders
-				statsZakupkiGovRu = stats
-				logger.SugaredLogger.Infof("Zakupki.gov.ru search completed")
-			}
-		}()
-
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			logger.SugaredLogger.Info("Starting Sber-AST search...")
-
-			tenders, stats, err := SearchFromSber(re, config)
-
-			mu.Lock()
-			defer mu.Unlock()
-
+		job := manager.Start(*config, func(ctx context.Context, job *jobs.Job) (any, error) {
+			result, report, err := searcher.RunReport(ctx, config, job.Report)
 			if err != nil {
-				logger.SugaredLogger.Warnf("Sber-AST search error: %v", err)
-				errors = append(errors, err)
-			} else {
-				tendersSber = tenders
-				statsSber = stats
-				logger.SugaredLogger.Infof("Sber-AST search completed")
+				logger.SugaredLogger.Warnf(err.Error())
+				return nil, err
 			}
-		}()
-
-		wg.Wait()
-
-		allTenders.ZakupkiGovRu = tendersZakupki
-		allTenders.ZakupkiSber = tendersSber
-
-		stats := mergeMaps(statsSber, statsZakupkiGovRu)
-
-		totalZakupki := 0
-		totalSber := 0
-
-		if statsZakupkiGovRu != nil {
-			totalZakupki = statsZakupkiGovRu["totalFoundZakupkiGovRu"]
-		}
-		if statsSber != nil {
-			totalSber = statsSber["totalFoundSber"]
-		}
-
-		stats["totalFound"] = totalZakupki + totalSber
-		stats["totalFoundZakupkiGovRu"] = totalZakupki
-		stats["totalFoundSber"] = totalSber
+			return searchResponse(result, report), nil
+		})
 
-		logger.SugaredLogger.Infof("Search completed. Total found: %d (Zakupki: %d, Sber: %d)",
-			stats["totalFound"], totalZakupki, totalSber)
-
-		file, err := excel.ToExcel(*config, allTenders)
-		if err != nil {
-			logger.SugaredLogger.Warnf(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Failed to create Excel file",
-				"details": err.Error(),
-			})
-			return
-		}
-
-		if err := file.SaveAs("Закупки.xlsx"); err != nil {
-			logger.SugaredLogger.Warnf(err.Error())
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "Failed to save Excel file",
-				"details": err.Error(),
-			})
-			return
-		}
-
-		response := gin.H{
-			"message":  "Excel file created successfully",
-			"stats":    stats,
-			"filename": "Закупки.xlsx",
-		}
-
-		if len(errors) > 0 {
-			response["warnings"] = errors
-		}
-
-		c.JSON(http.StatusOK, response)
+		c.JSON(http.StatusAccepted, jobLinks(job))
 	}
 }
 
-func SearchFromZakupkigovru(re *regexp.Regexp, config *models.Config) (models.AllTenders, map[string]int, error) {
-	var allTenders models.AllTenders
-	stats := map[string]int{
-		"totalFound": 0,
-	}
-
-	resultChan := make(chan parseResult, 4)
-	var wg sync.WaitGroup
-	var errors []string
-
-	if config.SearchVent {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			tenders, err := parsergovru.ParseGovRu("vent", config, re)
-			resultChan <- parseResult{name: "vent", tenders: tenders, err: err}
-		}()
-	}
-
-	if config.SearchDoors {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			tenders, err := parsergovru.ParseGovRu("doors", config, re)
-			resultChan <- parseResult{name: "doors", tenders: tenders, err: err}
-		}()
+// searchResponse - ответ для UI по итогам поиска. Отсеянные закупки сюда не
+// попадают: их может быть несколько тысяч, а ответ отдается при каждом опросе
+// задачи. Их число есть в stats.totalRejected, список - на листе "Отфильтровано"
+func searchResponse(result *search.Result, report *reports.Report) gin.H {
+	response := gin.H{
+		"message":      "Excel file created successfully",
+		"stats":        result.Stats,
+		"sources":      result.Sources,
+		"report_id":    report.ID,
+		"filename":     report.Filename,
+		"download_url": downloadURL(report.ID),
+		"partial":      result.Partial,
 	}
 
-	if config.SearchBuild {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			tenders, err := parsergovru.ParseGovRu("build", config, re)
-			resultChan <- parseResult{name: "build", tenders: tenders, err: err}
-		}()
+	if len(result.Errors) > 0 {
+		response["warnings"] = result.Errors
 	}
 
-	if config.SearchMetal {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			tenders, err := parsergovru.ParseGovRu("metal", config, re)
-			resultChan <- parseResult{name: "metal", tenders: tenders, err: err}
-		}()
-	}
-
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	for result := range resultChan {
-		if result.err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", result.name, result.err))
-			continue
-		}
+	return response
+}
 
-		switch result.name {
-		case "vent":
-			allTenders.Vent = result.tenders
-			stats["ventFoundZakupkiGovRu"] = len(result.tenders)
-			stats["totalFoundZakupkiGovRu"] += len(result.tenders)
-		case "doors":
-			allTenders.Doors = result.tenders
-			stats["doorsFoundZakupkiGovRu"] = len(result.tenders)
-			stats["totalFoundZakupkiGovRu"] += len(result.tenders)
-		case "build":
-			allTenders.Build = result.tenders
-			stats["buildFoundZakupkiGovRu"] = len(result.tenders)
-			stats["totalFoundZakupkiGovRu"] += len(result.tenders)
-		case "metal":
-			allTenders.Metal = result.tenders
-			stats["metalFoundZakupkiGovRu"] = len(result.tenders)
-			stats["totalFoundZakupkiGovRu"] += len(result.tenders)
+// getJob возвращает состояние задачи поиска
+func getJob(manager *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job := manager.Get(c.Param("id"))
+		if job == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
 		}
-	}
-
-	var err error
-
-	if len(errors) > 0 && stats["totalFoundZakupkiGovRu"] == 0 {
-		logger.SugaredLogger.Warnf("All parsing attempts failed from ZakupkiGovRu: %v", errors)
-		return allTenders, stats, err
-	}
 
-	if len(errors) > 0 {
-		logger.SugaredLogger.Warnf("Partial parsing errors (but some tenders found) from ZakupkiGovRu: %v", errors)
-		err = fmt.Errorf("Error when parsing ZakupkiGovRu: %v", errors)
+		c.JSON(http.StatusOK, job.View())
 	}
-
-	if stats["totalFoundZakupkiGovRu"] == 0 {
-		logger.SugaredLogger.Warn("0 tenders found from ZakupkiGovRu")
-		return allTenders, stats, err
-	}
-
-	return allTenders, stats, err
 }
 
-func SearchFromSber(re *regexp.Regexp, config *models.Config) (models.AllTenders, map[string]int, error) {
-	var allTenders models.AllTenders
-	stats := map[string]int{
-		"totalFound": 0,
-	}
-
-	resultChan := make(chan parseResult, 4)
-	var wg sync.WaitGroup
-	var errors []string
-
-	if config.SearchVent {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			tenders, err := parsersber.ParseSberAst("vent", config, re)
-			resultChan <- parseResult{name: "vent", tenders: tenders, err: err}
-		}()
-	}
-
-	if config.SearchDoors {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			tenders, err := parsersber.ParseSberAst("doors", config, re)
-			resultChan <- parseResult{name: "doors", tenders: tenders, err: err}
-		}()
-	}
-
-	if config.SearchBuild {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			tenders, err := parsersber.ParseSberAst("build", config, re)
-			resultChan <- parseResult{name: "build", tenders: tenders, err: err}
-		}()
-	}
-
-	if config.SearchMetal {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			tenders, err := parsersber.ParseSberAst("metal", config, re)
-			resultChan <- parseResult{name: "metal", tenders: tenders, err: err}
-		}()
-	}
-
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	for result := range resultChan {
-		if result.err != nil {
-			errors = append(errors, fmt.Sprintf("%s: %v", result.name, result.err))
-			continue
-		}
-
-		switch result.name {
-		case "vent":
-			allTenders.Vent = result.tenders
-			stats["ventFoundSber"] = len(result.tenders)
-			stats["totalFoundSber"] += len(result.tenders)
-		case "doors":
-			allTenders.Doors = result.tenders
-			stats["doorsFoundSber"] = len(result.tenders)
-			stats["totalFoundSber"] += len(result.tenders)
-		case "build":
-			allTenders.Build = result.tenders
-			stats["buildFoundSber"] = len(result.tenders)
-			stats["totalFoundSber"] += len(result.tenders)
-		case "metal":
-			allTenders.Metal = result.tenders
-			stats["metalFoundSber"] = len(result.tenders)
-			stats["totalFoundSber"] += len(result.tenders)
+// cancelJob останавливает задачу поиска, собранное к этому моменту попадет в отчет
+func cancelJob(manager *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job := manager.Get(c.Param("id"))
+		if job == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
 		}
-	}
 
-	var err error
-
-	if len(errors) > 0 && stats["totalFoundSber"] == 0 {
-		logger.SugaredLogger.Warnf("All parsing attempts failed from Sber: %v", errors)
-		return allTenders, stats, err
+		job.Cancel()
+		c.JSON(http.StatusAccepted, gin.H{"status": "canceling"})
 	}
-
-	if len(errors) > 0 {
-		logger.SugaredLogger.Warnf("Partial parsing errors (but some tenders found) from Sber: %v", errors)
-		err = fmt.Errorf("Error when parsing Sber: %v", errors)
-	}
-
-	if stats["totalFoundSber"] == 0 {
-		logger.SugaredLogger.Warn("0 tenders found from Sber")
-		return allTenders, stats, err
-	}
-
-	return allTenders, stats, err
 }
 
-func mergeMaps(maps ...map[string]int) map[string]int {
-	result := make(map[string]int)
-
-	for _, m := range maps {
-		for key, value := range m {
-			result[key] += value
+// streamJob передает ход задачи поиска через Server-Sent Events.
+// Сначала отправляется снимок задачи (snapshot), затем события площадок
+// (progress) и итоговое состояние (finished)
+func streamJob(manager *jobs.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job := manager.Get(c.Query("job"))
+		if job == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
 		}
-	}
 
-	return result
+		events, unsubscribe := job.Subscribe()
+		defer unsubscribe()
+
+		c.Header("Cache-Control", "no-cache")
+		c.Header("X-Accel-Buffering", "no")
+		c.SSEvent("snapshot", job.View())
+		c.Writer.Flush()
+
+		keepAlive := time.NewTicker(15 * time.Second)
+		defer keepAlive.Stop()
+
+		c.Stream(func(w io.Writer) bool {
+			select {
+			case event := <-events:
+				c.SSEvent("progress", event)
+				return true
+			case <-job.Done():
+				c.SSEvent("finished", job.View())
+				return false
+			case <-keepAlive.C:
+				c.SSEvent("ping", time.Now().Unix())
+				return true
+			case <-c.Request.Context().Done():
+				return false
+			}
+		})
+	}
 }
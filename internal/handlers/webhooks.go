@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/notify"
+	"tendertracker/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webhookInput - вебхук в JSON-запросе. Пустой secret при создании
+// заменяется случайным, при изменении оставляет прежний
+type webhookInput struct {
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	Secret    string   `json:"secret"`
+	Enabled   *bool    `json:"enabled"`
+	SearchIDs []string `json:"search_ids"`
+}
+
+func bindWebhook(c *gin.Context, store *storage.Store, webhook *storage.Webhook) error {
+	var input webhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		return err
+	}
+
+	address, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (address.Scheme != "http" && address.Scheme != "https") || address.Host == "" {
+		return fmt.Errorf("некорректный адрес %q, нужен http:// или https://", input.URL)
+	}
+
+	for _, id := range input.SearchIDs {
+		search, err := store.GetSearch(id)
+		if err != nil {
+			return err
+		}
+		if search == nil {
+			return fmt.Errorf("сохраненный поиск %q не найден", id)
+		}
+	}
+
+	webhook.Name = strings.TrimSpace(input.Name)
+	if webhook.Name == "" {
+		webhook.Name = address.Host
+	}
+	webhook.URL = address.String()
+	webhook.SearchIDs = input.SearchIDs
+
+	if input.Secret != "" {
+		webhook.Secret = input.Secret
+	}
+	if webhook.Secret == "" {
+		secret, err := notify.NewWebhookSecret()
+		if err != nil {
+			return err
+		}
+		webhook.Secret = secret
+	}
+
+	if input.Enabled != nil {
+		webhook.Enabled = *input.Enabled
+	}
+
+	return nil
+}
+
+// webhookView - вебхук без секрета
+func webhookView(webhook storage.Webhook) storage.Webhook {
+	webhook.Secret = ""
+	return webhook
+}
+
+func listWebhooks(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhooks, err := store.Webhooks()
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list webhooks", "details": err.Error()})
+			return
+		}
+
+		items := make([]storage.Webhook, 0, len(webhooks))
+		for _, webhook := range webhooks {
+			items = append(items, webhookView(webhook))
+		}
+
+		c.JSON(http.StatusOK, gin.H{"webhooks": items})
+	}
+}
+
+// createWebhook возвращает секрет подписи только в ответе на создание
+func createWebhook(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhook := &storage.Webhook{Enabled: true}
+		if err := bindWebhook(c, store, webhook); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+			return
+		}
+
+		if err := store.SaveWebhook(webhook); err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save webhook", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusCreated, webhook)
+	}
+}
+
+func updateWebhook(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhook := findWebhook(c, store)
+		if webhook == nil {
+			return
+		}
+
+		if err := bindWebhook(c, store, webhook); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input data", "details": err.Error()})
+			return
+		}
+
+		if err := store.SaveWebhook(webhook); err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save webhook", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, webhookView(*webhook))
+	}
+}
+
+func deleteWebhook(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if findWebhook(c, store) == nil {
+			return
+		}
+
+		if err := store.DeleteWebhook(c.Param("id")); err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+	}
+}
+
+// webhookDeliveries возвращает журнал доставок вебхука
+func webhookDeliveries(store *storage.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if findWebhook(c, store) == nil {
+			return
+		}
+
+		deliveries, err := store.Deliveries(c.Param("id"), 100)
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list deliveries", "details": err.Error()})
+			return
+		}
+		if deliveries == nil {
+			deliveries = []storage.Delivery{}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+	}
+}
+
+// testWebhook отправляет пробное событие ping
+func testWebhook(store *storage.Store, webhooks *notify.Webhooks) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhook := findWebhook(c, store)
+		if webhook == nil {
+			return
+		}
+
+		delivery, err := webhooks.Ping(c.Request.Context(), *webhook)
+		if delivery == nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send webhook", "details": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Webhook delivery failed", "details": err.Error(), "delivery": delivery})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"delivery": delivery})
+	}
+}
+
+func findWebhook(c *gin.Context, store *storage.Store) *storage.Webhook {
+	webhook, err := store.GetWebhook(c.Param("id"))
+	if err != nil {
+		logger.SugaredLogger.Warnf(err.Error())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read webhook", "details": err.Error()})
+		return nil
+	}
+	if webhook == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return nil
+	}
+	return webhook
+}
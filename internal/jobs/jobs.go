@@ -0,0 +1,335 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
+)
+
+// Состояния задачи поиска
+const (
+	StateQueued   = "queued"
+	StateRunning  = "running"
+	StateDone     = "done"
+	StateFailed   = "failed"
+	StateCanceled = "canceled"
+)
+
+// Progress - ход поиска по одной категории на одной площадке
+type Progress struct {
+	Source   string   `json:"source"`
+	Category string   `json:"category"`
+	Pages    int      `json:"pages"`
+	Cards    int      `json:"cards"`
+	Found    int      `json:"found"`
+	Retries  int      `json:"retries"`
+	Done     bool     `json:"done"`
+	Warnings []string `json:"warnings,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// Job - фоновая задача поиска
+type Job struct {
+	mu sync.RWMutex
+
+	id         string
+	state      string
+	config     models.Config
+	createdAt  time.Time
+	startedAt  time.Time
+	finishedAt time.Time
+	progress   map[string]*Progress
+	order      []string
+	result     any
+	err        string
+
+	// subscribers получают события хода поиска, done закрывается по завершении
+	subscribers map[chan sources.Event]struct{}
+	done        chan struct{}
+	cancel      context.CancelFunc
+}
+
+// View - снимок задачи для API
+type View struct {
+	ID         string        `json:"id"`
+	State      string        `json:"state"`
+	Config     models.Config `json:"config"`
+	CreatedAt  time.Time     `json:"created_at"`
+	StartedAt  *time.Time    `json:"started_at,omitempty"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	Progress   []Progress    `json:"progress"`
+	Result     any           `json:"result,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+func (j *Job) ID() string {
+	return j.id
+}
+
+// Report учитывает событие хода поиска. Подходит как sources.Reporter
+func (j *Job) Report(event sources.Event) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	key := event.Source + "/" + event.Category
+	progress, ok := j.progress[key]
+	if !ok {
+		progress = &Progress{Source: event.Source, Category: event.Category}
+		j.progress[key] = progress
+		j.order = append(j.order, key)
+	}
+
+	switch event.Kind {
+	case sources.EventPage:
+		progress.Pages++
+		progress.Cards += event.Cards
+		progress.Found += event.Kept
+	case sources.EventRetry:
+		progress.Retries++
+	case sources.EventWarning:
+		progress.Warnings = append(progress.Warnings, event.Message)
+	case sources.EventDone:
+		progress.Done = true
+		progress.Found = event.Kept
+	case sources.EventError:
+		progress.Done = true
+		progress.Errors = append(progress.Errors, event.Message)
+	}
+
+	for ch := range j.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Медленный получатель пропускает событие, итог он получит из View
+		}
+	}
+}
+
+// Subscribe подписывает на события хода поиска. Возвращаемую функцию
+// нужно вызвать, когда события больше не нужны
+func (j *Job) Subscribe() (<-chan sources.Event, func()) {
+	ch := make(chan sources.Event, 64)
+
+	j.mu.Lock()
+	j.subscribers[ch] = struct{}{}
+	j.mu.Unlock()
+
+	return ch, func() {
+		j.mu.Lock()
+		delete(j.subscribers, ch)
+		j.mu.Unlock()
+	}
+}
+
+// Cancel останавливает поиск. Уже собранные результаты сохраняются
+func (j *Job) Cancel() {
+	j.cancel()
+}
+
+// Done закрывается, когда задача завершена
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// View возвращает снимок состояния задачи
+func (j *Job) View() View {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	view := View{
+		ID:        j.id,
+		State:     j.state,
+		Config:    j.config,
+		CreatedAt: j.createdAt,
+		Progress:  make([]Progress, 0, len(j.order)),
+		Result:    j.result,
+		Error:     j.err,
+	}
+
+	if !j.startedAt.IsZero() {
+		startedAt := j.startedAt
+		view.StartedAt = &startedAt
+	}
+	if !j.finishedAt.IsZero() {
+		finishedAt := j.finishedAt
+		view.FinishedAt = &finishedAt
+	}
+
+	for _, key := range j.order {
+		progress := *j.progress[key]
+		progress.Warnings = append([]string(nil), progress.Warnings...)
+		progress.Errors = append([]string(nil), progress.Errors...)
+		view.Progress = append(view.Progress, progress)
+	}
+
+	return view
+}
+
+func (j *Job) finished() bool {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return j.state == StateDone || j.state == StateFailed || j.state == StateCanceled
+}
+
+// RunFunc выполняет поиск и возвращает результат для API
+type RunFunc func(ctx context.Context, job *Job) (any, error)
+
+// Manager хранит задачи поиска в памяти
+type Manager struct {
+	mu   sync.RWMutex
+	jobs map[string]*Job
+	// retention - сколько хранить завершенные задачи
+	retention time.Duration
+	// ctx отменяется при остановке сервера и отменяет все задачи
+	ctx context.Context
+}
+
+// NewManager создает менеджер задач. Отмена ctx останавливает все задачи
+func NewManager(ctx context.Context, retention time.Duration) *Manager {
+	return &Manager{
+		jobs:      make(map[string]*Job),
+		retention: retention,
+		ctx:       ctx,
+	}
+}
+
+// Start создает задачу и запускает ее в фоне. Задача не зависит от
+// HTTP-запроса, который ее создал, и останавливается через Cancel или
+// при остановке сервера
+func (m *Manager) Start(config models.Config, run RunFunc) *Job {
+	ctx, cancel := context.WithCancel(m.ctx)
+
+	job := &Job{
+		id:        newID(),
+		state:     StateQueued,
+		config:    config,
+		createdAt: time.Now(),
+		progress:  make(map[string]*Progress),
+
+		subscribers: make(map[chan sources.Event]struct{}),
+		done:        make(chan struct{}),
+		cancel:      cancel,
+	}
+
+	m.mu.Lock()
+	m.prune()
+	m.jobs[job.id] = job
+	m.mu.Unlock()
+
+	go m.run(ctx, job, run)
+
+	return job
+}
+
+func (m *Manager) run(ctx context.Context, job *Job, run RunFunc) {
+	defer job.cancel()
+
+	job.mu.Lock()
+	job.state = StateRunning
+	job.startedAt = time.Now()
+	job.mu.Unlock()
+
+	logger.SugaredLogger.Infof("Job %s started", job.id)
+
+	result, err := run(ctx, job)
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	defer close(job.done)
+
+	job.finishedAt = time.Now()
+	job.result = result
+	if err != nil {
+		job.state = StateFailed
+		job.err = err.Error()
+		logger.SugaredLogger.Warnf("Job %s failed: %v", job.id, err)
+		return
+	}
+
+	if ctx.Err() != nil {
+		job.state = StateCanceled
+		logger.SugaredLogger.Infof("Job %s canceled after %v", job.id, job.finishedAt.Sub(job.startedAt))
+		return
+	}
+
+	job.state = StateDone
+	logger.SugaredLogger.Infof("Job %s finished in %v", job.id, job.finishedAt.Sub(job.startedAt))
+}
+
+// Wait ждет завершения запущенных задач, но не дольше, чем живет ctx.
+// Вызывается при остановке сервера до закрытия базы
+func (m *Manager) Wait(ctx context.Context) error {
+	m.mu.RLock()
+	running := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		running = append(running, job)
+	}
+	m.mu.RUnlock()
+
+	for _, job := range running {
+		select {
+		case <-job.Done():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Get возвращает задачу по идентификатору или nil
+func (m *Manager) Get(id string) *Job {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.jobs[id]
+}
+
+// List возвращает задачи, начиная с последних
+func (m *Manager) List() []View {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	views := make([]View, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		views = append(views, job.View())
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].CreatedAt.After(views[j].CreatedAt)
+	})
+
+	return views
+}
+
+// prune удаляет давно завершенные задачи. Вызывается под m.mu
+func (m *Manager) prune() {
+	for id, job := range m.jobs {
+		if !job.finished() {
+			continue
+		}
+
+		job.mu.RLock()
+		expired := time.Since(job.finishedAt) > m.retention
+		job.mu.RUnlock()
+
+		if expired {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+func newID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+)
+
+func TestManagerStopsJobsWithParent(t *testing.T) {
+	logger.InitLogger("error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	manager := NewManager(ctx, time.Hour)
+
+	started := make(chan struct{})
+	job := manager.Start(models.Config{}, func(ctx context.Context, job *Job) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, nil
+	})
+	<-started
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+	if err := manager.Wait(waitCtx); err == nil {
+		t.Fatal("Wait returned before the running job finished")
+	}
+
+	cancel()
+
+	waitCtx, waitCancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer waitCancel()
+	if err := manager.Wait(waitCtx); err != nil {
+		t.Fatalf("Wait after cancel: %v", err)
+	}
+	if state := job.View().State; state != StateCanceled {
+		t.Errorf("state = %q, want %q", state, StateCanceled)
+	}
+}
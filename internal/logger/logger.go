@@ -16,6 +16,12 @@ func Close() {
 }
 
 func InitLogger(level string) {
+	InitLoggerTo(level, os.Stdout)
+}
+
+// InitLoggerTo - InitLogger с выводом в output. Консольные команды пишут
+// журнал в stderr, чтобы он не смешивался с результатом в stdout
+func InitLoggerTo(level string, output *os.File) {
 	// Создаем кастомную конфигурацию для красивого вывода в консоль
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "time",
@@ -47,7 +53,7 @@ func InitLogger(level string) {
 
 	core := zapcore.NewCore(
 		zapcore.NewConsoleEncoder(encoderConfig),
-		zapcore.Lock(os.Stdout),
+		zapcore.Lock(output),
 		zap.NewAtomicLevelAt(logLevel),
 	)
 
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Moscow - часовой пояс площадок. В образах без tzdata используется фиксированный UTC+3
+var Moscow = loadMoscow()
+
+func loadMoscow() *time.Location {
+	location, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		return time.FixedZone("MSK", 3*60*60)
+	}
+	return location
+}
+
+// ParseDate разбирает дату площадки по одному из форматов. Даты без часового
+// пояса считаются московскими
+func ParseDate(value string, layouts ...string) (time.Time, error) {
+	value = strings.TrimSpace(strings.ReplaceAll(value, "\u00A0", " "))
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, Moscow); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("неизвестный формат даты: %q", value)
+}
+
+// ParsePrice разбирает цену вида "1 234 567,89 ₽" в копейки. Разряды делятся
+// пробелами или точками, дробная часть отделяется только запятой. Разбор
+// останавливается на первом символе после числа, так что "(НДС 20%)" и
+// подобные хвосты в цену не попадают
+func ParsePrice(text string) (int64, error) {
+	runes := []rune(text)
+
+	start := 0
+	for start < len(runes) && !isDigit(runes[start]) {
+		start++
+	}
+	if start == len(runes) {
+		return 0, fmt.Errorf("в строке нет цены: %q", text)
+	}
+
+	var digits strings.Builder
+	fraction := -1
+
+	i := start
+loop:
+	for ; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case isDigit(r):
+			if fraction == 2 {
+				return 0, fmt.Errorf("больше двух знаков после запятой: %q", text)
+			}
+			if fraction >= 0 {
+				fraction++
+			}
+			digits.WriteRune(r)
+		case fraction < 0 && isGroupSeparator(r) && i+1 < len(runes) && isDigit(runes[i+1]):
+			// Пробел внутри числа: "1 234"
+		case fraction < 0 && r == '.' && groupFollows(runes, i+1):
+			// Точка - разделитель разрядов: "1.234.567,89"
+		case fraction < 0 && r == ',' && i+1 < len(runes) && isDigit(runes[i+1]):
+			fraction = 0
+		default:
+			break loop
+		}
+	}
+
+	value, err := strconv.ParseInt(digits.String(), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	if fraction < 0 {
+		fraction = 0
+	}
+	for ; fraction < 2; fraction++ {
+		value *= 10
+	}
+
+	return value, nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isGroupSeparator(r rune) bool {
+	return r == ' ' || r == '\u00A0' || r == '\u202F'
+}
+
+// groupFollows проверяет, что с позиции i идут ровно три цифры разряда
+func groupFollows(runes []rune, i int) bool {
+	if i+3 > len(runes) {
+		return false
+	}
+	for _, r := range runes[i : i+3] {
+		if !isDigit(r) {
+			return false
+		}
+	}
+	return i+3 == len(runes) || !isDigit(runes[i+3])
+}
+
+// RublesToKopecks переводит цену из API площадок в копейки
+func RublesToKopecks(amount float64) int64 {
+	if amount <= 0 {
+		return 0
+	}
+	return int64(amount*100 + 0.5)
+}
+
+// PriceText форматирует цену для отчета: "1 234 567,00 ₽"
+func (t Tender) PriceText() string {
+	if t.Price == 0 {
+		return "Не указана"
+	}
+
+	integerPart := strconv.FormatInt(t.Price/100, 10)
+
+	var formatted strings.Builder
+	for i, r := range integerPart {
+		if i > 0 && (len(integerPart)-i)%3 == 0 {
+			formatted.WriteByte(' ')
+		}
+		formatted.WriteRune(r)
+	}
+
+	return fmt.Sprintf("%s,%02d %s", formatted.String(), t.Price%100, currencySymbol(t.Currency))
+}
+
+// Rubles возвращает цену в рублях (для числовых ячеек отчета)
+func (t Tender) Rubles() float64 {
+	return float64(t.Price) / 100
+}
+
+func currencySymbol(currency string) string {
+	switch strings.ToUpper(currency) {
+	case "", "RUB", "RUR", "РУБ", "РУБ.":
+		return "₽"
+	case "USD":
+		return "$"
+	case "EUR":
+		return "€"
+	case "CNY":
+		return "¥"
+	}
+	return currency
+}
+
+// NormalizeLaw приводит название закона площадки к одной из констант Law*
+func NormalizeLaw(text string) string {
+	lower := strings.ToLower(text)
+
+	switch {
+	case strings.Contains(lower, "44"):
+		return Law44
+	case strings.Contains(lower, "223"):
+		return Law223
+	case strings.Contains(lower, "615"):
+		return LawPP615
+	}
+	return LawCommercial
+}
+
+// FormatDate форматирует дату для отчета, пустая дата - пустая строка
+func FormatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(Moscow).Format("02.01.2006")
+}
+
+// SortByPublishDate сортирует тендеры от новых к старым
+func SortByPublishDate(tenders []Tender) {
+	sort.SliceStable(tenders, func(i, j int) bool {
+		return tenders[i].PublishDate.After(tenders[j].PublishDate)
+	})
+}
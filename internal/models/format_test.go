@@ -0,0 +1,41 @@
+package models
+
+import "testing"
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		text string
+		want int64
+	}{
+		{"1 234 567,89 ₽", 123456789},
+		{"1\u00A0234\u00A0567,89\u00A0₽", 123456789},
+		{"1.234.567,89", 123456789},
+		{"100 000,00 ₽ (НДС 20%)", 10000000},
+		{"100 000 ₽ (НДС 20%)", 10000000},
+		{"Начальная цена: 5 000,5 руб.", 500050},
+		{"42", 4200},
+		{"0,00", 0},
+		// Точка без трех цифр после нее - конец числа, а не дробная часть
+		{"12.5 руб", 1200},
+		{"1 500,00 ₽.", 150000},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePrice(tt.text)
+		if err != nil {
+			t.Errorf("ParsePrice(%q): %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePrice(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParsePriceErrors(t *testing.T) {
+	for _, text := range []string{"", "Не указана", "₽", "1 234,567"} {
+		if got, err := ParsePrice(text); err == nil {
+			t.Errorf("ParsePrice(%q) = %d, want error", text, got)
+		}
+	}
+}
@@ -1,131 +1,205 @@
 package models
 
 import (
+	"fmt"
 	"strconv"
 	"tendertracker/internal/logger"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type TendersFromAllSites struct {
-	ZakupkiGovRu AllTenders
-	ZakupkiSber  AllTenders
+	Sites []SiteTenders
+	// Rejected - закупки, отсеянные правилами или по цене
+	Rejected []Rejection
 }
 
-type AllTenders struct {
-	Vent  []Tender
-	Doors []Tender
-	Build []Tender
-	Metal []Tender
+// SiteTenders - результаты поиска с одной площадки
+type SiteTenders struct {
+	Name    string
+	Title   string
+	Tenders AllTenders
 }
 
-type Tender struct {
-	Title       string
-	Customer    string
-	Price       string
-	PublishDate string
-	EndDate     string
-	Link        string
-	Region      string `json:"region"`
-}
+// AllTenders - тендеры по ключам категорий
+type AllTenders map[string][]Tender
 
-type Config struct {
-	SearchVent        bool     `form:"search_vent"`
-	SearchDoors       bool     `form:"search_doors"`
-	SearchBuild       bool     `form:"search_build"`
-	SearchMetal       bool     `form:"search_metal"`
-	MinPriceVent      int      `form:"min_price_vent"`
-	MinPriceDoors     int      `form:"min_price_doors"`
-	MinPriceBuild     int      `form:"min_price_build"`
-	MinPriceMetal     int      `form:"min_price_metal"`
-	VentCustomerPlace []string `form:"vent_customer_place"` // размещение для всех, не только вентиляции
-	ProcurementType   string   `form:"procurement_type"`
+// Законы, по которым проводятся закупки
+const (
+	Law44         = "44-ФЗ"
+	Law223        = "223-ФЗ"
+	LawPP615      = "ПП615"
+	LawCommercial = "commercial"
+)
+
+// Причины отсева закупки
+const (
+	// RejectedByRule - название подошло под правило exclude
+	RejectedByRule = "rule"
+	// RejectedByInclude - название не подошло ни под одно правило include категории
+	RejectedByInclude = "include"
+	// RejectedByMinPrice - цена ниже минимальной для категории
+	RejectedByMinPrice = "min_price"
+)
+
+// Rejection - закупка, отсеянная при поиске, и причина отсева
+type Rejection struct {
+	Source   string `json:"source"`
+	Category string `json:"category"`
+	Reason   string `json:"reason"`
+	// Detail - сработавшее правило или минимальная цена
+	Detail string `json:"detail"`
+	Tender Tender `json:"tender"`
 }
 
-func (c *Config) Bind(ctx *gin.Context) error {
-	// Обрабатываем чекбоксы
-	if ctx.PostForm("search_vent") == "on" || ctx.PostForm("search_vent") == "true" {
-		c.SearchVent = true
-	} else {
-		c.SearchVent = false
+// ReasonTitle - причина отсева для отчета
+func (r Rejection) ReasonTitle() string {
+	switch r.Reason {
+	case RejectedByRule:
+		return "Правило exclude"
+	case RejectedByInclude:
+		return "Нет совпадений с include"
+	case RejectedByMinPrice:
+		return "Цена ниже минимальной"
 	}
+	return r.Reason
+}
 
-	if ctx.PostForm("search_doors") == "on" || ctx.PostForm("search_doors") == "true" {
-		c.SearchDoors = true
-	} else {
-		c.SearchDoors = false
-	}
+type Tender struct {
+	// Number - реестровый номер извещения (номер закупки на площадке)
+	Number   string `json:"number"`
+	Title    string `json:"title"`
+	Customer string `json:"customer"`
+	// Law - 44-ФЗ, 223-ФЗ, ПП615 или commercial
+	Law string `json:"law"`
+	// Method - способ определения поставщика
+	Method   string `json:"method"`
+	Status   string `json:"status"`
+	Currency string `json:"currency"`
+	// Price - начальная цена в копейках, 0 - цена не указана
+	Price       int64     `json:"price_kopecks"`
+	PublishDate time.Time `json:"publish_date"`
+	EndDate     time.Time `json:"end_date"`
+	Link        string    `json:"link"`
+	Region      string    `json:"region"`
+	// Details - сведения из извещения, если поиск шел с дополнением извещений
+	Details *NoticeDetails `json:"details,omitempty"`
+}
 
-	if ctx.PostForm("search_build") == "on" || ctx.PostForm("search_build") == "true" {
-		c.SearchBuild = true
-	} else {
-		c.SearchBuild = false
-	}
+// NoticeDetails - сведения со страницы извещения zakupki.gov.ru
+type NoticeDetails struct {
+	// SubmissionDeadline - окончание подачи заявок с точным временем и часовым поясом
+	SubmissionDeadline time.Time `json:"submission_deadline"`
+	// AuctionDate - дата проведения аукциона или подведения итогов
+	AuctionDate time.Time `json:"auction_date"`
+	// ApplicationSecurity и ContractSecurity - обеспечение заявки и исполнения
+	// контракта в копейках, 0 - не требуется или не указано
+	ApplicationSecurity int64      `json:"application_security_kopecks"`
+	ContractSecurity    int64      `json:"contract_security_kopecks"`
+	DeliveryPlace       string     `json:"delivery_place"`
+	ContactPerson       string     `json:"contact_person"`
+	ContactPhone        string     `json:"contact_phone"`
+	ContactEmail        string     `json:"contact_email"`
+	OKPD2               []string   `json:"okpd2"`
+	Lots                []Lot      `json:"lots"`
+	Documents           []Document `json:"documents"`
+}
 
-	if ctx.PostForm("search_metal") == "on" || ctx.PostForm("search_metal") == "true" {
-		c.SearchMetal = true
-	} else {
-		c.SearchMetal = false
-	}
+// Lot - лот закупки, цена в копейках
+type Lot struct {
+	Number string `json:"number"`
+	Title  string `json:"title"`
+	Price  int64  `json:"price_kopecks"`
+}
 
-	// Обрабатываем инты
-	var err error
-	if c.SearchVent {
-		price := ctx.PostForm("min_price_vent")
+// Document - документ, приложенный к извещению
+type Document struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
 
-		if price == "" {
-			c.MinPriceVent = 0
-		} else {
-			c.MinPriceVent, err = strconv.Atoi(price)
-			if err != nil {
-				logger.SugaredLogger.Warnf(err.Error())
-				c.MinPriceVent = 0
-			}
+type Config struct {
+	// Categories - выбранные категории в порядке файла категорий
+	Categories        []string       `form:"-" json:"categories"`
+	MinPrices         map[string]int `form:"-" json:"min_prices"`
+	VentCustomerPlace []string       `form:"vent_customer_place" json:"customer_place"` // размещение для всех, не только вентиляции
+	ProcurementType   string         `form:"procurement_type" json:"procurement_type"`
+	// TimeoutMinutes - ограничение времени поиска, 0 - значение по умолчанию
+	TimeoutMinutes int `form:"timeout_minutes" json:"timeout_minutes,omitempty"`
+	// ReportRejected - добавить в отчет лист с отсеянными закупками
+	ReportRejected bool `form:"report_rejected" json:"report_rejected,omitempty"`
+	// EnrichNotices - разобрать извещения zakupki.gov.ru целиком, вместе со списком документов
+	EnrichNotices bool `form:"enrich_notices" json:"enrich_notices,omitempty"`
+}
+
+// Enabled возвращает выбранные для поиска категории
+func (c *Config) Enabled() []string {
+	return c.Categories
+}
+
+func (c *Config) IsEnabled(category string) bool {
+	for _, name := range c.Categories {
+		if name == category {
+			return true
 		}
 	}
-	if c.SearchDoors {
-		price := ctx.PostForm("min_price_doors")
+	return false
+}
 
-		if price == "" {
-			c.MinPriceDoors = 0
-		} else {
-			c.MinPriceDoors, err = strconv.Atoi(price)
-			if err != nil {
-				logger.SugaredLogger.Warnf(err.Error())
-				c.MinPriceDoors = 0
-			}
+// MinPrice возвращает минимальную цену для категории
+func (c *Config) MinPrice(category string) int {
+	return c.MinPrices[category]
+}
+
+// Bind читает форму поиска. Для каждой категории ожидаются поля
+// search_<ключ> и min_price_<ключ>
+func (c *Config) Bind(ctx *gin.Context, categories []string) error {
+	c.Categories = nil
+	c.MinPrices = make(map[string]int)
+
+	for _, category := range categories {
+		// Обрабатываем чекбоксы
+		search := ctx.PostForm("search_" + category)
+		if search != "on" && search != "true" {
+			continue
 		}
-	}
-	if c.SearchBuild {
-		price := ctx.PostForm("min_price_build")
 
+		c.Categories = append(c.Categories, category)
+
+		// Обрабатываем инты
+		price := ctx.PostForm("min_price_" + category)
 		if price == "" {
-			c.MinPriceBuild = 0
-		} else {
-			c.MinPriceBuild, err = strconv.Atoi(ctx.PostForm("min_price_build"))
-			if err != nil {
-				logger.SugaredLogger.Warnf(err.Error())
-				c.MinPriceBuild = 0
-			}
+			c.MinPrices[category] = 0
+			continue
 		}
-	}
-	if c.SearchMetal {
-		price := ctx.PostForm("min_price_metal")
 
-		if price == "" {
-			c.MinPriceMetal = 0
-		} else {
-			c.MinPriceMetal, err = strconv.Atoi(ctx.PostForm("min_price_metal"))
-			if err != nil {
-				logger.SugaredLogger.Warnf(err.Error())
-				c.MinPriceMetal = 0
-			}
+		minPrice, err := strconv.Atoi(price)
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			minPrice = 0
 		}
+		c.MinPrices[category] = minPrice
 	}
 
 	// Обрабатываем массивы
 	c.VentCustomerPlace = ctx.PostFormArray("vent_customer_place")
 
 	c.ProcurementType = ctx.PostForm("procurement_type")
+
+	rejected := ctx.PostForm("report_rejected")
+	c.ReportRejected = rejected == "on" || rejected == "true"
+
+	enrich := ctx.PostForm("enrich_notices")
+	c.EnrichNotices = enrich == "on" || enrich == "true"
+
+	c.TimeoutMinutes = 0
+	if timeout := ctx.PostForm("timeout_minutes"); timeout != "" {
+		minutes, err := strconv.Atoi(timeout)
+		if err != nil || minutes < 0 {
+			return fmt.Errorf("некорректное ограничение времени поиска: %q", timeout)
+		}
+		c.TimeoutMinutes = minutes
+	}
 	return nil
 }
@@ -0,0 +1,130 @@
+package notify
+
+import (
+	"bytes"
+	"html/template"
+	"sort"
+
+	"tendertracker/internal/categories"
+	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
+	"tendertracker/internal/storage"
+)
+
+// DigestTender - строка дайджеста
+type DigestTender struct {
+	Title    string
+	Link     string
+	Customer string
+	Region   string
+	Price    string
+	EndDate  string
+	Source   string
+}
+
+// DigestGroup - новые тендеры одной категории
+type DigestGroup struct {
+	Title   string
+	Tenders []DigestTender
+}
+
+// Digest - новые тендеры сохраненного поиска по категориям
+type Digest struct {
+	Search string
+	Total  int
+	Groups []DigestGroup
+}
+
+// NewDigest группирует тендеры по категориям в порядке файла категорий
+func NewDigest(search storage.SavedSearch, tenders []storage.TenderState, list categories.List) Digest {
+	sourceTitles := make(map[string]string)
+	for _, source := range sources.All() {
+		sourceTitles[source.Name()] = source.Title()
+	}
+
+	byCategory := make(map[string][]DigestTender)
+	for _, state := range tenders {
+		tender := models.Tender{Price: state.Price, Currency: state.Currency}
+
+		title := sourceTitles[state.Source]
+		if title == "" {
+			title = state.Source
+		}
+
+		byCategory[state.Category] = append(byCategory[state.Category], DigestTender{
+			Title:    state.Title,
+			Link:     state.Link,
+			Customer: state.Customer,
+			Region:   state.Region,
+			Price:    tender.PriceText(),
+			EndDate:  models.FormatDate(state.EndDate),
+			Source:   title,
+		})
+	}
+
+	digest := Digest{Search: search.Name, Total: len(tenders)}
+
+	names := make([]string, 0, len(byCategory))
+	for name := range byCategory {
+		names = append(names, name)
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return categoryIndex(list, names[i]) < categoryIndex(list, names[j])
+	})
+
+	for _, name := range names {
+		title := name
+		if category := list.Get(name); category != nil {
+			title = category.Title
+		}
+		digest.Groups = append(digest.Groups, DigestGroup{Title: title, Tenders: byCategory[name]})
+	}
+
+	return digest
+}
+
+func categoryIndex(list categories.List, name string) int {
+	for i, category := range list {
+		if category.Name == name {
+			return i
+		}
+	}
+	return len(list)
+}
+
+var digestTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"></head>
+<body style="font-family: Arial, sans-serif; font-size: 14px;">
+<h2>Новые закупки: {{.Search}}</h2>
+<p>Найдено новых закупок: {{.Total}}</p>
+{{range .Groups}}
+<h3>{{.Title}}</h3>
+<table cellpadding="6" cellspacing="0" border="1" style="border-collapse: collapse;">
+<tr style="background: #f0f0f0;">
+<th>Объект закупки</th><th>Заказчик</th><th>Регион</th><th>Начальная цена</th><th>Окончание подачи</th><th>Площадка</th>
+</tr>
+{{range .Tenders}}
+<tr>
+<td>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</td>
+<td>{{.Customer}}</td>
+<td>{{.Region}}</td>
+<td style="white-space: nowrap;">{{.Price}}</td>
+<td>{{.EndDate}}</td>
+<td>{{.Source}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>
+`))
+
+// HTML возвращает дайджест в виде HTML-письма
+func (d Digest) HTML() (string, error) {
+	var buf bytes.Buffer
+	if err := digestTemplate.Execute(&buf, d); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
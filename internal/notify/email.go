@@ -0,0 +1,286 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"tendertracker/internal/categories"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/reports"
+	"tendertracker/internal/storage"
+)
+
+// emailChannel - канал в отметках об отправке
+const emailChannel = "email"
+
+// SMTPConfig - параметры почтового сервера
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	// TLS - подключение сразу по TLS (обычно порт 465). Без него
+	// используется STARTTLS, если сервер его поддерживает
+	TLS bool
+}
+
+// Enabled - почтовый сервер настроен
+func (c SMTPConfig) Enabled() bool {
+	return c.Host != "" && c.From != ""
+}
+
+func (c SMTPConfig) address() string {
+	port := c.Port
+	if port == 0 {
+		port = 25
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(port))
+}
+
+// Email рассылает дайджест новых тендеров получателям сохраненного поиска
+type Email struct {
+	config     SMTPConfig
+	store      *storage.Store
+	reports    *reports.Store
+	categories categories.List
+}
+
+func NewEmail(config SMTPConfig, store *storage.Store, reportStore *reports.Store, list categories.List) *Email {
+	return &Email{
+		config:     config,
+		store:      store,
+		reports:    reportStore,
+		categories: list,
+	}
+}
+
+func (e *Email) Name() string {
+	return emailChannel
+}
+
+// Notify отправляет каждому получателю только тендеры, которые ему еще не отправлялись
+func (e *Email) Notify(ctx context.Context, search storage.SavedSearch, run *storage.SearchRun) error {
+	if len(search.Emails) == 0 || len(run.New) == 0 {
+		return nil
+	}
+
+	var report *attachment
+	if search.AttachReport && run.ReportID != "" {
+		meta, path, err := e.reports.Get(run.ReportID)
+		if err != nil {
+			return fmt.Errorf("отчет %s: %w", run.ReportID, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("ошибка чтения отчета: %w", err)
+		}
+		report = &attachment{
+			filename:    meta.Filename,
+			contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			data:        data,
+		}
+	}
+
+	var errs []error
+	for _, recipient := range search.Emails {
+		tenders, err := e.store.Unsent(emailChannel, recipient, run.New)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(tenders) == 0 {
+			continue
+		}
+
+		digest := NewDigest(search, tenders, e.categories)
+		body, err := digest.HTML()
+		if err != nil {
+			return err
+		}
+
+		subject := fmt.Sprintf("Новые закупки: %s (%d)", search.Name, len(tenders))
+		if err := e.Send(ctx, recipient, subject, body, report); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", recipient, err))
+			continue
+		}
+
+		if err := e.store.MarkSent(emailChannel, recipient, tenders, time.Now()); err != nil {
+			errs = append(errs, err)
+		}
+
+		logger.SugaredLogger.Infof("Дайджест %s отправлен на %s: %d закупок", search.Name, recipient, len(tenders))
+	}
+
+	return errors.Join(errs...)
+}
+
+// attachment - вложение письма
+type attachment struct {
+	filename    string
+	contentType string
+	data        []byte
+}
+
+// Send отправляет HTML-письмо одному получателю
+func (e *Email) Send(ctx context.Context, to, subject, html string, attachment *attachment) error {
+	if !e.config.Enabled() {
+		return fmt.Errorf("почтовый сервер не настроен")
+	}
+
+	message := buildMessage(e.config.From, to, subject, html, attachment)
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", e.config.address())
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к %s: %w", e.config.address(), err)
+	}
+	conn.SetDeadline(time.Now().Add(2 * time.Minute))
+
+	if e.config.TLS {
+		conn = tls.Client(conn, &tls.Config{ServerName: e.config.Host})
+	}
+
+	client, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if !e.config.TLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: e.config.Host}); err != nil {
+				return fmt.Errorf("ошибка STARTTLS: %w", err)
+			}
+		}
+	}
+
+	if e.config.Username != "" {
+		auth := smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("ошибка авторизации: %w", err)
+		}
+	}
+
+	if err := client.Mail(e.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// SendTest отправляет пробное письмо для проверки настроек почты
+func (e *Email) SendTest(ctx context.Context, to string) error {
+	return e.Send(ctx, to, "TenderTracker: проверка почты",
+		"<p>Это пробное письмо. Настройки почты работают.</p>", nil)
+}
+
+// buildMessage собирает MIME-письмо: HTML и необязательное вложение
+func buildMessage(from, to, subject, html string, attachment *attachment) []byte {
+	var buf bytes.Buffer
+	boundary := newBoundary()
+
+	writeHeader := func(name, value string) {
+		buf.WriteString(name + ": " + value + "\r\n")
+	}
+
+	writeHeader("From", from)
+	writeHeader("To", to)
+	writeHeader("Subject", mime.BEncoding.Encode("utf-8", subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("MIME-Version", "1.0")
+
+	if attachment == nil {
+		writeHeader("Content-Type", `text/html; charset="utf-8"`)
+		writeHeader("Content-Transfer-Encoding", "base64")
+		buf.WriteString("\r\n")
+		writeBase64(&buf, []byte(html))
+		return buf.Bytes()
+	}
+
+	writeHeader("Content-Type", `multipart/mixed; boundary="`+boundary+`"`)
+	buf.WriteString("\r\n")
+
+	buf.WriteString("--" + boundary + "\r\n")
+	writeHeader("Content-Type", `text/html; charset="utf-8"`)
+	writeHeader("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+	writeBase64(&buf, []byte(html))
+
+	filename := mime.BEncoding.Encode("utf-8", attachment.filename)
+	buf.WriteString("--" + boundary + "\r\n")
+	writeHeader("Content-Type", attachment.contentType+`; name="`+filename+`"`)
+	writeHeader("Content-Disposition", `attachment; filename="`+filename+`"`)
+	writeHeader("Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+	writeBase64(&buf, attachment.data)
+
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes()
+}
+
+// writeBase64 пишет данные в base64 строками по 76 символов
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+}
+
+func newBoundary() string {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "tendertracker-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return "tendertracker-" + hex.EncodeToString(buf)
+}
+
+// ParseEmails разбирает список адресов через запятую, точку с запятой или пробел
+func ParseEmails(text string) ([]string, error) {
+	var result []string
+
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\t'
+	})
+	for _, field := range fields {
+		address, err := mail.ParseAddress(field)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный адрес %q", field)
+		}
+		result = append(result, address.Address)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,311 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
+	"tendertracker/internal/storage"
+)
+
+// webhookChannel - канал в отметках об отправке
+const webhookChannel = "webhook"
+
+// Заголовки запроса вебхука. Подпись - "sha256=" и HMAC-SHA256 строки
+// "<timestamp>.<тело>" по секрету вебхука, см. Sign
+const (
+	SignatureHeader = "X-TenderTracker-Signature"
+	TimestampHeader = "X-TenderTracker-Timestamp"
+	EventHeader     = "X-TenderTracker-Event"
+	DeliveryHeader  = "X-TenderTracker-Delivery"
+)
+
+// События вебхуков
+const (
+	EventNewTenders = "tenders.new"
+	EventPing       = "ping"
+)
+
+// WebhookTender - тендер в теле вебхука: поля models.Tender, площадка, категория и поиск
+type WebhookTender struct {
+	models.Tender
+	Source   string `json:"source"`
+	Category string `json:"category"`
+	SearchID string `json:"search_id"`
+}
+
+// WebhookPayload - тело запроса вебхука
+type WebhookPayload struct {
+	Event      string          `json:"event"`
+	DeliveryID string          `json:"delivery_id"`
+	SearchID   string          `json:"search_id,omitempty"`
+	SearchName string          `json:"search_name,omitempty"`
+	ReportID   string          `json:"report_id,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Tenders    []WebhookTender `json:"tenders"`
+}
+
+// Webhooks отправляет новые тендеры сохраненных поисков на настроенные адреса
+type Webhooks struct {
+	store  *storage.Store
+	client *http.Client
+
+	// MaxAttempts - число попыток доставки. Backoff - пауза перед второй
+	// попыткой, каждая следующая вдвое длиннее
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+func NewWebhooks(store *storage.Store) *Webhooks {
+	return &Webhooks{
+		store:       store,
+		client:      &http.Client{Timeout: 30 * time.Second},
+		MaxAttempts: 6,
+		Backoff:     10 * time.Second,
+	}
+}
+
+func (w *Webhooks) Name() string {
+	return webhookChannel
+}
+
+// Notify готовит доставку на каждый подходящий вебхук. Отправка с повторами
+// идет в фоне, чтобы не задерживать завершение поиска
+func (w *Webhooks) Notify(ctx context.Context, search storage.SavedSearch, run *storage.SearchRun) error {
+	if len(run.New) == 0 {
+		return nil
+	}
+
+	webhooks, err := w.store.Webhooks()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, webhook := range webhooks {
+		if !webhook.Enabled || (len(webhook.SearchIDs) > 0 && !slices.Contains(webhook.SearchIDs, search.ID)) {
+			continue
+		}
+
+		tenders, err := w.store.Unsent(webhookChannel, webhook.ID, run.New)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(tenders) == 0 {
+			continue
+		}
+
+		delivery := &storage.Delivery{
+			WebhookID: webhook.ID,
+			Event:     EventNewTenders,
+			SearchID:  search.ID,
+			Tenders:   len(tenders),
+			Status:    storage.DeliveryPending,
+		}
+		if err := w.store.SaveDelivery(delivery); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		payload := WebhookPayload{
+			Event:      EventNewTenders,
+			DeliveryID: delivery.ID,
+			SearchID:   search.ID,
+			SearchName: search.Name,
+			ReportID:   run.ReportID,
+			CreatedAt:  delivery.CreatedAt,
+			Tenders:    w.webhookTenders(search.ID, tenders),
+		}
+
+		go func(webhook storage.Webhook) {
+			if err := w.deliver(ctx, webhook, delivery, payload); err != nil {
+				logger.SugaredLogger.Warnf("Вебхук %s: доставка %s не удалась: %v", webhook.Name, delivery.ID, err)
+				return
+			}
+			if err := w.store.MarkSent(webhookChannel, webhook.ID, tenders, time.Now()); err != nil {
+				logger.SugaredLogger.Warnf("Вебхук %s: %v", webhook.Name, err)
+			}
+		}(webhook)
+	}
+
+	return errors.Join(errs...)
+}
+
+// webhookTenders дополняет тендеры запуска полными данными из базы
+func (w *Webhooks) webhookTenders(searchID string, states []storage.TenderState) []WebhookTender {
+	result := make([]WebhookTender, 0, len(states))
+
+	for _, state := range states {
+		tender := models.Tender{
+			Title:    state.Title,
+			Link:     state.Link,
+			Customer: state.Customer,
+			Region:   state.Region,
+			Currency: state.Currency,
+			Price:    state.Price,
+			EndDate:  state.EndDate,
+		}
+		if stored, err := w.store.Get(state.ID); err == nil && stored != nil {
+			tender = stored.Tender
+		}
+
+		result = append(result, WebhookTender{
+			Tender:   tender,
+			Source:   state.Source,
+			Category: state.Category,
+			SearchID: searchID,
+		})
+	}
+
+	return result
+}
+
+// Ping отправляет пробное событие без повторов и возвращает запись журнала
+func (w *Webhooks) Ping(ctx context.Context, webhook storage.Webhook) (*storage.Delivery, error) {
+	delivery := &storage.Delivery{
+		WebhookID: webhook.ID,
+		Event:     EventPing,
+		Status:    storage.DeliveryPending,
+	}
+	if err := w.store.SaveDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	payload := WebhookPayload{
+		Event:      EventPing,
+		DeliveryID: delivery.ID,
+		CreatedAt:  delivery.CreatedAt,
+		Tenders:    []WebhookTender{},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = w.attempt(ctx, webhook, delivery, body)
+	return delivery, err
+}
+
+// deliver отправляет тело с повторами и экспоненциальной паузой.
+// Каждая попытка записывается в журнал доставки
+func (w *Webhooks) deliver(ctx context.Context, webhook storage.Webhook, delivery *storage.Delivery, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	delay := w.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := w.attempt(ctx, webhook, delivery, body)
+		if err == nil || !retry || attempt >= w.MaxAttempts {
+			return err
+		}
+
+		if err := sources.Sleep(ctx, delay); err != nil {
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// attempt выполняет одну попытку и сохраняет ее итог. retry - ошибку
+// имеет смысл повторить: сеть, 408, 429 и 5xx
+func (w *Webhooks) attempt(ctx context.Context, webhook storage.Webhook, delivery *storage.Delivery, body []byte) (retry bool, err error) {
+	started := time.Now()
+	record := storage.DeliveryAttempt{At: started}
+
+	statusCode, err := w.post(ctx, webhook, delivery, body)
+	record.StatusCode = statusCode
+	record.DurationMs = time.Since(started).Milliseconds()
+
+	switch {
+	case err != nil:
+		retry = true
+	case statusCode >= 200 && statusCode < 300:
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests || statusCode >= 500:
+		retry = true
+		err = fmt.Errorf("HTTP %d", statusCode)
+	default:
+		err = fmt.Errorf("HTTP %d", statusCode)
+	}
+
+	delivery.Attempts = append(delivery.Attempts, record)
+	if err != nil {
+		delivery.Attempts[len(delivery.Attempts)-1].Error = err.Error()
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = storage.DeliveryDelivered
+		delivery.FinishedAt = time.Now()
+	case !retry || len(delivery.Attempts) >= w.MaxAttempts || delivery.Event == EventPing:
+		delivery.Status = storage.DeliveryFailed
+		delivery.FinishedAt = time.Now()
+	}
+
+	if saveErr := w.store.SaveDelivery(delivery); saveErr != nil {
+		logger.SugaredLogger.Warnf("Вебхук %s: ошибка записи журнала: %v", webhook.Name, saveErr)
+	}
+
+	return retry, err
+}
+
+func (w *Webhooks) post(ctx context.Context, webhook storage.Webhook, delivery *storage.Delivery, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TenderTracker-Webhook")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	if webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// Sign возвращает подпись тела: "sha256=" и HMAC-SHA256 строки "<timestamp>.<тело>".
+// Получатель считает ее так же и сравнивает с заголовком X-TenderTracker-Signature
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewWebhookSecret создает случайный секрет для подписи
+func NewWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
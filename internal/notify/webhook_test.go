@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/storage"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		secret    string
+		timestamp string
+		body      string
+		want      string
+	}{
+		{"secret", "1700000000", `{"event":"ping"}`, "sha256=4d39bd2442f073b6bc62e95d0297ce25475582a17389ab860abdc778fe1d9f77"},
+	}
+
+	for _, tt := range tests {
+		if got := Sign(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+			t.Errorf("Sign(%q, %q, %q) = %s, want %s", tt.secret, tt.timestamp, tt.body, got, tt.want)
+		}
+	}
+
+	// Подпись зависит и от секрета, и от метки времени, и от тела
+	base := Sign("secret", "1700000000", []byte("body"))
+	for _, other := range []string{
+		Sign("secret2", "1700000000", []byte("body")),
+		Sign("secret", "1700000001", []byte("body")),
+		Sign("secret", "1700000000", []byte("body ")),
+	} {
+		if other == base {
+			t.Errorf("подпись не изменилась: %s", other)
+		}
+	}
+}
+
+func TestPingSignature(t *testing.T) {
+	logger.InitLogger("error")
+
+	store, err := storage.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	webhooks := NewWebhooks(store)
+	delivery, err := webhooks.Ping(context.Background(), storage.Webhook{ID: "w1", Name: "test", URL: server.URL, Secret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != storage.DeliveryDelivered {
+		t.Errorf("статус доставки %s, want %s", delivery.Status, storage.DeliveryDelivered)
+	}
+
+	if header.Get(EventHeader) != EventPing || header.Get(DeliveryHeader) != delivery.ID {
+		t.Errorf("заголовки события: %v", header)
+	}
+	want := Sign("secret", header.Get(TimestampHeader), body)
+	if got := header.Get(SignatureHeader); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Errorf("подпись %s, want %s", got, want)
+	}
+
+	// Без секрета запрос не подписывается
+	if _, err := webhooks.Ping(context.Background(), storage.Webhook{ID: "w2", Name: "test", URL: server.URL}); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get(SignatureHeader); got != "" {
+		t.Errorf("запрос без секрета подписан: %s", got)
+	}
+}
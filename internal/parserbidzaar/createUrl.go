@@ -8,12 +8,21 @@ import (
 	"time"
 )
 
-func CreateUrl(config models.Config, name string, minPrice int) string {
-	encoder := urlgen.NewURLEncoder("https://bidzaar.com/requests/public/buy")
+// CreateUrl строит ссылку на публичную витрину Bidzaar с фильтрами поиска
+// по закупкам, опубликованным после from
+func CreateUrl(config models.Config, tags []string, minPrice int, from time.Time) string {
+	return createUrlWithBase(pageURL, config, tags, from)
+}
+
+// createApiUrl строит запрос к API витрины с теми же фильтрами
+func createApiUrl(config models.Config, tags []string, minPrice int, from time.Time) string {
+	return createUrlWithBase(apiURL, config, tags, from)
+}
 
-	now := time.Now()
-	twoYearsAgo := now.AddDate(-1, 0, 0)
-	dateString := twoYearsAgo.Format("2006-01-02T15:04:05-07:00")
+func createUrlWithBase(baseURL string, config models.Config, tags []string, from time.Time) string {
+	encoder := urlgen.NewURLEncoder(baseURL)
+
+	dateString := from.Format("2006-01-02T15:04:05-07:00")
 
 	url := encoder.
 		AddParam("sorting.key", "publishDate").
@@ -27,7 +36,7 @@ func CreateUrl(config models.Config, name string, minPrice int) string {
 		AddParam("filters[2].value", getStatusFilter(config.ProcurementType)).
 		AddParam("filters[1].operator", "any").
 		AddParam("filters[1].field", "tags").
-		AddParam("filters[1].value", getNameFilter(name)).
+		AddParam("filters[1].value", getTagsFilter(tags)).
 		AddParam("filters[0].operator", "any").
 		AddParam("filters[0].field", "deliveryAddresses.search").
 		AddParam("filters[0].value", getRegionsFromFederalDistricts(config.VentCustomerPlace))
@@ -46,19 +55,9 @@ func getStatusFilter(procType string) string {
 	}
 }
 
-func getNameFilter(name string) string {
-	switch name {
-	case "doors":
-		return "[металлические двери,противопожарные двери,алюминиевые окна и двери,окна и двери пвх,двери,входные двери,межкомнатные двери,монтаж противопожарных дверей,технические двери,стеклянные двери,раздвижные двери,деревянные двери,двери скрытого монтажа,автоматические двери,двери мдф,монтаж окон и дверей,взломостойкие двери,монтаж дверей,бронированные двери]"
-	case "vent":
-		return "[вентиляция и кондиционирование,вентиляция,монтаж систем вентиляции и кондиционирования,обслуживание кондиционеров и систем вентиляции,вентилятор осевой,вентилятор канальный]"
-	case "build":
-		return "[строительство зданий и сооружений,инженерное строительство,строительство ангаров,строительство складов,монолитное строительство,строительство домов,промышленное строительство,строительство жилых и нежилых зданий,капитальный ремонт]"
-	case "metal":
-		return "[изготовление металлоконструкций,изделия из металла,металлопрокат,металлообработка,металлоконструкции для производств,нержавеющий металлопрокат,цветной металлопрокат,металлоизделия на заказ,металлоконструкции для станционных сооружений,механическая обработка металла]"
-	default:
-		return "[]"
-	}
+// getTagsFilter форматирует теги категории в фильтр вида [тег1,тег2]
+func getTagsFilter(tags []string) string {
+	return "[" + strings.Join(tags, ",") + "]"
 }
 
 func getRegionsFromFederalDistricts(districts []string) string {
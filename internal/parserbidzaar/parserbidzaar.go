@@ -1,18 +1,210 @@
 package parserbidzaar
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
+	"tendertracker/internal/urlgen"
+)
+
+// Публичная витрина и API, которым она пользуется, принимают одинаковые фильтры
+const (
+	pageURL = "https://bidzaar.com/requests/public/buy"
+	apiURL  = "https://bidzaar.com/api/requests/public/buy"
 )
 
 type Parser struct {
-	client *http.Client
+	settings sources.Settings
+}
+
+func NewParser(settings sources.Settings) *Parser {
+	return &Parser{settings: settings}
 }
 
-func NewParser() *Parser {
-	return &Parser{
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+// SourceName - ключ площадки в файле категорий
+const SourceName = "Bidzaar"
+
+// Host - хост площадки для ограничений запросов
+const Host = "bidzaar.com"
+
+func ParseBidzaar(ctx context.Context, settings sources.Settings, query sources.Query) ([]models.Tender, error) {
+	category := query.Category
+	tags := category.SearchStrings(SourceName)
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("%s: не заданы теги для %s", category.Name, SourceName)
 	}
+
+	minPrice := query.Config.MinPrice(category.Name)
+	url := createApiUrl(*query.Config, tags, minPrice, settings.LookBackFrom(time.Now()))
+	return NewParser(settings).ParseAllPages(ctx, category.Name, url, query, minPrice)
+}
+
+func (p *Parser) ParseAllPages(ctx context.Context, name, baseURL string, query sources.Query, minPrice int) ([]models.Tender, error) {
+	var allTenders []models.Tender
+	pageSize := 50
+	maxPages := p.settings.MaxPages
+
+	for page := 1; ; page++ {
+		skip := (page - 1) * pageSize
+		url := urlgen.ReplaceURLParam(urlgen.ReplaceURLParam(baseURL, "skip", strconv.Itoa(skip)), "take", strconv.Itoa(pageSize))
+
+		logger.SugaredLogger.Infof("%s: Bidzaar: Парсинг страницы %d (skip: %d, take: %d)...", name, page, skip, pageSize)
+		logger.SugaredLogger.Debug(url)
+
+		tenders, items, total, err := p.ParsePage(ctx, name, url, query, minPrice)
+		if err != nil {
+			return allTenders, fmt.Errorf("%s: ошибка на странице %d: %w", name, page, err)
+		}
+
+		allTenders = append(allTenders, tenders...)
+
+		logger.SugaredLogger.Infof("%s: Bidzaar: Страница %d: найдено %d карточек, распарсено %d, всего: %d",
+			name, page, items, len(tenders), len(allTenders))
+		query.Report(SourceName, sources.Event{Kind: sources.EventPage, Page: page, Cards: items, Kept: len(tenders)})
+
+		if items < pageSize || skip+items >= total {
+			logger.SugaredLogger.Infof("%s: Bidzaar: Достигнут конец данных", name)
+			break
+		}
+
+		if maxPages > 0 && page >= maxPages {
+			logger.SugaredLogger.Infof("%s: Bidzaar: Достигнут лимит в %d страниц", name, maxPages)
+			break
+		}
+
+		if err := sources.Sleep(ctx, p.settings.PageDelay); err != nil {
+			return allTenders, err
+		}
+	}
+
+	logger.SugaredLogger.Infof("%s: Bidzaar: Завершено. Всего собрано тендеров: %d", name, len(allTenders))
+	return allTenders, nil
+}
+
+// ParsePage возвращает отобранные тендеры, число карточек на странице и общее число найденных
+func (p *Parser) ParsePage(ctx context.Context, name, url string, query sources.Query, minPrice int) ([]models.Tender, int, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Accept", "application/json, text/plain, */*")
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+
+	resp, err := p.settings.Fetcher.Do(req, query.RetryReporter(SourceName, name))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, 0, 0, fmt.Errorf("статус код ошибки: %d %s, тело ответа: %s", resp.StatusCode, resp.Status, string(bodyBytes))
+	}
+
+	var apiResponse SearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
+		return nil, 0, 0, fmt.Errorf("ошибка декодирования JSON: %w", err)
+	}
+
+	var tenders []models.Tender
+	for _, item := range apiResponse.Items {
+		tender := p.parseRequest(name, item, query, minPrice)
+		if tender.Title != "" {
+			tenders = append(tenders, tender)
+		}
+	}
+
+	return tenders, len(apiResponse.Items), apiResponse.Total, nil
+}
+
+func (p *Parser) parseRequest(name string, item Request, query sources.Query, minPrice int) models.Tender {
+	var tender models.Tender
+
+	tender.Title = strings.TrimSpace(item.Name)
+
+	tender.Price = models.RublesToKopecks(item.Budget)
+	tender.Number = item.Number
+	tender.Law = models.LawCommercial
+	tender.Currency = item.Currency
+	tender.Status = statusNames[item.Status]
+	tender.Customer = strings.TrimSpace(item.Company.Name)
+	tender.PublishDate = parseDate(name, item.PublishDate)
+	tender.EndDate = parseDate(name, item.EndDate)
+
+	var places []string
+	for _, address := range item.DeliveryAddresses {
+		place := address.Address
+		if place == "" {
+			place = address.Search
+		}
+		if place = strings.TrimSpace(place); place != "" {
+			places = append(places, place)
+		}
+	}
+	tender.Region = strings.Join(places, "; ")
+
+	if item.ID != "" {
+		tender.Link = pageURL + "/" + item.ID
+	}
+
+	// Бюджет в коммерческих закупках часто скрыт - такие PassesMinPrice не отсекает
+	if !query.Passes(SourceName, tender) || !query.PassesMinPrice(SourceName, tender, minPrice) {
+		return models.Tender{}
+	}
+
+	return tender
+}
+
+// Статусы закупок, совпадают с фильтром getStatusFilter
+var statusNames = map[int]string{
+	1: "Прием предложений",
+	3: "Завершена",
+}
+
+func parseDate(name, value string) time.Time {
+	date, err := models.ParseDate(value, time.RFC3339, "2006-01-02T15:04:05")
+	if err != nil {
+		logger.SugaredLogger.Warnf("%s: %v", name, err)
+	}
+	return date
+}
+
+type SearchResponse struct {
+	Items []Request `json:"items"`
+	Total int       `json:"total"`
+}
+
+type Request struct {
+	ID                string            `json:"id"`
+	Number            string            `json:"number"`
+	Name              string            `json:"name"`
+	Budget            float64           `json:"budget"`
+	Currency          string            `json:"currency"`
+	PublishDate       string            `json:"publishDate"`
+	EndDate           string            `json:"endDate"`
+	Status            int               `json:"status"`
+	Tags              []string          `json:"tags"`
+	Company           Company           `json:"company"`
+	DeliveryAddresses []DeliveryAddress `json:"deliveryAddresses"`
+}
+
+type Company struct {
+	Name string `json:"name"`
+	Inn  string `json:"inn"`
+}
+
+type DeliveryAddress struct {
+	Address string `json:"address"`
+	Search  string `json:"search"`
 }
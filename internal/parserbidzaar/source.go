@@ -0,0 +1,29 @@
+package parserbidzaar
+
+import (
+	"context"
+
+	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
+)
+
+// Source - площадка коммерческих закупок Bidzaar
+type Source struct {
+	settings sources.Settings
+}
+
+func NewSource(settings sources.Settings) *Source {
+	return &Source{settings: settings.WithFetcher(Host)}
+}
+
+func (s *Source) Name() string {
+	return SourceName
+}
+
+func (s *Source) Title() string {
+	return "Bidzaar"
+}
+
+func (s *Source) Search(ctx context.Context, query sources.Query) ([]models.Tender, error) {
+	return ParseBidzaar(ctx, s.settings, query)
+}
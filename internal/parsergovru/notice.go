@@ -0,0 +1,280 @@
+package parsergovru
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"tendertracker/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// noticeField - заголовок и значение одного поля извещения
+type noticeField struct {
+	title string
+	value string
+}
+
+// Названия полей отличаются у 44-ФЗ и 223-ФЗ, поэтому для каждого сведения
+// перечислены варианты. Поле ищется по вхождению без учета регистра
+var (
+	deadlineTitles      = []string{"окончания срока подачи заявок", "окончания подачи заявок"}
+	auctionTitles       = []string{"дата проведения аукциона", "дата проведения процедуры", "дата подведения итогов"}
+	applicationTitles   = []string{"размер обеспечения заявки", "обеспечение заявки"}
+	contractTitles      = []string{"размер обеспечения исполнения контракта", "размер обеспечения исполнения договора", "обеспечение исполнения"}
+	deliveryTitles      = []string{"место поставки", "место выполнения работ", "место оказания услуг"}
+	contactPersonTitles = []string{"ответственное должностное лицо", "контактное лицо"}
+	contactPhoneTitles  = []string{"номер контактного телефона", "контактный телефон", "телефон"}
+	contactEmailTitles  = []string{"адрес электронной почты", "электронная почта"}
+)
+
+var (
+	// dateTimeZone - "25.10.2026 09:00 (МСК+4)", время и пояс могут отсутствовать
+	dateTimeZone = regexp.MustCompile(`(\d{2}\.\d{2}\.\d{4})(?:\s+(\d{1,2}:\d{2}))?(?:\s*\(МСК\s*([+-]\s*\d+)?\))?`)
+	// money - сумма с валютой, "12 345,67 ₽" или "12 345,67 Российский рубль"
+	money = regexp.MustCompile(`\d[\d\s\x{00A0}]*(?:[.,]\d{1,2})?\s*(?:₽|руб|Российский рубль)`)
+	// okpd2 - код ОКПД2, например 43.22.12.140
+	okpd2  = regexp.MustCompile(`\b\d{2}\.\d{2}(?:\.\d{1,3}){0,3}\b`)
+	spaces = regexp.MustCompile(`\s+`)
+)
+
+// parseNotice разбирает общую информацию извещения
+func parseNotice(doc *goquery.Document) notice {
+	fields := noticeFields(doc)
+
+	details := models.NoticeDetails{
+		SubmissionDeadline:  parseDateTimeZone(findField(fields, deadlineTitles...)),
+		AuctionDate:         parseDateTimeZone(findField(fields, auctionTitles...)),
+		ApplicationSecurity: parseSecurity(findField(fields, applicationTitles...)),
+		ContractSecurity:    parseSecurity(findField(fields, contractTitles...)),
+		DeliveryPlace:       findField(fields, deliveryTitles...),
+		ContactPerson:       findField(fields, contactPersonTitles...),
+		ContactPhone:        findField(fields, contactPhoneTitles...),
+		ContactEmail:        findField(fields, contactEmailTitles...),
+		OKPD2:               parseOKPD2(doc, fields),
+		Lots:                parseLots(doc),
+	}
+
+	return notice{
+		Version: noticeVersion,
+		Place:   parsePlace(doc),
+		Details: details,
+	}
+}
+
+// noticeFields собирает поля разделов извещения в порядке страницы
+func noticeFields(doc *goquery.Document) []noticeField {
+	var fields []noticeField
+
+	doc.Find(".blockInfo__section").Each(func(i int, s *goquery.Selection) {
+		title := cleanText(s.Find(".section__title").First().Text())
+		value := cleanText(s.Find(".section__info").First().Text())
+		if title != "" && value != "" {
+			fields = append(fields, noticeField{title: title, value: value})
+		}
+	})
+
+	return fields
+}
+
+// findField возвращает значение первого поля, заголовок которого содержит один из вариантов
+func findField(fields []noticeField, titles ...string) string {
+	for _, title := range titles {
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field.title), title) {
+				return field.value
+			}
+		}
+	}
+	return ""
+}
+
+// parseDateTimeZone разбирает дату со временем и часовым поясом площадки.
+// Пояс "МСК+N" переводится в UTC+3+N, без пояса время считается московским
+func parseDateTimeZone(value string) time.Time {
+	match := dateTimeZone.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}
+	}
+
+	location := models.Moscow
+	if offset := strings.ReplaceAll(match[3], " ", ""); offset != "" {
+		hours, err := strconv.Atoi(offset)
+		if err == nil {
+			location = time.FixedZone("МСК"+offset, (3+hours)*60*60)
+		}
+	}
+
+	layout, text := "02.01.2006", match[1]
+	if match[2] != "" {
+		layout, text = "02.01.2006 15:04", match[1]+" "+match[2]
+	}
+
+	parsed, err := time.ParseInLocation(layout, text, location)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+// parseSecurity находит сумму обеспечения в копейках. Обеспечение в процентах
+// без суммы и "не требуется" дают 0
+func parseSecurity(value string) int64 {
+	amount := money.FindString(value)
+	if amount == "" {
+		return 0
+	}
+
+	price, err := models.ParsePrice(amount)
+	if err != nil {
+		return 0
+	}
+	return price
+}
+
+// parseOKPD2 собирает коды ОКПД2 из таблицы объектов закупки и полей извещения
+func parseOKPD2(doc *goquery.Document, fields []noticeField) []string {
+	var codes []string
+	seen := make(map[string]bool)
+	add := func(text string) {
+		for _, code := range okpd2.FindAllString(text, -1) {
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	doc.Find("table").Each(func(i int, table *goquery.Selection) {
+		column := tableColumn(table, func(header string) bool {
+			return strings.Contains(header, "окпд2")
+		})
+		if column < 0 {
+			return
+		}
+		tableRows(table, func(cells []string) {
+			if column < len(cells) {
+				add(cells[column])
+			}
+		})
+	})
+
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field.title), "окпд2") {
+			add(field.value)
+		}
+	}
+
+	return codes
+}
+
+// parseLots разбирает таблицу лотов, если она есть (в извещениях 44-ФЗ лот один и таблицы нет)
+func parseLots(doc *goquery.Document) []models.Lot {
+	var lots []models.Lot
+
+	doc.Find("table").Each(func(i int, table *goquery.Selection) {
+		number := tableColumn(table, func(header string) bool {
+			return strings.Contains(header, "лот") && (strings.Contains(header, "№") || strings.Contains(header, "номер"))
+		})
+		if number < 0 {
+			return
+		}
+		title := tableColumn(table, func(header string) bool {
+			return strings.Contains(header, "наименование") || strings.Contains(header, "предмет")
+		})
+		price := tableColumn(table, func(header string) bool {
+			return strings.Contains(header, "цена")
+		})
+
+		tableRows(table, func(cells []string) {
+			if number >= len(cells) || cells[number] == "" {
+				return
+			}
+
+			lot := models.Lot{Number: cells[number]}
+			if title >= 0 && title < len(cells) {
+				lot.Title = cells[title]
+			}
+			if price >= 0 && price < len(cells) {
+				lot.Price, _ = models.ParsePrice(cells[price])
+			}
+			lots = append(lots, lot)
+		})
+	})
+
+	return lots
+}
+
+// tableColumn возвращает номер столбца, заголовок которого подходит под match, или -1
+func tableColumn(table *goquery.Selection, match func(header string) bool) int {
+	column := -1
+	table.Find("tr").First().Find("th, td").EachWithBreak(func(i int, cell *goquery.Selection) bool {
+		if match(strings.ToLower(cleanText(cell.Text()))) {
+			column = i
+			return false
+		}
+		return true
+	})
+	return column
+}
+
+// tableRows передает в handle ячейки строк таблицы без строки заголовков
+func tableRows(table *goquery.Selection, handle func(cells []string)) {
+	table.Find("tr").Slice(1, goquery.ToEnd).Each(func(i int, row *goquery.Selection) {
+		var cells []string
+		row.Children().Each(func(j int, cell *goquery.Selection) {
+			cells = append(cells, cleanText(cell.Text()))
+		})
+		handle(cells)
+	})
+}
+
+// fetchDocuments загружает список документов со страницы документов извещения
+func (n *notices) fetchDocuments(ctx context.Context, link string) ([]models.Document, error) {
+	documentsURL := strings.Replace(link, "common-info.html", "documents.html", 1)
+	if documentsURL == link {
+		return []models.Document{}, nil
+	}
+
+	doc, err := n.fetch(ctx, documentsURL)
+	if err != nil {
+		return nil, err
+	}
+	return parseDocuments(doc, documentsURL), nil
+}
+
+// parseDocuments собирает ссылки на файлы извещения
+func parseDocuments(doc *goquery.Document, pageURL string) []models.Document {
+	documents := []models.Document{}
+	base, _ := url.Parse(pageURL)
+	seen := make(map[string]bool)
+
+	doc.Find(`a[href*="download"], a[href*="filestore"]`).Each(func(i int, a *goquery.Selection) {
+		href, _ := a.Attr("href")
+		if base != nil {
+			if ref, err := url.Parse(href); err == nil {
+				href = base.ResolveReference(ref).String()
+			}
+		}
+		if href == "" || seen[href] {
+			return
+		}
+		seen[href] = true
+
+		name, _ := a.Attr("title")
+		if name = cleanText(name); name == "" {
+			name = cleanText(a.Text())
+		}
+		documents = append(documents, models.Document{Name: name, URL: href})
+	})
+
+	return documents
+}
+
+func cleanText(text string) string {
+	return strings.TrimSpace(spaces.ReplaceAllString(strings.ReplaceAll(text, "\u00A0", " "), " "))
+}
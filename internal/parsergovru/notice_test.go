@@ -0,0 +1,60 @@
+package parsergovru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDateTimeZone(t *testing.T) {
+	utc := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"25.10.2026 09:00 (МСК)", utc("2026-10-25 06:00")},
+		{"25.10.2026 09:00 (МСК+4)", utc("2026-10-25 02:00")},
+		{"25.10.2026 09:00 (МСК + 4)", utc("2026-10-25 02:00")},
+		{"25.10.2026 09:00 (МСК-1)", utc("2026-10-25 07:00")},
+		// Без пояса время московское
+		{"25.10.2026 9:00", utc("2026-10-25 06:00")},
+		{"25.10.2026", utc("2026-10-24 21:00")},
+		{"Дата и время окончания срока подачи заявок 25.10.2026 09:00 (МСК+7)", utc("2026-10-24 23:00")},
+		{"", time.Time{}},
+		{"не указано", time.Time{}},
+		{"32.13.2026 09:00", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		if got := parseDateTimeZone(tt.value); !got.Equal(tt.want) {
+			t.Errorf("parseDateTimeZone(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseSecurity(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"15 000,00 ₽", 1500000},
+		{"15 000,50 Российский рубль", 1500050},
+		{"1 234 567,89 руб.", 123456789},
+		{"0.5% от НМЦК, 7 500,00 ₽", 750000},
+		{"5 %", 0},
+		{"Не требуется", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseSecurity(tt.value); got != tt.want {
+			t.Errorf("parseSecurity(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package parsergovru
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// maxKnownNotices - сколько разобранных извещений держать в памяти. Остальные
+// берутся из settings.Cache
+const maxKnownNotices = 2000
+
+// noticeVersion меняется вместе с составом notice: записи кэша другой версии
+// загружаются заново
+const noticeVersion = 2
+
+// notice - то, что берется со страницы извещения
+type notice struct {
+	Version int                  `json:"version"`
+	Place   string               `json:"place"`
+	Details models.NoticeDetails `json:"details"`
+	// HasDocuments - список документов уже загружен, для него нужен отдельный запрос
+	HasDocuments bool `json:"has_documents"`
+}
+
+type cachedNotice struct {
+	notice  notice
+	expires time.Time
+}
+
+// pendingNotice - извещение, которое уже загружается для другой карточки
+type pendingNotice struct {
+	done   chan struct{}
+	notice notice
+	err    error
+}
+
+// notices загружает извещения ограниченным числом обработчиков. Разобранные
+// извещения хранятся по ссылке в памяти и в settings.Cache, поэтому одна и та же
+// закупка из разных категорий и поисков открывается один раз за срок хранения
+type notices struct {
+	settings sources.Settings
+
+	mu      sync.Mutex
+	known   map[string]cachedNotice
+	pending map[string]*pendingNotice
+}
+
+func newNotices(settings sources.Settings) *notices {
+	return &notices{
+		settings: settings,
+		known:    make(map[string]cachedNotice),
+		pending:  make(map[string]*pendingNotice),
+	}
+}
+
+// fill дополняет тендеры адресами заказчиков из извещений, а с enrich - всеми
+// сведениями извещения и списком документов. Ошибка загрузки извещения
+// оставляет тендер без дополнения
+func (n *notices) fill(ctx context.Context, name string, tenders []models.Tender, enrich bool) {
+	workers := min(max(n.settings.DetailWorkers, 1), len(tenders))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				notice, err := n.get(ctx, tenders[i].Link, enrich)
+				if err != nil {
+					logger.SugaredLogger.Warnf("%s: не удалось загрузить извещение %s: %v", name, tenders[i].Link, err)
+					continue
+				}
+				tenders[i].Region = notice.Place
+				if enrich {
+					details := notice.Details
+					tenders[i].Details = &details
+				}
+			}
+		}()
+	}
+
+send:
+	for i := range tenders {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// get возвращает разобранное извещение, с documents - вместе со списком
+// документов. Одновременные запросы одного извещения загружают его страницу
+// один раз: запрос с документами, пришедший во время обычной загрузки, ждет
+// ее и загружает только список документов
+func (n *notices) get(ctx context.Context, link string, documents bool) (notice, error) {
+	if link == "" {
+		return notice{}, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return notice{}, err
+	}
+
+	// base - извещение без документов, загруженное другим запросом
+	var base *notice
+	for {
+		n.mu.Lock()
+		if cached, ok := n.cached(link); ok && (!documents || cached.HasDocuments) {
+			n.mu.Unlock()
+			return cached, nil
+		}
+		pending, ok := n.pending[link]
+		if !ok {
+			break
+		}
+		n.mu.Unlock()
+
+		select {
+		case <-pending.done:
+		case <-ctx.Done():
+			return notice{}, ctx.Err()
+		}
+		if pending.err != nil || !documents || pending.notice.HasDocuments {
+			return pending.notice, pending.err
+		}
+		loaded := pending.notice
+		base = &loaded
+	}
+	pending := &pendingNotice{done: make(chan struct{})}
+	n.pending[link] = pending
+	n.mu.Unlock()
+
+	pending.notice, pending.err = n.load(ctx, link, documents, base)
+
+	n.mu.Lock()
+	delete(n.pending, link)
+	if pending.err == nil {
+		n.remember(link, pending.notice)
+	}
+	n.mu.Unlock()
+	close(pending.done)
+
+	return pending.notice, pending.err
+}
+
+// cached возвращает извещение из памяти, устаревшее удаляет. Вызывается под n.mu
+func (n *notices) cached(link string) (notice, bool) {
+	cached, ok := n.known[link]
+	if !ok {
+		return notice{}, false
+	}
+	if !time.Now().Before(cached.expires) {
+		delete(n.known, link)
+		return notice{}, false
+	}
+	return cached.notice, true
+}
+
+// remember сохраняет извещение в памяти. Когда места нет, сначала удаляются
+// устаревшие записи, затем те, что устареют раньше других. Вызывается под n.mu
+func (n *notices) remember(link string, loaded notice) {
+	ttl := n.settings.DetailCacheTTL
+	if ttl <= 0 {
+		return
+	}
+
+	now := time.Now()
+	if _, ok := n.known[link]; !ok && len(n.known) >= maxKnownNotices {
+		for key, cached := range n.known {
+			if !now.Before(cached.expires) {
+				delete(n.known, key)
+			}
+		}
+		for len(n.known) >= maxKnownNotices {
+			var oldest string
+			for key, cached := range n.known {
+				if oldest == "" || cached.expires.Before(n.known[oldest].expires) {
+					oldest = key
+				}
+			}
+			delete(n.known, oldest)
+		}
+	}
+
+	n.known[link] = cachedNotice{notice: loaded, expires: now.Add(ttl)}
+}
+
+// load берет извещение из base, постоянного кэша или загружает и разбирает
+// страницу. Список документов загружается отдельным запросом, только когда он нужен
+func (n *notices) load(ctx context.Context, link string, documents bool, base *notice) (notice, error) {
+	cache := n.settings.Cache
+	ttl := n.settings.DetailCacheTTL
+	key := SourceName + ":notice:" + link
+
+	var result notice
+	found := false
+	if base != nil {
+		result, found = *base, true
+	} else if cache != nil && ttl > 0 {
+		var err error
+		found, err = cache.CacheGet(key, &result)
+		if err != nil {
+			logger.SugaredLogger.Warnf("Ошибка чтения кэша извещений: %v", err)
+		}
+		found = found && err == nil && result.Version == noticeVersion
+	}
+
+	changed := false
+	if !found {
+		doc, err := n.fetch(ctx, link)
+		if err != nil {
+			return notice{}, err
+		}
+		result = parseNotice(doc)
+		changed = true
+	}
+
+	if documents && !result.HasDocuments {
+		list, err := n.fetchDocuments(ctx, link)
+		if err != nil {
+			// Сведения извещения уже есть, без документов они тоже полезны
+			logger.SugaredLogger.Warnf("Не удалось загрузить документы извещения %s: %v", link, err)
+		} else {
+			result.Details.Documents = list
+			result.HasDocuments = true
+			changed = true
+		}
+	}
+
+	if changed && cache != nil && ttl > 0 {
+		if err := cache.CachePut(key, result, ttl); err != nil {
+			logger.SugaredLogger.Warnf("Ошибка записи кэша извещений: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// fetch загружает и разбирает страницу извещения. Ответ закрыт до возврата,
+// поэтому извещение и его документы в load занимают место хоста по очереди
+func (n *notices) fetch(ctx context.Context, link string) (*goquery.Document, error) {
+	req, err := newRequest(ctx, link)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	return fetchDocument(n.settings.Fetcher, req, nil)
+}
+
+// parsePlace находит место нахождения заказчика
+func parsePlace(doc *goquery.Document) string {
+	place := doc.Find(".blockInfo__section .section__info").FilterFunction(func(i int, s *goquery.Selection) bool {
+		// Проверяем, что предыдущий элемент содержит заголовок "Место нахождения"
+		title := s.Prev().Find(".section__title").Text()
+		return strings.Contains(title, "Место нахождения")
+	}).First()
+
+	if place.Length() == 0 {
+		// Альтернативный поиск, если структура немного отличается
+		place = doc.Find("section:contains('Место нахождения') .section__info").First()
+	}
+
+	if place.Length() > 0 {
+		return strings.TrimSpace(place.Text())
+	}
+
+	return ""
+}
@@ -0,0 +1,146 @@
+package parsergovru
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tendertracker/internal/fetcher"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/sources"
+)
+
+// Запрос с документами во время обычной загрузки того же извещения не
+// открывает страницу повторно, а загружает только список документов
+func TestGetSharesPageLoad(t *testing.T) {
+	logger.InitLogger("error")
+
+	var (
+		mu      sync.Mutex
+		hits    = make(map[string]int)
+		started = make(chan struct{})
+		release = make(chan struct{})
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		first := r.URL.Path == "/notice/1/common-info.html" && hits[r.URL.Path] == 1
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/common-info.html"):
+			if first {
+				close(started)
+				<-release
+			}
+			fmt.Fprint(w, `<html><body><section class="blockInfo__section">
+				<span class="section__title">Место нахождения</span>
+				<span class="section__info">г Казань</span>
+			</section></body></html>`)
+		case strings.HasSuffix(r.URL.Path, "/documents.html"):
+			fmt.Fprint(w, `<html><body><a href="/filestore/download?uid=1" title="ТЗ.docx">ТЗ</a></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	// Без кэша: повторная загрузка страницы была бы видна по счетчику
+	settings := sources.Settings{
+		HTTPTimeout: 5 * time.Second,
+		MaxAttempts: 1,
+		Fetcher:     fetcher.New(),
+	}.WithFetcher(serverURL.Hostname())
+	notices := newNotices(settings)
+	link := server.URL + "/notice/1/common-info.html"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var (
+		wg             sync.WaitGroup
+		plain, withDoc notice
+		plainErr, err  error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		plain, plainErr = notices.get(ctx, link, false)
+	}()
+	<-started
+	go func() {
+		defer wg.Done()
+		withDoc, err = notices.get(ctx, link, true)
+	}()
+	// Запрос с документами успевает встать в ожидание обычной загрузки
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if plainErr != nil || err != nil {
+		t.Fatalf("get: %v, %v", plainErr, err)
+	}
+	if plain.Place != "г Казань" || withDoc.Place != "г Казань" {
+		t.Errorf("место: %q, %q", plain.Place, withDoc.Place)
+	}
+	if !withDoc.HasDocuments || len(withDoc.Details.Documents) != 1 {
+		t.Errorf("документы не загружены: %+v", withDoc.Details.Documents)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if hits["/notice/1/common-info.html"] != 1 || hits["/notice/1/documents.html"] != 1 {
+		t.Errorf("запросы: %v, want по одному на страницу", hits)
+	}
+}
+
+func TestRememberBoundsKnown(t *testing.T) {
+	notices := newNotices(sources.Settings{DetailCacheTTL: time.Hour})
+	now := time.Now()
+
+	for i := range maxKnownNotices {
+		notices.known[fmt.Sprint(i)] = cachedNotice{expires: now.Add(time.Duration(i+1) * time.Minute)}
+	}
+	notices.known["0"] = cachedNotice{expires: now.Add(-time.Minute)}
+
+	tests := []struct {
+		link string
+		// evicted - запись, которая должна уйти ради новой
+		evicted string
+	}{
+		{"expired", "0"},
+		{"earliest", "1"},
+		{"next", "2"},
+	}
+
+	for _, tt := range tests {
+		notices.remember(tt.link, notice{Place: tt.link})
+
+		if len(notices.known) > maxKnownNotices {
+			t.Fatalf("%s: в памяти %d извещений, предел %d", tt.link, len(notices.known), maxKnownNotices)
+		}
+		if _, ok := notices.known[tt.evicted]; ok {
+			t.Errorf("%s: запись %s не удалена", tt.link, tt.evicted)
+		}
+		if cached, ok := notices.cached(tt.link); !ok || cached.Place != tt.link {
+			t.Errorf("%s: новая запись не сохранена", tt.link)
+		}
+	}
+
+	// Устаревшая запись удаляется при обращении
+	notices.known["stale"] = cachedNotice{expires: now.Add(-time.Second)}
+	if _, ok := notices.cached("stale"); ok {
+		t.Error("устаревшее извещение возвращено")
+	}
+	if _, ok := notices.known["stale"]; ok {
+		t.Error("устаревшее извещение осталось в памяти")
+	}
+}
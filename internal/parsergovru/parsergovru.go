@@ -1,67 +1,51 @@
 package parsergovru
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
-	"unicode"
 
+	"tendertracker/internal/fetcher"
 	"tendertracker/internal/logger"
 	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
 	"tendertracker/internal/urlgen"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
 type Parser struct {
-	client *http.Client
+	settings sources.Settings
+	notices  *notices
 }
 
-func NewParser() *Parser {
-	return &Parser{
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
-	}
+func NewParser(settings sources.Settings, notices *notices) *Parser {
+	return &Parser{settings: settings, notices: notices}
 }
 
-func ParseGovRu(name string, config *models.Config, re *regexp.Regexp) ([]models.Tender, error) {
-	switch name {
-	case "vent":
-		return parseSingleCategory(config, "вентиляции", config.MinPriceVent, name, re)
-
-	case "doors":
-		return parseMultipleCategories(config, []string{
-			"монтаж двер",
-			"дверны блок",
-			"установ двер",
-			"замен двер",
-		}, config.MinPriceDoors, name, re)
-
-	case "build":
-		return parseMultipleCategories(config, []string{
-			"реконструкция здания",
-			"строительство здания",
-			"капитальный ремонт здания",
-		}, config.MinPriceBuild, name, re)
-
-	case "metal":
-		return parseSingleCategory(config, "изготовление металлоконструкц", config.MinPriceMetal, name, re)
+// SourceName - ключ площадки в файле категорий
+const SourceName = "ZakupkiGovRu"
+
+// Host - хост площадки для ограничений запросов
+const Host = "zakupki.gov.ru"
+
+func ParseGovRu(ctx context.Context, settings sources.Settings, notices *notices, query sources.Query) ([]models.Tender, error) {
+	category := query.Category
+	searchStrings := category.SearchStrings(SourceName)
+	if len(searchStrings) == 0 {
+		return nil, fmt.Errorf("%s: не заданы поисковые строки для %s", category.Name, SourceName)
 	}
 
-	return nil, fmt.Errorf("incorrect parameters")
+	return parseMultipleCategories(ctx, NewParser(settings, notices), query, searchStrings, query.Config.MinPrice(category.Name))
 }
 
-func parseSingleCategory(config *models.Config, searchString string, minPrice int, name string, re *regexp.Regexp) ([]models.Tender, error) {
-	url := createUrl(*config, searchString, minPrice)
-	return NewParser().ParseAllPages(name, url, re, config)
-}
+func parseMultipleCategories(ctx context.Context, parser *Parser, query sources.Query, searchStrings []string, minPrice int) ([]models.Tender, error) {
+	category := query.Category
 
-func parseMultipleCategories(config *models.Config, searchStrings []string, minPrice int, name string, re *regexp.Regexp) ([]models.Tender, error) {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
@@ -71,32 +55,36 @@ func parseMultipleCategories(config *models.Config, searchStrings []string, minP
 	parseInGoroutine := func(searchString string, suffix string) {
 		defer wg.Done()
 
-		url := createUrl(*config, searchString, minPrice)
-		tenders, err := NewParser().ParseAllPages(name+suffix, url, re, config)
+		url := createUrl(*query.Config, searchString, minPrice, parser.settings.LookBackFrom(time.Now()))
+		tenders, err := parser.ParseAllPages(ctx, category.Name+suffix, url, query, minPrice)
 
 		mu.Lock()
 		if err != nil {
 			allErrors = append(allErrors, fmt.Sprintf("%s: %v", searchString, err))
-		} else {
-			allTenders = append(allTenders, tenders...)
 		}
+		// При ошибке или отмене сохраняем то, что успели собрать
+		allTenders = append(allTenders, tenders...)
 		mu.Unlock()
 	}
 
 	wg.Add(len(searchStrings))
 	for i, searchString := range searchStrings {
-		go parseInGoroutine(searchString, strconv.Itoa(i))
+		suffix := ""
+		if len(searchStrings) > 1 {
+			suffix = strconv.Itoa(i)
+		}
+		go parseInGoroutine(searchString, suffix)
 	}
 	wg.Wait()
 
 	if len(allErrors) > 0 {
-		return allTenders, fmt.Errorf("%s search failed: %s", name, strings.Join(allErrors, "; "))
+		return mergeTendersWithoutDuplicates(allTenders), fmt.Errorf("%s search failed: %s", category.Name, strings.Join(allErrors, "; "))
 	}
 
 	return mergeTendersWithoutDuplicates(allTenders), nil
 }
 
-func (p *Parser) ParseAllPages(name, baseURL string, re *regexp.Regexp, config *models.Config) ([]models.Tender, error) {
+func (p *Parser) ParseAllPages(ctx context.Context, name, baseURL string, query sources.Query, minPrice int) ([]models.Tender, error) {
 	var allTenders []models.Tender
 	quantityCards := 100
 	page := 1
@@ -107,15 +95,16 @@ func (p *Parser) ParseAllPages(name, baseURL string, re *regexp.Regexp, config *
 		logger.SugaredLogger.Infof("%s: Парсинг страницы %d...\n", name, page)
 		logger.SugaredLogger.Debug(url)
 
-		tenders, totalCards, err := p.ParsePage(name, url, re, config)
+		tenders, totalCards, err := p.ParsePage(ctx, name, url, query, minPrice)
 		if err != nil {
-			return nil, fmt.Errorf("%s: ошибка на странице %d: %w", name, page, err)
+			return allTenders, fmt.Errorf("%s: ошибка на странице %d: %w", name, page, err)
 		}
 
 		allTenders = append(allTenders, tenders...)
 
 		logger.SugaredLogger.Infof("%s: Страница %d: найдено %d карточек, распарсено %d тендеров\n",
 			name, page, totalCards, len(tenders))
+		query.Report(SourceName, sources.Event{Kind: sources.EventPage, Page: page, Cards: totalCards, Kept: len(tenders)})
 
 		if totalCards < quantityCards {
 			logger.SugaredLogger.Infof("%s: Последняя страница достигнута. Всего страниц: %d\n", name, page)
@@ -127,88 +116,48 @@ func (p *Parser) ParseAllPages(name, baseURL string, re *regexp.Regexp, config *
 			break
 		}
 
-		page++
-		time.Sleep(1 * time.Second)
-	}
-
-	fmt.Printf("%s: Всего собрано тендеров: %d\n", name, len(allTenders))
-	return allTenders, nil
-}
-
-func (p *Parser) ParsePage(name, url string, re *regexp.Regexp, config *models.Config) ([]models.Tender, int, error) {
-	var resp *http.Response
-	var err error
-
-	for attempt := 1; attempt <= 3; attempt++ {
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return nil, 0, fmt.Errorf("ошибка создания запроса: %w", err)
-		}
-
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-		req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
-
-		resp, err = p.client.Do(req)
-		if err == nil {
+		if p.settings.MaxPages > 0 && page >= p.settings.MaxPages {
+			logger.SugaredLogger.Infof("%s: Достигнут лимит в %d страниц", name, p.settings.MaxPages)
 			break
 		}
 
-		if attempt < 3 {
-			waitTime := time.Duration(attempt*attempt) * 2 * time.Second
-			logger.SugaredLogger.Warnf("%s Попытка %d не удалась, повтор через %v: %v", name, attempt, waitTime, err)
-			time.Sleep(waitTime)
-			continue
+		page++
+		if err := sources.Sleep(ctx, p.settings.PageDelay); err != nil {
+			return allTenders, err
 		}
-		return nil, 0, fmt.Errorf("ошибка выполнения запроса после 3 попыток: %w", err)
 	}
 
-	if err != nil {
-		return nil, 0, fmt.Errorf("ошибка выполнения запроса после 3 попыток: %w", err)
-	}
-	defer resp.Body.Close()
+	logger.SugaredLogger.Infof("%s: Всего собрано тендеров: %d", name, len(allTenders))
+	return allTenders, nil
+}
 
-	if resp.StatusCode != 200 {
-		return nil, 0, fmt.Errorf("статус код ошибки: %d %s", resp.StatusCode, resp.Status)
+func (p *Parser) ParsePage(ctx context.Context, name, url string, query sources.Query, minPrice int) ([]models.Tender, int, error) {
+	req, err := newRequest(ctx, url)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка создания запроса: %w", err)
 	}
 
-	// Парсим HTML
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := fetchDocument(p.settings.Fetcher, req, query.RetryReporter(SourceName, name))
 	if err != nil {
-		return nil, 0, fmt.Errorf("ошибка парсинга HTML: %w", err)
+		return nil, 0, err
 	}
 
 	var tenders []models.Tender
-	totalCards := doc.Find(".search-registry-entry-block").Length()
-
-	var cards []*goquery.Selection
-	doc.Find(".search-registry-entry-block").Each(func(i int, s *goquery.Selection) {
-		cards = append(cards, s)
+	cards := doc.Find(".search-registry-entry-block")
+	cards.Each(func(i int, s *goquery.Selection) {
+		tender := p.parseTenderCard(name, s, query, minPrice)
+		if tender.Title != "" {
+			tenders = append(tenders, tender)
+		}
 	})
 
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-
-	for _, card := range cards {
-		wg.Add(1)
-
-		go func(s *goquery.Selection) {
-			defer wg.Done()
-
-			tender := p.parseTenderCard(name, s, re, config)
-			if tender.Title != "" {
-				mu.Lock()
-				tenders = append(tenders, tender)
-				mu.Unlock()
-			}
-		}(card)
-	}
-
-	wg.Wait()
+	// Адреса заказчиков есть только в извещениях, их открывают после отсева.
+	// Ответ выдачи к этому времени закрыт и не занимает место хоста
+	p.notices.fill(ctx, name, tenders, query.Config.EnrichNotices)
 
-	return tenders, totalCards, nil
+	return tenders, cards.Length(), nil
 }
-func (p *Parser) parseTenderCard(name string, s *goquery.Selection, re *regexp.Regexp, config *models.Config) models.Tender {
+func (p *Parser) parseTenderCard(name string, s *goquery.Selection, query sources.Query, minPrice int) models.Tender {
 	var tender models.Tender
 
 	// Название
@@ -216,69 +165,41 @@ func (p *Parser) parseTenderCard(name string, s *goquery.Selection, re *regexp.R
 
 	// logger.SugaredLogger.Debugf(s.Text())
 
-	if re.MatchString(strings.ToLower(tender.Title)) {
-		logger.SugaredLogger.Debugf("%s: отменено: %s", name, tender.Title)
-		return models.Tender{}
-	}
-
 	// Цена - ищем ТОЛЬКО в пределах текущей карточки
-	var minPrice int
-
-	switch name {
-	case ("vent"):
-		minPrice = config.MinPriceVent
-	case ("doors"):
-		minPrice = config.MinPriceDoors
-	case ("metal"):
-		minPrice = config.MinPriceMetal
-	default:
-		if strings.Contains(name, "build") {
-			minPrice = config.MinPriceBuild
-		} else {
-			minPrice = 0
-		}
-	}
-
 	priceElem := s.Find(".price-block__value")
 	if priceElem.Length() > 0 {
-		priceText := strings.TrimSpace(priceElem.First().Text())
-
-		priceText = strings.ReplaceAll(priceText, "	", "")
-		priceText = strings.ReplaceAll(priceText, "	", "")      // табуляция
-		priceText = strings.ReplaceAll(priceText, "\u00A0", "") // неразрывный пробел
-
-		// Находим индекс первого нецифрового символа
-		firstNonDigit := strings.IndexFunc(priceText, func(r rune) bool {
-			return !unicode.IsDigit(r)
-		})
-
-		if firstNonDigit != -1 {
-			priceText = priceText[:firstNonDigit]
-
-			priceInt, err := strconv.Atoi(priceText)
-			if err != nil {
-				logger.SugaredLogger.Warnf("Incorrect trimed price (not number)")
-			}
-			if priceInt < minPrice {
-				return models.Tender{}
-			}
-
+		price, err := models.ParsePrice(priceElem.First().Text())
+		if err != nil {
+			logger.SugaredLogger.Warnf("Incorrect price in tender card: %v", err)
+			query.Report(SourceName, sources.Event{Kind: sources.EventWarning, Message: err.Error()})
 		} else {
-			logger.SugaredLogger.Warnln("Incorrect price in tender card")
+			tender.Price = price
 		}
+	}
+	tender.Currency = "RUB"
 
-		tender.Price = strings.TrimSpace(priceElem.First().Text())
-	} else {
-		tender.Price = "Не указана" // или пустая строка
+	// Закон и способ определения поставщика: "44-ФЗ Электронный аукцион"
+	header := strings.Fields(s.Find(".registry-entry__header-top__title").First().Text())
+	if len(header) > 0 {
+		tender.Law = models.NormalizeLaw(header[0])
+		tender.Method = strings.Join(header[1:], " ")
 	}
 
+	// Номер и статус
+	tender.Number = strings.TrimSpace(strings.TrimPrefix(
+		strings.TrimSpace(s.Find(".registry-entry__header-mid__number a").First().Text()), "№"))
+	tender.Status = strings.TrimSpace(s.Find(".registry-entry__header-mid__title").First().Text())
+
 	dateBlocks := s.Find(".data-block .row .col-6")
 	dateBlocks.Each(func(i int, dateBlock *goquery.Selection) {
 		title := strings.TrimSpace(dateBlock.Find(".data-block__title").Text())
 		value := strings.TrimSpace(dateBlock.Find(".data-block__value").Text())
 
-		if title == "Размещено" {
-			tender.PublishDate = value
+		switch title {
+		case "Размещено":
+			tender.PublishDate = parseDate(name, value)
+		case "Окончание подачи заявок":
+			tender.EndDate = parseDate(name, value)
 		}
 	})
 
@@ -295,63 +216,54 @@ func (p *Parser) parseTenderCard(name string, s *goquery.Selection, re *regexp.R
 	// Заказчик
 	tender.Customer = strings.TrimSpace(s.Find(".registry-entry__body-href").Text())
 
-	// Дата окончания подачи заявок (находится отдельно)
-	applicationEnd := s.Find(".data-block__title:contains('Окончание подачи заявок') + .data-block__value")
-	if applicationEnd.Length() > 0 {
-		tender.EndDate = strings.TrimSpace(applicationEnd.Text())
+	// Отсев до запроса адреса, чтобы не открывать лишние извещения
+	if !query.Passes(SourceName, tender) || !query.PassesMinPrice(SourceName, tender, minPrice) {
+		return models.Tender{}
 	}
 
-	//Адрес
-	tender.Region = NewParser().parsePlace(tender.Link)
-
 	return tender
 }
 
-func (p *Parser) parsePlace(url string) string {
-	req, err := http.NewRequest("GET", url, nil)
+// fetchDocument загружает и разбирает HTML-страницу. Ответ закрывается до
+// возврата: место хоста в fetcher держится, пока открыто тело, и страница,
+// которая открывает другие страницы той же площадки, иначе ждала бы сама себя
+func fetchDocument(client *fetcher.Fetcher, req *http.Request, onRetry fetcher.RetryFunc) (*goquery.Document, error) {
+	resp, err := client.Do(req, onRetry)
 	if err != nil {
-		logger.SugaredLogger.Errorf("ошибка создания запроса: %v", err)
-		return ""
-	}
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		logger.SugaredLogger.Errorf("ошибка отправки запроса: %v", err)
-		return ""
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		logger.SugaredLogger.Errorf("неверный статус код: %d", resp.StatusCode)
-		return ""
+		return nil, fmt.Errorf("статус код ошибки: %d %s", resp.StatusCode, resp.Status)
 	}
 
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		logger.SugaredLogger.Errorf("ошибка парсинга HTML: %v", err)
-		return ""
+		return nil, fmt.Errorf("ошибка парсинга HTML: %w", err)
 	}
+	return doc, nil
+}
 
-	place := doc.Find(".blockInfo__section .section__info").FilterFunction(func(i int, s *goquery.Selection) bool {
-		// Проверяем, что предыдущий элемент содержит заголовок "Место нахождения"
-		title := s.Prev().Find(".section__title").Text()
-		return strings.Contains(title, "Место нахождения")
-	}).First()
-
-	if place.Length() == 0 {
-		// Альтернативный поиск, если структура немного отличается
-		place = doc.Find("section:contains('Место нахождения') .section__info").First()
+// newRequest готовит запрос страницы площадки с заголовками браузера
+func newRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if place.Length() > 0 {
-		return strings.TrimSpace(place.Text())
-	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+	return req, nil
+}
 
-	return ""
+func parseDate(name, value string) time.Time {
+	date, err := models.ParseDate(value, "02.01.2006 15:04", "02.01.2006")
+	if err != nil {
+		logger.SugaredLogger.Warnf("%s: %v", name, err)
+	}
+	return date
 }
 
 func mergeTendersWithoutDuplicates(tenderSlices ...[]models.Tender) []models.Tender {
@@ -360,8 +272,12 @@ func mergeTendersWithoutDuplicates(tenderSlices ...[]models.Tender) []models.Ten
 
 	for _, slice := range tenderSlices {
 		for _, tender := range slice {
-			if tender.Title != "" && !seen[tender.Title] {
-				seen[tender.Title] = true
+			key := tender.Number
+			if key == "" {
+				key = tender.Title
+			}
+			if tender.Title != "" && !seen[key] {
+				seen[key] = true
 				result = append(result, tender)
 			}
 		}
@@ -370,12 +286,11 @@ func mergeTendersWithoutDuplicates(tenderSlices ...[]models.Tender) []models.Ten
 	return result
 }
 
-func createUrl(config models.Config, searchText string, minPrice int) string {
+// createUrl строит ссылку на поиск закупок с окончанием подачи заявок не раньше from
+func createUrl(config models.Config, searchText string, minPrice int, from time.Time) string {
 	encoder := urlgen.NewURLEncoder("https://zakupki.gov.ru/epz/order/extendedsearch/results.html")
 
-	now := time.Now()
-	twoYearsAgo := now.AddDate(-1, 0, 0)
-	dateString := twoYearsAgo.Format("02.01.2006")
+	dateString := from.Format("02.01.2006")
 
 	url := encoder.
 		AddParam("morphology", "on").
@@ -0,0 +1,103 @@
+package parsergovru
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"tendertracker/internal/categories"
+	"tendertracker/internal/fetcher"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
+)
+
+// newTestSite отдает выдачу из cards карточек, извещения с адресом заказчика
+// и страницы документов
+func newTestSite(cards int) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		switch {
+		case r.URL.Path == "/search":
+			var page strings.Builder
+			for i := range cards {
+				fmt.Fprintf(&page, `<div class="search-registry-entry-block">
+					<div class="registry-entry__header-top__title">44-ФЗ Электронный аукцион</div>
+					<div class="registry-entry__header-mid__number"><a href="%s/notice/%d/common-info.html">№ 0373100000126%06d</a></div>
+					<div class="registry-entry__body-value">Монтаж вентиляции %d</div>
+					<div class="price-block__value">1 500 000,00 ₽</div>
+				</div>`, server.URL, i, i, i)
+			}
+			fmt.Fprintf(w, "<html><body>%s</body></html>", page.String())
+		case strings.HasSuffix(r.URL.Path, "/common-info.html"):
+			fmt.Fprint(w, `<html><body><section class="blockInfo__section">
+				<span class="section__title">Место нахождения</span>
+				<span class="section__info">Российская Федерация, 420000, Татарстан Респ, г Казань</span>
+			</section></body></html>`)
+		case strings.HasSuffix(r.URL.Path, "/documents.html"):
+			fmt.Fprint(w, `<html><body><a href="/filestore/download?uid=1" title="Техническое задание.docx">ТЗ</a></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return server
+}
+
+// Извещения открываются после выдачи на том же хосте. С одним местом на хост
+// выдача не должна держать его, пока загружаются извещения и их документы
+func TestParsePageSingleSlot(t *testing.T) {
+	logger.InitLogger("error")
+
+	server := newTestSite(3)
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	client := fetcher.New()
+	settings := sources.Settings{
+		HTTPTimeout:   5 * time.Second,
+		MaxConcurrent: 1,
+		MaxAttempts:   1,
+		Fetcher:       client,
+		DetailWorkers: 3,
+	}.WithFetcher(serverURL.Hostname())
+
+	parser := NewParser(settings, newNotices(settings))
+	query := sources.Query{
+		Category: &categories.Category{Name: "vent"},
+		Config:   &models.Config{EnrichNotices: true},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	tenders, cards, err := parser.ParsePage(ctx, "vent", server.URL+"/search", query, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("ParsePage не уложился в таймаут: выдача держит место хоста")
+	}
+	if cards != 3 || len(tenders) != 3 {
+		t.Fatalf("карточек %d, тендеров %d, want 3", cards, len(tenders))
+	}
+
+	for _, tender := range tenders {
+		if !strings.Contains(tender.Region, "Казань") {
+			t.Errorf("%s: регион %q не из извещения", tender.Number, tender.Region)
+		}
+		if tender.Details == nil || len(tender.Details.Documents) != 1 {
+			t.Errorf("%s: документы не загружены: %+v", tender.Number, tender.Details)
+			continue
+		}
+		if want := server.URL + "/filestore/download?uid=1"; tender.Details.Documents[0].URL != want {
+			t.Errorf("%s: ссылка на документ %s, want %s", tender.Number, tender.Details.Documents[0].URL, want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package parsergovru
+
+import (
+	"context"
+	"fmt"
+
+	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
+)
+
+// Source - площадка zakupki.gov.ru
+type Source struct {
+	settings sources.Settings
+	// notices - общая для всех поисков загрузка извещений
+	notices *notices
+}
+
+func NewSource(settings sources.Settings) *Source {
+	settings = settings.WithFetcher(Host)
+	return &Source{settings: settings, notices: newNotices(settings)}
+}
+
+func (s *Source) Name() string {
+	return SourceName
+}
+
+func (s *Source) Title() string {
+	return "Zakupki.Gov.ru"
+}
+
+func (s *Source) Search(ctx context.Context, query sources.Query) ([]models.Tender, error) {
+	return ParseGovRu(ctx, s.settings, s.notices, query)
+}
+
+// Documents возвращает документы извещения с вкладки "Документы"
+func (s *Source) Documents(ctx context.Context, tender models.Tender) ([]models.Document, error) {
+	notice, err := s.notices.get(ctx, tender.Link, true)
+	if err != nil {
+		return nil, err
+	}
+	if !notice.HasDocuments {
+		return nil, fmt.Errorf("не удалось загрузить список документов %s", tender.Link)
+	}
+	return notice.Details.Documents, nil
+}
@@ -0,0 +1,108 @@
+package parsersber
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"tendertracker/internal/models"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// documentExtensions - расширения файлов документации в ссылках на вложения
+var documentExtensions = map[string]bool{
+	".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true, ".rtf": true,
+	".odt": true, ".ods": true, ".txt": true, ".zip": true, ".rar": true, ".7z": true,
+	".jpg": true, ".jpeg": true, ".png": true, ".tif": true, ".tiff": true, ".xml": true, ".sig": true,
+}
+
+// attachmentHref - ссылки на вложения процедуры: файловое хранилище и обработчики скачивания
+var attachmentHref = regexp.MustCompile(`(?i)(download|getfile|filestore|/files?/|attachment)`)
+
+// attachmentBlocks - блоки вложений на странице процедуры. Ссылки вне них
+// (регламенты и инструкции в шапке и подвале сайта) документацией не считаются
+const attachmentBlocks = `[id*="attach" i], [class*="attach" i], [id*="file" i], [class*="file" i], [id*="document" i], [class*="document" i]`
+
+// fetchDocuments загружает страницу процедуры и собирает ссылки на вложения
+func (p *Parser) fetchDocuments(ctx context.Context, link string) ([]models.Document, error) {
+	if link == "" {
+		return []models.Document{}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+
+	resp, err := p.settings.Fetcher.Do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("неверный статус код: %d", resp.StatusCode)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка парсинга HTML: %w", err)
+	}
+	return parseAttachments(doc, resp.Request.URL), nil
+}
+
+// parseAttachments собирает ссылки на файлы из блоков вложений страницы
+// процедуры. Ссылкой на файл считается адрес обработчика скачивания или ссылка
+// с расширением документа на том же хосте, что и страница
+func parseAttachments(doc *goquery.Document, base *url.URL) []models.Document {
+	documents := []models.Document{}
+	if base == nil {
+		return documents
+	}
+	seen := make(map[string]bool)
+
+	doc.Find(attachmentBlocks).Find("a[href]").Each(func(i int, a *goquery.Selection) {
+		if a.Closest("header, footer, nav").Length() > 0 {
+			return
+		}
+
+		href, _ := a.Attr("href")
+		ref, err := url.Parse(strings.TrimSpace(href))
+		if err != nil {
+			return
+		}
+		ref = base.ResolveReference(ref)
+		if (ref.Scheme != "http" && ref.Scheme != "https") || !strings.EqualFold(ref.Hostname(), base.Hostname()) {
+			return
+		}
+
+		name := strings.Join(strings.Fields(a.AttrOr("title", "")), " ")
+		if name == "" {
+			name = strings.Join(strings.Fields(a.Text()), " ")
+		}
+
+		if !attachmentHref.MatchString(ref.Path+"?"+ref.RawQuery) &&
+			!documentExtensions[strings.ToLower(path.Ext(ref.Path))] &&
+			!documentExtensions[strings.ToLower(path.Ext(name))] {
+			return
+		}
+
+		link := ref.String()
+		if seen[link] {
+			return
+		}
+		seen[link] = true
+
+		documents = append(documents, models.Document{Name: name, URL: link})
+	})
+
+	return documents
+}
@@ -0,0 +1,61 @@
+package parsersber
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const procedurePage = `<html><body>
+<header>
+	<a href="/files/reglament.pdf">Регламент площадки.pdf</a>
+	<div class="user-files"><a href="/download/manual">Инструкция.docx</a></div>
+</header>
+<div class="procedure">
+	<a href="/purchase/12345">Карточка процедуры</a>
+	<a href="/download/nowhere">Ссылка вне блока вложений</a>
+</div>
+<table id="ctl00_FilesTable">
+	<tr><td><a href="/Download.ashx?fileId=1" title="Техническое  задание.docx">ТЗ</a></td></tr>
+	<tr><td><a href="https://sberbank-ast.ru/files/2/project.pdf">Проект договора</a></td></tr>
+	<tr><td><a href="/Download.ashx?fileId=1">Дубль</a></td></tr>
+	<tr><td><a href="https://evil.example/files/3/virus.zip">virus.zip</a></td></tr>
+	<tr><td><a href="//cdn.example/download/4">Другой хост</a></td></tr>
+	<tr><td><a href="javascript:download(5)">Смета.xlsx</a></td></tr>
+	<tr><td><a href="ftp://sberbank-ast.ru/files/6.zip">ftp</a></td></tr>
+	<tr><td><a href="/help">Справка</a></td></tr>
+</table>
+<footer><div class="documents"><a href="/files/offer.pdf">Оферта.pdf</a></div></footer>
+</body></html>`
+
+func TestParseAttachments(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(procedurePage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := url.Parse("https://sberbank-ast.ru/procedure/12345")
+
+	want := []struct {
+		name string
+		url  string
+	}{
+		{"Техническое задание.docx", "https://sberbank-ast.ru/Download.ashx?fileId=1"},
+		{"Проект договора", "https://sberbank-ast.ru/files/2/project.pdf"},
+	}
+
+	got := parseAttachments(doc, base)
+	if len(got) != len(want) {
+		t.Fatalf("документов %d, want %d: %+v", len(got), len(want), got)
+	}
+	for i, document := range got {
+		if document.Name != want[i].name || document.URL != want[i].url {
+			t.Errorf("документ %d: %+v, want %+v", i, document, want[i])
+		}
+	}
+
+	if documents := parseAttachments(doc, nil); len(documents) != 0 {
+		t.Errorf("без адреса страницы: %+v", documents)
+	}
+}
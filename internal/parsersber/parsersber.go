@@ -1,13 +1,13 @@
 package parsersber
 
 import (
+	"context"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,18 +15,15 @@ import (
 
 	"tendertracker/internal/logger"
 	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
 )
 
 type Parser struct {
-	client *http.Client
+	settings sources.Settings
 }
 
-func NewParser() *Parser {
-	return &Parser{
-		client: &http.Client{
-			Timeout: 60 * time.Second,
-		},
-	}
+func NewParser(settings sources.Settings) *Parser {
+	return &Parser{settings: settings}
 }
 
 // Карта соответствия кодов ФО и их названий
@@ -56,39 +53,25 @@ func ConvertDistrictCodesToRegions(codes []string) []string {
 	return allRegions
 }
 
-func ParseSberAst(name string, config *models.Config, re *regexp.Regexp) ([]models.Tender, error) {
-	switch name {
-	case "vent":
-		return parseSingleCategory(config, "вент", config.MinPriceVent, name, re)
-
-	case "doors":
-		return parseMultipleCategories(config, []string{
-			"монтаж двер",
-			"дверны блок",
-			"установ двер",
-			"замен двер",
-		}, config.MinPriceDoors, name, re)
-
-	case "build":
-		return parseMultipleCategories(config, []string{
-			"реконструкция",
-			"строительство",
-			"капитальный ремонт",
-		}, config.MinPriceBuild, name, re)
-
-	case "metal":
-		return parseSingleCategory(config, "металлоконструкц", config.MinPriceMetal, name, re)
+// SourceName - ключ площадки в файле категорий
+const SourceName = "Sber"
+
+// Host - хост площадки для ограничений запросов
+const Host = "sberbank-ast.ru"
+
+func ParseSberAst(ctx context.Context, settings sources.Settings, query sources.Query) ([]models.Tender, error) {
+	category := query.Category
+	searchStrings := category.SearchStrings(SourceName)
+	if len(searchStrings) == 0 {
+		return nil, fmt.Errorf("%s: не заданы поисковые строки для %s", category.Name, SourceName)
 	}
 
-	return nil, fmt.Errorf("incorrect parameters")
+	return parseMultipleCategories(ctx, settings, query, searchStrings, query.Config.MinPrice(category.Name))
 }
 
-func parseSingleCategory(config *models.Config, searchString string, minPrice int, name string, re *regexp.Regexp) ([]models.Tender, error) {
-	searchRequest := createSearchRequest(searchString, minPrice, config, 0, 20)
-	return NewParser().ParseAllPages(name, searchRequest, re, config, minPrice)
-}
+func parseMultipleCategories(ctx context.Context, settings sources.Settings, query sources.Query, searchStrings []string, minPrice int) ([]models.Tender, error) {
+	category := query.Category
 
-func parseMultipleCategories(config *models.Config, searchStrings []string, minPrice int, name string, re *regexp.Regexp) ([]models.Tender, error) {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
@@ -98,46 +81,50 @@ func parseMultipleCategories(config *models.Config, searchStrings []string, minP
 	parseInGoroutine := func(searchString string, suffix string) {
 		defer wg.Done()
 
-		searchRequest := createSearchRequest(searchString, minPrice, config, 0, 20)
-		tenders, err := NewParser().ParseAllPages(name+suffix, searchRequest, re, config, minPrice)
+		searchRequest := createSearchRequest(searchString, minPrice, query.Config, settings.LookBackFrom(time.Now()), 0, 20)
+		tenders, err := NewParser(settings).ParseAllPages(ctx, category.Name+suffix, searchRequest, query)
 
 		mu.Lock()
 		if err != nil {
 			allErrors = append(allErrors, fmt.Sprintf("%s: %v", searchString, err))
-		} else {
-			allTenders = append(allTenders, tenders...)
 		}
+		// При ошибке или отмене сохраняем то, что успели собрать
+		allTenders = append(allTenders, tenders...)
 		mu.Unlock()
 	}
 
 	wg.Add(len(searchStrings))
 	for i, searchString := range searchStrings {
-		go parseInGoroutine(searchString, strconv.Itoa(i))
+		suffix := ""
+		if len(searchStrings) > 1 {
+			suffix = strconv.Itoa(i)
+		}
+		go parseInGoroutine(searchString, suffix)
 	}
 	wg.Wait()
 
 	if len(allErrors) > 0 {
-		return allTenders, fmt.Errorf("%s search failed: %s", name, strings.Join(allErrors, "; "))
+		return mergeTendersWithoutDuplicates(allTenders), fmt.Errorf("%s search failed: %s", category.Name, strings.Join(allErrors, "; "))
 	}
 
 	return mergeTendersWithoutDuplicates(allTenders), nil
 }
 
-func (p *Parser) ParseAllPages(name string, searchRequest ElasticRequest, re *regexp.Regexp, config *models.Config, minPrice int) ([]models.Tender, error) {
+func (p *Parser) ParseAllPages(ctx context.Context, name string, searchRequest ElasticRequest, query sources.Query) ([]models.Tender, error) {
 	var allTenders []models.Tender
 	pageSize := 20
 	from := 0
-	maxPages := 50
+	maxPages := p.settings.MaxPages
 
-	for page := 1; page <= maxPages; page++ {
+	for page := 1; ; page++ {
 		searchRequest.From = from
 		searchRequest.Size = pageSize
 
 		logger.SugaredLogger.Infof("%s: Парсинг страницы %d (from: %d, size: %d)...", name, page, from, pageSize)
 
-		tenders, totalHits, err := p.ParsePage(name, searchRequest, re, config, minPrice)
+		tenders, totalHits, cards, err := p.ParsePage(ctx, name, searchRequest, query)
 		if err != nil {
-			return nil, fmt.Errorf("%s: ошибка на странице %d: %w", name, page, err)
+			return allTenders, fmt.Errorf("%s: ошибка на странице %d: %w", name, page, err)
 		}
 
 		if len(tenders) == 0 {
@@ -149,8 +136,9 @@ func (p *Parser) ParseAllPages(name string, searchRequest ElasticRequest, re *re
 
 		logger.SugaredLogger.Infof("%s: Страница %d: найдено %d тендеров, распарсено %d, всего: %d",
 			name, page, totalHits, len(tenders), len(allTenders))
+		query.Report(SourceName, sources.Event{Kind: sources.EventPage, Page: page, Cards: cards, Kept: len(tenders)})
 
-		if page >= maxPages {
+		if maxPages > 0 && page >= maxPages {
 			logger.SugaredLogger.Infof("%s: Достигнут лимит в %d страниц", name, maxPages)
 			break
 		}
@@ -161,20 +149,20 @@ func (p *Parser) ParseAllPages(name string, searchRequest ElasticRequest, re *re
 		}
 
 		from += pageSize
-		time.Sleep(1 * time.Second)
+		if err := sources.Sleep(ctx, p.settings.PageDelay); err != nil {
+			return allTenders, err
+		}
 	}
 
 	logger.SugaredLogger.Infof("%s: Завершено. Всего собрано тендеров: %d", name, len(allTenders))
 	return allTenders, nil
 }
 
-func (p *Parser) ParsePage(name string, searchRequest ElasticRequest, re *regexp.Regexp, config *models.Config, minPrice int) ([]models.Tender, int, error) {
-	var resp *http.Response
-	var err error
-
+// ParsePage возвращает отобранные тендеры, общее число найденных и число карточек на странице
+func (p *Parser) ParsePage(ctx context.Context, name string, searchRequest ElasticRequest, query sources.Query) ([]models.Tender, int, int, error) {
 	xmlData, err := xml.Marshal(searchRequest)
 	if err != nil {
-		return nil, 0, fmt.Errorf("ошибка маршалинга XML: %w", err)
+		return nil, 0, 0, fmt.Errorf("ошибка маршалинга XML: %w", err)
 	}
 
 	formData := url.Values{}
@@ -183,82 +171,68 @@ func (p *Parser) ParsePage(name string, searchRequest ElasticRequest, re *regexp
 	formData.Add("targetPageCode", "UnitedPurchaseList")
 	formData.Add("PID", "0")
 
-	body := strings.NewReader(formData.Encode())
+	body := formData.Encode()
 	baseURL := "https://sberbank-ast.ru/SearchQuery.aspx"
 
-	for attempt := 1; attempt <= 3; attempt++ {
-		req, err := http.NewRequest("POST", baseURL, body)
-		if err != nil {
-			return nil, 0, fmt.Errorf("ошибка создания запроса: %w", err)
-		}
-
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-		req.Header.Set("Accept", "application/json, text/plain, */*")
-		req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
-		req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL, strings.NewReader(body))
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
 
-		q := req.URL.Query()
-		q.Add("name", "Main")
-		req.URL.RawQuery = q.Encode()
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("Accept", "application/json, text/plain, */*")
+	req.Header.Set("Accept-Language", "ru-RU,ru;q=0.9,en;q=0.8")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
 
-		resp, err = p.client.Do(req)
-		if err == nil {
-			break
-		}
-
-		if attempt < 3 {
-			waitTime := time.Duration(attempt*attempt) * 2 * time.Second
-			logger.SugaredLogger.Warnf("%s Попытка %d не удалась, повтор через %v: %v", name, attempt, waitTime, err)
-			time.Sleep(waitTime)
-			continue
-		}
-		return nil, 0, fmt.Errorf("ошибка выполнения запроса после 3 попыток: %w", err)
-	}
+	q := req.URL.Query()
+	q.Add("name", "Main")
+	req.URL.RawQuery = q.Encode()
 
+	resp, err := p.settings.Fetcher.Do(req, query.RetryReporter(SourceName, name))
 	if err != nil {
-		return nil, 0, fmt.Errorf("ошибка выполнения запроса после 3 попыток: %w", err)
+		return nil, 0, 0, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, 0, fmt.Errorf("статус код ошибки: %d %s, тело ответа: %s", resp.StatusCode, resp.Status, string(bodyBytes))
+		return nil, 0, 0, fmt.Errorf("статус код ошибки: %d %s, тело ответа: %s", resp.StatusCode, resp.Status, string(bodyBytes))
 	}
 
 	var apiResponse SberAstResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return nil, 0, fmt.Errorf("ошибка декодирования JSON: %w", err)
+		return nil, 0, 0, fmt.Errorf("ошибка декодирования JSON: %w", err)
 	}
 
 	if apiResponse.Result != "success" {
-		return nil, 0, fmt.Errorf("API вернуло ошибку: %s", apiResponse.Result)
+		return nil, 0, 0, fmt.Errorf("API вернуло ошибку: %s", apiResponse.Result)
 	}
 
 	var dataResponse DataResponse
 	if err := json.Unmarshal([]byte(apiResponse.Data), &dataResponse); err != nil {
-		return nil, 0, fmt.Errorf("ошибка декодирования data JSON: %w", err)
+		return nil, 0, 0, fmt.Errorf("ошибка декодирования data JSON: %w", err)
 	}
 
 	var elasticResponse ElasticResponse
 	if err := json.Unmarshal([]byte(dataResponse.Data), &elasticResponse); err != nil {
-		return nil, 0, fmt.Errorf("ошибка декодирования elastic JSON: %w", err)
+		return nil, 0, 0, fmt.Errorf("ошибка декодирования elastic JSON: %w", err)
 	}
 
 	totalHits := elasticResponse.Hits.Total.Value
 	var tenders []models.Tender
 
 	for _, hit := range elasticResponse.Hits.Hits {
-		tender := p.parseTenderHit(name, hit, re, config)
+		tender := p.parseTenderHit(name, hit, query)
 		if tender.Title != "" {
 			tenders = append(tenders, tender)
 		}
 	}
 
-	return tenders, totalHits, nil
+	return tenders, totalHits, len(elasticResponse.Hits.Hits), nil
 }
 
-func (p *Parser) parseTenderHit(name string, hit Hit, re *regexp.Regexp, config *models.Config) models.Tender {
+func (p *Parser) parseTenderHit(name string, hit Hit, query sources.Query) models.Tender {
 	var tender models.Tender
 
 	if hit.Source.PurchName != "" {
@@ -267,38 +241,15 @@ func (p *Parser) parseTenderHit(name string, hit Hit, re *regexp.Regexp, config
 		tender.Title = hit.Source.BidName
 	}
 
-	if strings.Contains(name, "vent") {
-		if re.MatchString(strings.ToLower(tender.Title)) ||
-			!regexp.MustCompile(`вент`).MatchString(strings.ToLower(tender.Title)) {
-			return models.Tender{}
-		}
-	}
-
-	if strings.Contains(name, "doors") {
-		if re.MatchString(strings.ToLower(tender.Title)) ||
-			!regexp.MustCompile(`двер`).MatchString(strings.ToLower(tender.Title)) {
-			return models.Tender{}
-		}
-	}
-
-	if strings.Contains(name, "build") {
-		if re.MatchString(strings.ToLower(tender.Title)) ||
-			!regexp.MustCompile(`реконстру|строит|капит|ремонт`).MatchString(strings.ToLower(tender.Title)) {
-			return models.Tender{}
-		}
-	}
-
-	if strings.Contains(name, "metal") {
-		if re.MatchString(strings.ToLower(tender.Title)) ||
-			!regexp.MustCompile(`производ|изготов|монтаж металл`).MatchString(strings.ToLower(tender.Title)) {
-			return models.Tender{}
-		}
-	}
-
-	tender.Price = formatPrice(hit.Source.PurchAmount)
-	tender.PublishDate = hit.Source.PublicDate
+	tender.Number = hit.Source.PurchCodeTerm
+	tender.Price = models.RublesToKopecks(hit.Source.PurchAmount)
+	tender.Currency = hit.Source.PurchCurrency
+	tender.Law = models.NormalizeLaw(hit.Source.SourceTerm)
+	tender.Method = hit.Source.PurchaseTypeName
+	tender.Status = hit.Source.PurchStateName
+	tender.PublishDate = parseDate(name, hit.Source.PublicDate)
+	tender.EndDate = parseDate(name, hit.Source.EndDate)
 	tender.Customer = hit.Source.OrgName
-	tender.EndDate = hit.Source.EndDate
 	tender.Region = hit.Source.RegionNameTerm
 
 	if hit.Source.ObjectHrefTerm != "" {
@@ -307,40 +258,25 @@ func (p *Parser) parseTenderHit(name string, hit Hit, re *regexp.Regexp, config
 		tender.Link = hit.Source.SourceHrefTerm
 	}
 
-	return tender
-}
-
-func formatPrice(amount float64) string {
-	if amount == 0 {
-		return "Не указана"
+	// Полнотекстовый поиск Сбер-АСТ нечеткий, отсев по правилам include
+	// здесь особенно важен
+	if !query.Passes(SourceName, tender) {
+		return models.Tender{}
 	}
 
-	str := fmt.Sprintf("%.2f", amount)
-
-	parts := strings.Split(str, ".")
-	integerPart := parts[0]
-	decimalPart := parts[1]
-
-	var formattedInteger strings.Builder
-	count := 0
-
-	for i := len(integerPart) - 1; i >= 0; i-- {
-		if count > 0 && count%3 == 0 {
-			formattedInteger.WriteByte(' ')
-		}
-		formattedInteger.WriteByte(integerPart[i])
-		count++
-	}
+	return tender
+}
 
-	integerChars := []rune(formattedInteger.String())
-	for i, j := 0, len(integerChars)-1; i < j; i, j = i+1, j-1 {
-		integerChars[i], integerChars[j] = integerChars[j], integerChars[i]
+func parseDate(name, value string) time.Time {
+	date, err := models.ParseDate(value, "02.01.2006 15:04:05", "02.01.2006 15:04", "02.01.2006", time.RFC3339)
+	if err != nil {
+		logger.SugaredLogger.Warnf("%s: %v", name, err)
 	}
-
-	return string(integerChars) + "," + decimalPart + " ₽"
+	return date
 }
 
-func createSearchRequest(searchText string, minPrice int, config *models.Config, from, size int) ElasticRequest {
+// createSearchRequest строит запрос к поиску Сбер-АСТ по закупкам, опубликованным не раньше publishedFrom
+func createSearchRequest(searchText string, minPrice int, config *models.Config, publishedFrom time.Time, from, size int) ElasticRequest {
 	// Преобразуем коды ФО в список регионов
 	var regions []string
 	if len(config.VentCustomerPlace) > 0 {
@@ -499,8 +435,7 @@ func createSearchRequest(searchText string, minPrice int, config *models.Config,
 		From: from,
 	}
 
-	twoYearsAgo := time.Now().AddDate(-2, 0, 0)
-	searchRequest.Filters.PublicDate.MinValue = twoYearsAgo.Format("02.01.2006")
+	searchRequest.Filters.PublicDate.MinValue = publishedFrom.Format("02.01.2006")
 
 	return searchRequest
 }
@@ -511,8 +446,12 @@ func mergeTendersWithoutDuplicates(tenderSlices ...[]models.Tender) []models.Ten
 
 	for _, slice := range tenderSlices {
 		for _, tender := range slice {
-			if tender.Title != "" && !seen[tender.Title] {
-				seen[tender.Title] = true
+			key := tender.Number
+			if key == "" {
+				key = tender.Title
+			}
+			if tender.Title != "" && !seen[key] {
+				seen[key] = true
 				result = append(result, tender)
 			}
 		}
@@ -543,7 +482,10 @@ type Hit struct {
 	Source struct {
 		PurchName        string  `json:"purchName"`
 		BidName          string  `json:"BidName"`
+		PurchCodeTerm    string  `json:"purchCodeTerm"`
 		PurchAmount      float64 `json:"purchAmount"`
+		PurchCurrency    string  `json:"purchCurrency"`
+		SourceTerm       string  `json:"SourceTerm"`
 		PublicDate       string  `json:"PublicDate"`
 		EndDate          string  `json:"EndDate"`
 		OrgName          string  `json:"OrgName"`
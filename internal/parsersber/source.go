@@ -0,0 +1,34 @@
+package parsersber
+
+import (
+	"context"
+
+	"tendertracker/internal/models"
+	"tendertracker/internal/sources"
+)
+
+// Source - площадка Сбер-АСТ
+type Source struct {
+	settings sources.Settings
+}
+
+func NewSource(settings sources.Settings) *Source {
+	return &Source{settings: settings.WithFetcher(Host)}
+}
+
+func (s *Source) Name() string {
+	return SourceName
+}
+
+func (s *Source) Title() string {
+	return "Сбер-АСТ"
+}
+
+func (s *Source) Search(ctx context.Context, query sources.Query) ([]models.Tender, error) {
+	return ParseSberAst(ctx, s.settings, query)
+}
+
+// Documents возвращает вложения со страницы процедуры
+func (s *Source) Documents(ctx context.Context, tender models.Tender) ([]models.Document, error) {
+	return NewParser(s.settings).fetchDocuments(ctx, tender.Link)
+}
@@ -0,0 +1,240 @@
+package reports
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrNotFound - отчета с таким идентификатором нет в хранилище
+var ErrNotFound = errors.New("отчет не найден")
+
+// Идентификатор отчета: дата, время и случайный суффикс, например 20240131-094500-1a2b3c4d
+var idPattern = regexp.MustCompile(`^\d{8}-\d{6}-[0-9a-f]{8}$`)
+
+// Report - сохраненный отчет поиска
+type Report struct {
+	ID        string         `json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	Config    models.Config  `json:"config"`
+	Stats     map[string]int `json:"stats"`
+	Partial   bool           `json:"partial,omitempty"`
+	// Filename - имя файла, под которым отчет отдается пользователю
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+}
+
+// Store хранит файлы отчетов и их параметры в отдельном каталоге
+type Store struct {
+	mu  sync.Mutex
+	dir string
+	// retention - сколько хранить отчеты, 0 - бессрочно
+	retention time.Duration
+}
+
+// Open открывает (или создает) каталог отчетов и удаляет устаревшие
+func Open(dir string, retention time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога отчетов: %w", err)
+	}
+
+	store := &Store{dir: dir, retention: retention}
+	store.Prune(time.Now())
+
+	return store, nil
+}
+
+// Filename - имя файла отчета для пользователя: Закупки_<дата>_<время>.xlsx
+func Filename(now time.Time) string {
+	return "Закупки_" + now.In(models.Moscow).Format("2006-01-02_1504") + ".xlsx"
+}
+
+// Save записывает книгу Excel под новым идентификатором
+func (s *Store) Save(file *excelize.File, config models.Config, stats map[string]int, partial bool) (*Report, error) {
+	now := time.Now()
+
+	report := &Report{
+		ID:        newID(now),
+		CreatedAt: now,
+		Config:    config,
+		Stats:     stats,
+		Partial:   partial,
+		Filename:  Filename(now),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := file.SaveAs(s.filePath(report.ID)); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения отчета: %w", err)
+	}
+
+	if info, err := os.Stat(s.filePath(report.ID)); err == nil {
+		report.Size = info.Size()
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(s.metaPath(report.ID), data, 0o644); err != nil {
+		os.Remove(s.filePath(report.ID))
+		return nil, fmt.Errorf("ошибка сохранения параметров отчета: %w", err)
+	}
+
+	s.pruneLocked(now)
+
+	return report, nil
+}
+
+// Get возвращает параметры отчета и путь к его файлу. Принимаются только
+// идентификаторы, выданные хранилищем
+func (s *Store) Get(id string) (*Report, string, error) {
+	if !idPattern.MatchString(id) {
+		return nil, "", ErrNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report, err := s.readMeta(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	path := s.filePath(id)
+	if _, err := os.Stat(path); err != nil {
+		return nil, "", ErrNotFound
+	}
+
+	return report, path, nil
+}
+
+// List возвращает отчеты, начиная с последних
+func (s *Store) List() ([]Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids, err := s.ids()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Report, 0, len(ids))
+	for _, id := range ids {
+		report, err := s.readMeta(id)
+		if err != nil {
+			logger.SugaredLogger.Warnf("Отчет %s пропущен: %v", id, err)
+			continue
+		}
+		result = append(result, *report)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+// Prune удаляет отчеты старше срока хранения
+func (s *Store) Prune(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(now)
+}
+
+func (s *Store) pruneLocked(now time.Time) {
+	if s.retention <= 0 {
+		return
+	}
+
+	ids, err := s.ids()
+	if err != nil {
+		logger.SugaredLogger.Warnf("Ошибка чтения каталога отчетов: %v", err)
+		return
+	}
+
+	removed := 0
+	for _, id := range ids {
+		created, err := time.Parse("20060102-150405", id[:15])
+		if err != nil || now.Sub(created) <= s.retention {
+			continue
+		}
+
+		os.Remove(s.filePath(id))
+		os.Remove(s.metaPath(id))
+		removed++
+	}
+
+	if removed > 0 {
+		logger.SugaredLogger.Infof("Удалено устаревших отчетов: %d", removed)
+	}
+}
+
+// ids возвращает идентификаторы отчетов, для которых есть параметры
+func (s *Store) ids() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if ok && idPattern.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (s *Store) readMeta(id string) (*Report, error) {
+	data, err := os.ReadFile(s.metaPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("ошибка чтения параметров отчета %s: %w", id, err)
+	}
+
+	return &report, nil
+}
+
+func (s *Store) filePath(id string) string {
+	return filepath.Join(s.dir, id+".xlsx")
+}
+
+func (s *Store) metaPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// newID строится по UTC, чтобы срок хранения считался по имени файла
+func newID(now time.Time) string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return now.UTC().Format("20060102-150405") + fmt.Sprintf("-%08x", now.Nanosecond())
+	}
+	return now.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(buf)
+}
@@ -0,0 +1,135 @@
+package reports
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func openTestStore(t *testing.T, retention time.Duration) *Store {
+	t.Helper()
+	logger.InitLogger("error")
+
+	store, err := Open(filepath.Join(t.TempDir(), "reports"), retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// writeReport кладет в каталог файлы отчета с заданным идентификатором
+func writeReport(t *testing.T, store *Store, id string) {
+	t.Helper()
+
+	if err := os.WriteFile(store.filePath(id), []byte("xlsx"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(store.metaPath(id), []byte(`{"id":"`+id+`"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetValidatesID(t *testing.T) {
+	store := openTestStore(t, 0)
+
+	report, err := store.Save(excelize.NewFile(), models.Config{}, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Файлы рядом с каталогом отчетов и по абсолютному пути: ни один не
+	// должен отдаваться через Get
+	parent := filepath.Dir(store.dir)
+	writeReportAt(t, parent, "secret")
+	absolute := filepath.Join(parent, "secret")
+	// Файлы с заглавными буквами в суффиксе хранилище не выдает
+	writeReport(t, store, "20240131-094500-1A2B3C4D")
+
+	tests := []struct {
+		name string
+		id   string
+		ok   bool
+	}{
+		{"saved", report.ID, true},
+		{"valid format, missing", "20240131-094500-1a2b3c4d", false},
+		{"uppercase hex", "20240131-094500-1A2B3C4D", false},
+		{"parent directory", "../secret", false},
+		{"nested traversal", report.ID + "/../../secret", false},
+		{"absolute path", absolute, false},
+		{"trailing newline", report.ID + "\n", false},
+		{"short suffix", report.ID[:len(report.ID)-1], false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		got, path, err := store.Get(tt.id)
+		if !tt.ok {
+			if !errors.Is(err, ErrNotFound) {
+				t.Errorf("%s: Get(%q) = %v, %q, %v, want ErrNotFound", tt.name, tt.id, got, path, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Get(%q): %v", tt.name, tt.id, err)
+			continue
+		}
+		if filepath.Dir(path) != store.dir {
+			t.Errorf("%s: Get(%q) path %q outside %q", tt.name, tt.id, path, store.dir)
+		}
+	}
+}
+
+func writeReportAt(t *testing.T, dir, name string) {
+	t.Helper()
+
+	for _, ext := range []string{".xlsx", ".json"} {
+		if err := os.WriteFile(filepath.Join(dir, name+ext), []byte(`{}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		retention time.Duration
+		id        string
+		kept      bool
+	}{
+		{"fresh", 24 * time.Hour, "20240301-060000-0000000a", true},
+		{"on the boundary", 24 * time.Hour, "20240229-120000-0000000b", true},
+		{"expired", 24 * time.Hour, "20240229-115959-0000000c", false},
+		{"unlimited retention", 0, "20200101-000000-0000000d", true},
+	}
+
+	for _, tt := range tests {
+		store := openTestStore(t, tt.retention)
+		writeReport(t, store, tt.id)
+		// Посторонние файлы в каталоге не трогаются
+		foreign := filepath.Join(store.dir, "notes.json")
+		if err := os.WriteFile(foreign, []byte(`{}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		store.Prune(now)
+
+		for _, path := range []string{store.filePath(tt.id), store.metaPath(tt.id)} {
+			_, err := os.Stat(path)
+			if kept := err == nil; kept != tt.kept {
+				t.Errorf("%s: %s kept = %v, want %v", tt.name, filepath.Base(path), kept, tt.kept)
+			}
+		}
+		if _, err := os.Stat(foreign); err != nil {
+			t.Errorf("%s: foreign file removed: %v", tt.name, err)
+		}
+	}
+}
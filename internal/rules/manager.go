@@ -0,0 +1,240 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tendertracker/internal/categories"
+	"tendertracker/internal/logger"
+)
+
+// FileAuthor - автор версий, найденных при изменении файла на диске
+const FileAuthor = "файл"
+
+// Version - сохраненная версия файла правил
+type Version struct {
+	ID        int       `json:"id"`
+	Text      string    `json:"text"`
+	Author    string    `json:"author"`
+	Comment   string    `json:"comment,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// History - журнал версий правил, его ведет storage.Store
+type History interface {
+	SaveRulesVersion(version *Version) error
+	RulesVersions(limit int) ([]Version, error)
+}
+
+// Manager хранит действующие правила и подменяет их при изменении файла
+// или сохранении из веб-интерфейса. Поиски, которые уже идут, получают
+// новые правила со следующей проверенной карточки
+type Manager struct {
+	path string
+	list categories.List
+	// History - журнал версий, nil - без журнала (консольный поиск)
+	History History
+
+	current atomic.Pointer[Set]
+
+	// mu упорядочивает сохранение и перечитывание файла
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// NewManager загружает правила из файла
+func NewManager(path string, list categories.List) (*Manager, error) {
+	m := &Manager{path: path, list: list}
+
+	set, err := Load(path, list)
+	if err != nil {
+		return nil, err
+	}
+	m.current.Store(set)
+	m.modTime, m.size = m.stat()
+
+	return m, nil
+}
+
+// Path - файл правил
+func (m *Manager) Path() string {
+	return m.path
+}
+
+// Current возвращает действующие правила
+func (m *Manager) Current() *Set {
+	if m == nil {
+		return nil
+	}
+	return m.current.Load()
+}
+
+// Check проверяет название по действующим правилам
+func (m *Manager) Check(category, title string) Decision {
+	return m.Current().Check(category, title)
+}
+
+// Text возвращает текст файла правил, пустой, если файла нет
+func (m *Manager) Text() (string, error) {
+	data, err := os.ReadFile(m.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Validate проверяет текст правил так же, как при загрузке файла
+func (m *Manager) Validate(text string) (*Set, error) {
+	return compile(m.path, text, m.list)
+}
+
+// Save проверяет и записывает новый текст правил, подменяет действующие
+// правила и добавляет версию в журнал
+func (m *Manager) Save(text, author, comment string) (*Version, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	set, err := m.Validate(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeFile(m.path, []byte(text)); err != nil {
+		return nil, fmt.Errorf("не удалось записать файл правил: %w", err)
+	}
+	m.current.Store(set)
+	m.modTime, m.size = m.stat()
+
+	logger.SugaredLogger.Infof("Правила отсева сохранены (%s): %d правил", author, len(set.Rules))
+
+	return m.record(text, author, comment)
+}
+
+// Reload перечитывает файл, если он изменился с последней загрузки.
+// При ошибке в файле действующие правила остаются прежними
+func (m *Manager) Reload() (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	modTime, size := m.stat()
+	if modTime.Equal(m.modTime) && size == m.size {
+		return false, nil
+	}
+	// Запоминаем и ошибочный файл, чтобы не сообщать о нем при каждой проверке
+	m.modTime, m.size = modTime, size
+
+	text, err := m.Text()
+	if err != nil {
+		return false, err
+	}
+
+	set, err := m.Validate(text)
+	if err != nil {
+		return false, err
+	}
+	m.current.Store(set)
+
+	if _, err := m.record(text, FileAuthor, ""); err != nil {
+		logger.SugaredLogger.Warnf("Не удалось записать версию правил: %v", err)
+	}
+
+	return true, nil
+}
+
+// Watch проверяет файл правил раз в interval, пока не отменен ctx.
+// Перед этим записывает текущий файл в журнал, если он отличается от последней версии
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) {
+	m.mu.Lock()
+	if text, err := m.Text(); err == nil {
+		if _, err := m.record(text, FileAuthor, ""); err != nil {
+			logger.SugaredLogger.Warnf("Не удалось записать версию правил: %v", err)
+		}
+	}
+	m.mu.Unlock()
+
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			reloaded, err := m.Reload()
+			if err != nil {
+				logger.SugaredLogger.Warnf("Файл правил %s не применен, действуют прежние правила:\n%v", m.path, err)
+				continue
+			}
+			if reloaded {
+				logger.SugaredLogger.Infof("Правила отсева перечитаны из %s: %d правил", m.path, len(m.Current().Rules))
+			}
+		}
+	}()
+}
+
+// record добавляет версию в журнал, если текст отличается от последней версии
+func (m *Manager) record(text, author, comment string) (*Version, error) {
+	if m.History == nil {
+		return nil, nil
+	}
+
+	latest, err := m.History.RulesVersions(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) > 0 && latest[0].Text == text {
+		return &latest[0], nil
+	}
+
+	version := &Version{Text: text, Author: author, Comment: comment}
+	if err := m.History.SaveRulesVersion(version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+func (m *Manager) stat() (time.Time, int64) {
+	info, err := os.Stat(m.path)
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
+
+// writeFile записывает файл через временный файл рядом с ним, чтобы при сбое
+// не остался наполовину записанный файл правил
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,330 @@
+package rules
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"tendertracker/internal/categories"
+)
+
+// Виды правил
+const (
+	Include = "include"
+	Exclude = "exclude"
+)
+
+// Global - раздел правил для всех категорий
+const Global = "*"
+
+// categoriesSource - источник правил include и exclude из файла категорий
+const categoriesSource = "файл категорий"
+
+// Rule - одно правило отсева: ключевое слово или регулярное выражение
+type Rule struct {
+	// Source - файл правил, Line - строка в нем (0 для правил из файла категорий)
+	Source string `json:"source"`
+	Line   int    `json:"line,omitempty"`
+	// Category - ключ категории или Global
+	Category string `json:"category"`
+	Kind     string `json:"kind"`
+	// Pattern - ключевое слово или выражение без ограничивающих "/"
+	Pattern string `json:"pattern"`
+	Regex   bool   `json:"regex"`
+
+	re *regexp.Regexp
+}
+
+func (r *Rule) String() string {
+	pattern := r.Pattern
+	if r.Regex {
+		pattern = "/" + pattern + "/"
+	}
+	if r.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", r.Source, r.Line, pattern)
+	}
+	return fmt.Sprintf("%s: %s.%s: %s", r.Source, r.Category, r.Kind, pattern)
+}
+
+// match проверяет название, приведенное к нижнему регистру
+func (r *Rule) match(title string) bool {
+	if r.Regex {
+		return r.re.MatchString(title)
+	}
+	return strings.Contains(title, r.Pattern)
+}
+
+// Set - правила отсева, общие и по категориям
+type Set struct {
+	Rules []*Rule
+}
+
+// Decision - итог проверки названия
+type Decision struct {
+	Excluded bool
+	// Rule - сработавшее правило exclude. Если название не подошло ни под одно
+	// правило include, Rule пустой
+	Rule *Rule
+}
+
+// Reason - причина отсева для журнала и отчетов
+func (d Decision) Reason() string {
+	if !d.Excluded {
+		return ""
+	}
+	if d.Rule == nil {
+		return "не подходит ни под одно правило include"
+	}
+	return d.Rule.String()
+}
+
+// Check проверяет название закупки по правилам категории и общим правилам.
+// Название отсеивается, если подходит под любое правило exclude или если
+// правила include заданы, но ни одно из них не подходит. Пустой набор
+// пропускает все названия
+func (s *Set) Check(category, title string) Decision {
+	if s == nil {
+		return Decision{}
+	}
+
+	title = normalize(title)
+	hasInclude, included := false, false
+
+	for _, rule := range s.Rules {
+		if rule.Category != Global && rule.Category != category {
+			continue
+		}
+
+		switch rule.Kind {
+		case Exclude:
+			if rule.match(title) {
+				return Decision{Excluded: true, Rule: rule}
+			}
+		case Include:
+			hasInclude = true
+			if !included && rule.match(title) {
+				included = true
+			}
+		}
+	}
+
+	if hasInclude && !included {
+		return Decision{Excluded: true}
+	}
+	return Decision{}
+}
+
+// Load читает файл правил и добавляет к нему include и exclude из файла
+// категорий. Отсутствующий файл правил означает, что правил нет
+func Load(filename string, list categories.List) (*Set, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	return compile(filename, string(data), list)
+}
+
+// compile разбирает текст правил и добавляет правила из файла категорий
+func compile(source, text string, list categories.List) (*Set, error) {
+	set, err := Parse(source, strings.NewReader(text), list)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := set.addCategories(list); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// Parse разбирает правила в формате:
+//
+//	# комментарий
+//	[exclude]                    - общие правила exclude
+//	асфальт                      - ключевое слово, ищется как подстрока без учета регистра
+//	/мост(ов|а)?(?:[^\p{L}]|$)/  - регулярное выражение
+//	[vent include]               - правила include категории vent
+//	вент
+//
+// Граница слова \b в Go учитывает только латинские буквы, поэтому выражения
+// с \b и кириллицей не принимаются: вместо нее пишется (?:^|[^\p{L}]) в начале
+// слова и (?:[^\p{L}]|$) в конце. Ошибки содержат номер строки.
+// Категории проверяются по list
+func Parse(source string, r io.Reader, list categories.List) (*Set, error) {
+	set := &Set{}
+	var errs []error
+	fail := func(line int, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s:%d: %s", source, line, fmt.Sprintf(format, args...)))
+	}
+
+	// skip - раздел с ошибкой в заголовке, его правила не разбираются
+	category, kind, skip := "", "", false
+
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			var err error
+			if !strings.HasSuffix(line, "]") {
+				err = fmt.Errorf("не закрыт заголовок раздела")
+			} else {
+				category, kind, err = parseHeader(strings.TrimSpace(line[1:len(line)-1]), list)
+			}
+			if skip = err != nil; skip {
+				fail(number, "%v", err)
+			}
+			continue
+		}
+
+		if skip {
+			continue
+		}
+		if kind == "" {
+			fail(number, "правило вне раздела, начните файл с [exclude] или [include]")
+			continue
+		}
+
+		rule := &Rule{Source: source, Line: number, Category: category, Kind: kind}
+		if err := rule.parse(line); err != nil {
+			fail(number, "%v", err)
+			continue
+		}
+		set.Rules = append(set.Rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// parseHeader разбирает заголовок "[exclude]" или "[vent include]"
+func parseHeader(header string, list categories.List) (string, string, error) {
+	fields := strings.Fields(header)
+
+	category, kind := Global, ""
+	switch len(fields) {
+	case 1:
+		kind = fields[0]
+	case 2:
+		category, kind = fields[0], fields[1]
+	default:
+		return "", "", fmt.Errorf("некорректный заголовок раздела [%s], нужен [exclude] или [категория exclude]", header)
+	}
+
+	if kind != Include && kind != Exclude {
+		return "", "", fmt.Errorf("неизвестный вид правил %q, допустимо include или exclude", kind)
+	}
+	if category != Global && list.Get(category) == nil {
+		return "", "", fmt.Errorf("неизвестная категория %q, доступны: %s", category, strings.Join(list.Names(), ", "))
+	}
+
+	return category, kind, nil
+}
+
+// parse разбирает ключевое слово или /регулярное выражение/
+func (r *Rule) parse(line string) error {
+	if len(line) < 2 || !strings.HasPrefix(line, "/") || !strings.HasSuffix(line, "/") {
+		r.Pattern = normalize(line)
+		return nil
+	}
+
+	r.Pattern = line[1 : len(line)-1]
+	r.Regex = true
+	return r.compile()
+}
+
+func (r *Rule) compile() error {
+	if r.Pattern == "" {
+		return fmt.Errorf("пустое регулярное выражение")
+	}
+
+	if hasWordBoundary(r.Pattern) && hasNonASCIILetter(r.Pattern) {
+		return fmt.Errorf("\\b в выражении /%s/ не находит границы русских слов, используйте (?:^|[^\\p{L}]) и (?:[^\\p{L}]|$)", r.Pattern)
+	}
+
+	// Названия сравниваются после normalize, где "ё" заменена на "е"
+	re, err := regexp.Compile("(?i)" + yoReplacer.Replace(r.Pattern))
+	if err != nil {
+		return fmt.Errorf("некорректное регулярное выражение: %w", err)
+	}
+
+	// Выражение, которому соответствует пустая строка, отсеет или пропустит все названия
+	if re.MatchString("") {
+		return fmt.Errorf("выражение /%s/ совпадает с пустой строкой", r.Pattern)
+	}
+
+	r.re = re
+	return nil
+}
+
+var yoReplacer = strings.NewReplacer("ё", "е", "Ё", "е")
+
+// hasWordBoundary ищет \b или \B вне экранированной обратной косой черты
+func hasWordBoundary(pattern string) bool {
+	for i := 0; i < len(pattern)-1; i++ {
+		if pattern[i] != '\\' {
+			continue
+		}
+		if pattern[i+1] == 'b' || pattern[i+1] == 'B' {
+			return true
+		}
+		i++
+	}
+	return false
+}
+
+func hasNonASCIILetter(pattern string) bool {
+	for _, r := range pattern {
+		if r > unicode.MaxASCII && unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// addCategories добавляет include и exclude из файла категорий
+func (s *Set) addCategories(list categories.List) error {
+	var errs []error
+
+	for _, category := range list {
+		for _, rule := range []*Rule{
+			{Kind: Include, Pattern: category.Include},
+			{Kind: Exclude, Pattern: category.Exclude},
+		} {
+			if rule.Pattern == "" {
+				continue
+			}
+
+			rule.Source, rule.Category, rule.Regex = categoriesSource, category.Name, true
+			if err := rule.compile(); err != nil {
+				errs = append(errs, fmt.Errorf("категория %q: %s: %w", category.Name, rule.Kind, err))
+				continue
+			}
+			s.Rules = append(s.Rules, rule)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// normalize приводит название к виду для сравнения: нижний регистр, "ё" как "е",
+// одиночные пробелы
+func normalize(title string) string {
+	title = strings.ReplaceAll(strings.ToLower(title), "ё", "е")
+	return strings.Join(strings.Fields(title), " ")
+}
@@ -0,0 +1,167 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"tendertracker/internal/categories"
+)
+
+var testCategories = categories.List{
+	{Name: "vent", Include: "вент|кондицион"},
+	{Name: "doors", Exclude: "гараж"},
+}
+
+const testRules = `# правила для тестов
+[exclude]
+асфальт
+/поставка издел.{0,6}мед/
+/(?:^|[^\p{L}])мост(ов|а)?(?:[^\p{L}]|$)/
+
+[vent exclude]
+Ремонт Ёмкостей
+
+[doors include]
+/\bpvc\b/
+двер
+/ворот(а|ам)/
+`
+
+func TestParse(t *testing.T) {
+	set, err := Parse("rules.txt", strings.NewReader(testRules), testCategories)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Rule{
+		{Source: "rules.txt", Line: 3, Category: Global, Kind: Exclude, Pattern: "асфальт"},
+		{Source: "rules.txt", Line: 4, Category: Global, Kind: Exclude, Pattern: "поставка издел.{0,6}мед", Regex: true},
+		{Source: "rules.txt", Line: 5, Category: Global, Kind: Exclude, Pattern: `(?:^|[^\p{L}])мост(ов|а)?(?:[^\p{L}]|$)`, Regex: true},
+		{Source: "rules.txt", Line: 8, Category: "vent", Kind: Exclude, Pattern: "ремонт емкостей"},
+		{Source: "rules.txt", Line: 11, Category: "doors", Kind: Include, Pattern: `\bpvc\b`, Regex: true},
+		{Source: "rules.txt", Line: 12, Category: "doors", Kind: Include, Pattern: "двер"},
+		{Source: "rules.txt", Line: 13, Category: "doors", Kind: Include, Pattern: "ворот(а|ам)", Regex: true},
+	}
+	if len(set.Rules) != len(want) {
+		t.Fatalf("правил %d, want %d: %v", len(set.Rules), len(want), set.Rules)
+	}
+	for i, rule := range set.Rules {
+		got := *rule
+		got.re = nil
+		if got != want[i] {
+			t.Errorf("правило %d: %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"асфальт", []string{"rules.txt:1:", "вне раздела"}},
+		{"[exclude", []string{"rules.txt:1:", "не закрыт"}},
+		{"[skip]", []string{"неизвестный вид правил"}},
+		{"[roads exclude]", []string{"неизвестная категория", "vent, doors"}},
+		{"[a b c]", []string{"некорректный заголовок"}},
+		{"[exclude]\n/мост(/", []string{"rules.txt:2:", "некорректное регулярное выражение"}},
+		{"[exclude]\n//", []string{"rules.txt:2:", "пустое"}},
+		{"[exclude]\n/(мост)?/", []string{"совпадает с пустой строкой"}},
+		// \b в Go - граница только латинских слов
+		{"[exclude]\n/мост(ов|а)?\\b/", []string{"rules.txt:2:", "не находит границы русских слов"}},
+		{"[exclude]\n/\\Bмост/", []string{"не находит границы русских слов"}},
+		// Правила раздела с ошибкой в заголовке не разбираются, ошибки остальных строк собираются все
+		{"[roads exclude]\n/(/\n[exclude]\n/)/", []string{"rules.txt:1:", "rules.txt:4:"}},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse("rules.txt", strings.NewReader(tt.text), testCategories)
+		if err == nil {
+			t.Errorf("Parse(%q) без ошибки", tt.text)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("Parse(%q) = %q, нет %q", tt.text, err, want)
+			}
+		}
+		if strings.Contains(tt.text, "[roads exclude]\n/(/") && strings.Contains(err.Error(), "rules.txt:2:") {
+			t.Errorf("Parse(%q): разобрано правило раздела с ошибкой: %q", tt.text, err)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	set, err := compile("rules.txt", testRules, testCategories)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		category string
+		title    string
+		excluded bool
+		// reason - подстрока причины отсева
+		reason string
+	}{
+		{"vent", "Поставка вентиляционного оборудования", false, ""},
+		{"vent", "Ремонт АСФАЛЬТОВОГО покрытия и вентиляции", true, "rules.txt:3: асфальт"},
+		{"vent", "Поставка изделий медицинского назначения, вентиляторы", true, "rules.txt:4: /поставка издел.{0,6}мед/"},
+		{"vent", "Вентиляция моста через реку", true, "rules.txt:5:"},
+		{"vent", "Ремонт мостов, вентиляция", true, "rules.txt:5:"},
+		{"vent", "Мост", true, "rules.txt:5:"},
+		{"vent", "Вентиляция мостовой", false, ""},
+		{"vent", "Ремонт  ёмкостей вентиляции", true, "rules.txt:8: ремонт емкостей"},
+		{"vent", "Поставка насосов", true, "не подходит ни под одно правило include"},
+		{"doors", "Ремонт емкостей, замена дверей", false, ""},
+		{"doors", "Ремонт ворота", false, ""},
+		{"doors", "Окна PVC", false, ""},
+		{"doors", "Окна PVCB", true, "не подходит"},
+		{"doors", "Поставка воротников", true, "не подходит"},
+		{"doors", "Двери для гаража", true, "файл категорий: doors.exclude: /гараж/"},
+		{"unknown", "Любая закупка", false, ""},
+		{"unknown", "Укладка асфальта", true, "асфальт"},
+	}
+
+	for _, tt := range tests {
+		decision := set.Check(tt.category, tt.title)
+		if decision.Excluded != tt.excluded || !strings.Contains(decision.Reason(), tt.reason) {
+			t.Errorf("Check(%q, %q) = %v %q, want %v %q", tt.category, tt.title, decision.Excluded, decision.Reason(), tt.excluded, tt.reason)
+		}
+	}
+
+	var empty *Set
+	if decision := empty.Check("vent", "Укладка асфальта"); decision.Excluded {
+		t.Errorf("пустой набор отсеял название: %q", decision.Reason())
+	}
+}
+
+func TestCheckRegexWithYo(t *testing.T) {
+	set, err := compile("rules.txt", "[exclude]\n/объём/\n/[её]мкост/\n/^Ёлк/\n", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Выражение показывается так, как его записали
+	if set.Rules[0].Pattern != "объём" {
+		t.Errorf("Pattern = %q, want %q", set.Rules[0].Pattern, "объём")
+	}
+
+	tests := []struct {
+		title    string
+		excluded bool
+	}{
+		{"Увеличение объёма вентиляции", true},
+		{"Увеличение объема вентиляции", true},
+		{"Поставка ёмкостей", true},
+		{"Поставка емкостей", true},
+		{"Ёлки новогодние", true},
+		{"Елки новогодние", true},
+		{"Поставка вентиляторов", false},
+	}
+
+	for _, tt := range tests {
+		if decision := set.Check("vent", tt.title); decision.Excluded != tt.excluded {
+			t.Errorf("Check(%q) = %v, want %v", tt.title, decision.Excluded, tt.excluded)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule - разобранное расписание в формате cron из пяти полей:
+// минута час день_месяца месяц день_недели
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domAny, dowAny - поле задано как "*"; если ограничены оба поля дней,
+	// достаточно совпадения любого из них, как в cron
+	domAny, dowAny bool
+}
+
+// Сокращения для частых расписаний
+var shortcuts = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 1",
+	"@monthly": "0 0 1 * *",
+}
+
+type fieldBounds struct {
+	name     string
+	min, max int
+}
+
+var bounds = []fieldBounds{
+	{"минута", 0, 59},
+	{"час", 0, 23},
+	{"день месяца", 1, 31},
+	{"месяц", 1, 12},
+	{"день недели", 0, 7},
+}
+
+// ParseSchedule разбирает расписание, например "0 8 * * 1-5" - в 8:00 по будням
+func ParseSchedule(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := shortcuts[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != len(bounds) {
+		return nil, fmt.Errorf("расписание %q: ожидается 5 полей (минута час день месяц день_недели), получено %d", spec, len(fields))
+	}
+
+	values := make([]uint64, len(fields))
+	for i, field := range fields {
+		value, err := parseField(field, bounds[i])
+		if err != nil {
+			return nil, fmt.Errorf("расписание %q: %w", spec, err)
+		}
+		values[i] = value
+	}
+
+	// Воскресенье можно указать как 0 или 7
+	if values[4]&(1<<7) != 0 {
+		values[4] |= 1
+	}
+
+	return &Schedule{
+		minute: values[0],
+		hour:   values[1],
+		dom:    values[2],
+		month:  values[3],
+		dow:    values[4],
+		domAny: fields[2] == "*",
+		dowAny: fields[4] == "*",
+	}, nil
+}
+
+// parseField разбирает поле из списка через запятую: *, N, N-M, */S, N-M/S
+func parseField(field string, b fieldBounds) (uint64, error) {
+	var result uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("%s: некорректный шаг %q", b.name, stepPart)
+			}
+		}
+
+		from, to := b.min, b.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			lo, hi, _ := strings.Cut(rangePart, "-")
+			var err error
+			if from, err = parseValue(lo, b); err != nil {
+				return 0, err
+			}
+			if to, err = parseValue(hi, b); err != nil {
+				return 0, err
+			}
+			if from > to {
+				return 0, fmt.Errorf("%s: некорректный диапазон %q", b.name, rangePart)
+			}
+		default:
+			value, err := parseValue(rangePart, b)
+			if err != nil {
+				return 0, err
+			}
+			from = value
+			if !hasStep {
+				to = value
+			}
+		}
+
+		for value := from; value <= to; value += step {
+			result |= 1 << value
+		}
+	}
+
+	return result, nil
+}
+
+func parseValue(text string, b fieldBounds) (int, error) {
+	value, err := strconv.Atoi(text)
+	if err != nil || value < b.min || value > b.max {
+		return 0, fmt.Errorf("%s: значение %q вне диапазона %d-%d", b.name, text, b.min, b.max)
+	}
+	return value, nil
+}
+
+// Next возвращает ближайшее время запуска строго после after
+// в часовом поясе after
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	// Расписание вроде 31 февраля никогда не сработает, ищем не дальше пяти лет
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domAny || s.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
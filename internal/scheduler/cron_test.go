@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	// 2026-10-16 - пятница
+	after := time.Date(2026, 10, 16, 8, 30, 15, 0, moscow)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"0 8 * * 1-5", time.Date(2026, 10, 19, 8, 0, 0, 0, moscow)},
+		{"*/15 * * * *", time.Date(2026, 10, 16, 8, 45, 0, 0, moscow)},
+		{"31 8 * * *", time.Date(2026, 10, 16, 8, 31, 0, 0, moscow)},
+		{"0 9,18 * * *", time.Date(2026, 10, 16, 9, 0, 0, 0, moscow)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, moscow)},
+		{"0 0 * * 0", time.Date(2026, 10, 18, 0, 0, 0, 0, moscow)},
+		{"@daily", time.Date(2026, 10, 17, 0, 0, 0, 0, moscow)},
+		{"@monthly", time.Date(2026, 11, 1, 0, 0, 0, 0, moscow)},
+		{"0 12 29 2 *", time.Date(2028, 2, 29, 12, 0, 0, 0, moscow)},
+		// Ограничены оба поля дней - достаточно любого: 1 ноября или понедельник
+		{"0 0 1 * 1", time.Date(2026, 10, 19, 0, 0, 0, 0, moscow)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseSchedule(tt.spec)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := schedule.Next(after); !got.Equal(tt.want) {
+			t.Errorf("ParseSchedule(%q).Next() = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@yearly",
+	} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q): ожидалась ошибка", spec)
+		}
+	}
+}
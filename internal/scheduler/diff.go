@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"sort"
+
+	"tendertracker/internal/models"
+	"tendertracker/internal/storage"
+)
+
+// snapshotOf собирает найденные тендеры по ключам storage.TenderID.
+// Тендер из нескольких категорий учитывается в первой
+func snapshotOf(allTenders *models.TendersFromAllSites, config models.Config) storage.Snapshot {
+	snapshot := make(storage.Snapshot)
+
+	for _, site := range allTenders.Sites {
+		// Категории в порядке конфигурации, чтобы выбор первой был стабильным
+		for _, category := range config.Enabled() {
+			for _, tender := range site.Tenders[category] {
+				id := storage.TenderID(site.Name, tender)
+				if _, ok := snapshot[id]; ok {
+					continue
+				}
+
+				snapshot[id] = storage.TenderState{
+					ID:       id,
+					Source:   site.Name,
+					Category: category,
+					Title:    tender.Title,
+					Link:     tender.Link,
+					Customer: tender.Customer,
+					Region:   tender.Region,
+					Currency: tender.Currency,
+					Price:    tender.Price,
+					EndDate:  tender.EndDate,
+				}
+			}
+		}
+	}
+
+	return snapshot
+}
+
+// compare заполняет в run новые, измененные и исчезнувшие тендеры.
+// Исчезнувшие определяются только по полному результату (complete),
+// иначе тендер мог просто не попасть в прерванный поиск
+func compare(run *storage.SearchRun, previous, current storage.Snapshot, config models.Config, complete bool) {
+	run.FirstRun = previous == nil
+	run.Found = len(current)
+	run.New = []storage.TenderState{}
+	run.Changed = []storage.TenderChange{}
+	run.Gone = []storage.TenderState{}
+	run.Summary = make(map[string]map[string]int)
+
+	for _, state := range sortedStates(current) {
+		old, ok := previous[state.ID]
+		if !ok {
+			run.New = append(run.New, state)
+			if run.Summary[state.Category] == nil {
+				run.Summary[state.Category] = make(map[string]int)
+			}
+			run.Summary[state.Category][state.Source]++
+			continue
+		}
+
+		if old.Price != state.Price || !old.EndDate.Equal(state.EndDate) {
+			run.Changed = append(run.Changed, storage.TenderChange{Old: old, New: state})
+		}
+	}
+
+	if !complete {
+		return
+	}
+
+	for _, state := range sortedStates(previous) {
+		if _, ok := current[state.ID]; !ok && config.IsEnabled(state.Category) {
+			run.Gone = append(run.Gone, state)
+		}
+	}
+}
+
+// nextSnapshot - база для следующего запуска. После прерванного поиска
+// прежние тендеры сохраняются, чтобы в следующий раз не считаться новыми
+func nextSnapshot(previous, current storage.Snapshot, config models.Config, complete bool) storage.Snapshot {
+	if complete {
+		return current
+	}
+
+	merged := make(storage.Snapshot, len(previous)+len(current))
+	for id, state := range previous {
+		if config.IsEnabled(state.Category) {
+			merged[id] = state
+		}
+	}
+	for id, state := range current {
+		merged[id] = state
+	}
+
+	return merged
+}
+
+func sortedStates(snapshot storage.Snapshot) []storage.TenderState {
+	states := make([]storage.TenderState, 0, len(snapshot))
+	for _, state := range snapshot {
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].Category != states[j].Category {
+			return states[i].Category < states[j].Category
+		}
+		if states[i].Source != states[j].Source {
+			return states[i].Source < states[j].Source
+		}
+		return states[i].ID < states[j].ID
+	})
+
+	return states
+}
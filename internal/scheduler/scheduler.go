@@ -0,0 +1,202 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tendertracker/internal/jobs"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/search"
+	"tendertracker/internal/storage"
+)
+
+// Notifier сообщает о результатах запуска сохраненного поиска
+type Notifier interface {
+	// Name - название канала для журнала
+	Name() string
+	Notify(ctx context.Context, search storage.SavedSearch, run *storage.SearchRun) error
+}
+
+// Scheduler запускает сохраненные поиски по расписанию. Каждый запуск -
+// обычная задача поиска, ее ход виден через /tender/jobs/{id}
+type Scheduler struct {
+	store    *storage.Store
+	searcher *search.Searcher
+	manager  *jobs.Manager
+
+	notifiers []Notifier
+
+	mu      sync.Mutex
+	running map[string]*jobs.Job
+}
+
+func New(store *storage.Store, searcher *search.Searcher, manager *jobs.Manager) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		searcher: searcher,
+		manager:  manager,
+		running:  make(map[string]*jobs.Job),
+	}
+}
+
+// AddNotifier подключает канал уведомлений о результатах запусков
+func (s *Scheduler) AddNotifier(notifier Notifier) {
+	s.notifiers = append(s.notifiers, notifier)
+}
+
+// Start проверяет расписания раз в минуту, пока не отменен ctx
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		s.tick(time.Now())
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				s.tick(now)
+			}
+		}
+	}()
+}
+
+// tick запускает поиски, время которых подошло. Поиск, пропущенный
+// пока сервис был остановлен, запускается один раз при старте
+func (s *Scheduler) tick(now time.Time) {
+	searches, err := s.store.Searches()
+	if err != nil {
+		logger.SugaredLogger.Warnf("Ошибка чтения сохраненных поисков: %v", err)
+		return
+	}
+
+	for _, saved := range searches {
+		if !saved.Enabled {
+			continue
+		}
+
+		schedule, err := ParseSchedule(saved.Schedule)
+		if err != nil {
+			logger.SugaredLogger.Warnf("Сохраненный поиск %s: %v", saved.Name, err)
+			continue
+		}
+
+		from := saved.LastRun
+		if from.IsZero() {
+			from = saved.CreatedAt
+		}
+
+		next := schedule.Next(from.In(models.Moscow))
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+
+		if _, err := s.Run(saved); err != nil {
+			logger.SugaredLogger.Warnf("Не удалось запустить поиск %s: %v", saved.Name, err)
+		}
+	}
+}
+
+// Run запускает сохраненный поиск. Если он уже выполняется, возвращается текущая задача
+func (s *Scheduler) Run(saved storage.SavedSearch) (*jobs.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if job, ok := s.running[saved.ID]; ok {
+		return job, nil
+	}
+
+	if err := s.store.SetLastRun(saved.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	logger.SugaredLogger.Infof("Запуск сохраненного поиска %s (%s)", saved.Name, saved.ID)
+
+	job := s.manager.Start(saved.Config, func(ctx context.Context, job *jobs.Job) (any, error) {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, saved.ID)
+			s.mu.Unlock()
+		}()
+
+		run, err := s.execute(ctx, saved, job)
+		if err != nil {
+			return nil, err
+		}
+		return run, nil
+	})
+	s.running[saved.ID] = job
+
+	return job, nil
+}
+
+// execute выполняет поиск, сравнивает результат с прошлым запуском и сохраняет итог
+func (s *Scheduler) execute(ctx context.Context, saved storage.SavedSearch, job *jobs.Job) (*storage.SearchRun, error) {
+	run := &storage.SearchRun{
+		SearchID:  saved.ID,
+		StartedAt: time.Now(),
+	}
+
+	config := saved.Config
+	result, report, err := s.searcher.RunReport(ctx, &config, job.Report)
+	if err != nil {
+		run.FinishedAt = time.Now()
+		run.Errors = append(result.Errors, err.Error())
+		if err := s.store.AddRun(run); err != nil {
+			logger.SugaredLogger.Warnf("Не удалось сохранить запуск %s: %v", saved.Name, err)
+		}
+		return nil, err
+	}
+
+	// Поиск могли удалить или изменить, пока он выполнялся
+	latest, err := s.store.GetSearch(saved.ID)
+	if err != nil || latest == nil {
+		return run, err
+	}
+	saved = *latest
+
+	run.ReportID = report.ID
+	run.Partial = result.Partial
+	run.Errors = result.Errors
+
+	previous, err := s.store.Snapshot(saved.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения прошлого запуска: %w", err)
+	}
+
+	complete := !result.Partial && len(result.Errors) == 0
+	current := snapshotOf(result.Tenders, config)
+	compare(run, previous, current, config, complete)
+	run.FinishedAt = time.Now()
+
+	if err := s.store.SaveSnapshot(saved.ID, nextSnapshot(previous, current, config, complete)); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения результатов запуска: %w", err)
+	}
+	if err := s.store.AddRun(run); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения запуска: %w", err)
+	}
+
+	logger.SugaredLogger.Infof("Сохраненный поиск %s: найдено %d, новых %d, изменилось %d, исчезло %d",
+		saved.Name, run.Found, len(run.New), len(run.Changed), len(run.Gone))
+
+	s.notify(ctx, saved, run)
+
+	return run, nil
+}
+
+// notify передает итог запуска всем каналам. Ошибка одного канала
+// не мешает остальным и не делает запуск неудачным
+func (s *Scheduler) notify(ctx context.Context, saved storage.SavedSearch, run *storage.SearchRun) {
+	// Уведомления отправляются и после отмены поиска, по тому, что успели найти
+	ctx = context.WithoutCancel(ctx)
+
+	for _, notifier := range s.notifiers {
+		if err := notifier.Notify(ctx, saved, run); err != nil {
+			logger.SugaredLogger.Warnf("Поиск %s: ошибка уведомления %s: %v", saved.Name, notifier.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"tendertracker/internal/categories"
+	"tendertracker/internal/excel"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/reports"
+	"tendertracker/internal/rules"
+	"tendertracker/internal/sources"
+	"tendertracker/internal/storage"
+)
+
+// Searcher запускает поиск по всем зарегистрированным площадкам
+type Searcher struct {
+	Rules      *rules.Manager
+	Categories categories.List
+	Store      *storage.Store
+	Reports    *reports.Store
+	// Timeout - ограничение времени поиска по умолчанию, 0 - без ограничения
+	Timeout time.Duration
+}
+
+// SourceInfo - площадка в ответе поиска
+type SourceInfo struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+}
+
+// Result - итог поиска по всем площадкам
+type Result struct {
+	Tenders *models.TendersFromAllSites
+	Stats   map[string]int
+	Sources []SourceInfo
+	// New - тендеры, впервые попавшие в базу
+	New    []storage.StoredTender
+	Errors []string
+	// FailedSources - площадки, вернувшие ошибку (возможно, с частью результатов)
+	FailedSources []string
+	// Partial - поиск прерван отменой или по времени, найдено не все
+	Partial bool
+}
+
+type parseResult struct {
+	name    string
+	tenders []models.Tender
+	err     error
+}
+
+type sourceResult struct {
+	site  models.SiteTenders
+	stats map[string]int
+	err   error
+}
+
+// Run ищет выбранные в config категории на всех площадках и сохраняет найденное в базу.
+// События хода поиска передаются в progress (может быть nil). При отмене ctx
+// или по истечении времени поиска возвращается то, что успели собрать
+func (s *Searcher) Run(ctx context.Context, config *models.Config, progress sources.Reporter) *Result {
+	logger.SugaredLogger.Infof("config: %+v", config)
+
+	timeout := s.Timeout
+	if config.TimeoutMinutes > 0 {
+		timeout = time.Duration(config.TimeoutMinutes) * time.Minute
+	}
+
+	searchCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		searchCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	registered := sources.All()
+	results := make([]sourceResult, len(registered))
+
+	// Отсеянные закупки всех площадок для листа "Отфильтровано"
+	var rejectedMu sync.Mutex
+	var rejected []models.Rejection
+	reject := func(rejection models.Rejection) {
+		rejectedMu.Lock()
+		rejected = append(rejected, rejection)
+		rejectedMu.Unlock()
+	}
+
+	var wg sync.WaitGroup
+	for i, source := range registered {
+		wg.Add(1)
+		go func(i int, source sources.TenderSource) {
+			defer wg.Done()
+			logger.SugaredLogger.Infof("Starting %s search...", source.Name())
+
+			tenders, stats, err := s.searchSource(searchCtx, source, config, progress, reject)
+			if err != nil {
+				logger.SugaredLogger.Warnf("%s search error: %v", source.Name(), err)
+			} else {
+				logger.SugaredLogger.Infof("%s search completed", source.Name())
+			}
+
+			results[i] = sourceResult{
+				site: models.SiteTenders{
+					Name:    source.Name(),
+					Title:   source.Title(),
+					Tenders: tenders,
+				},
+				stats: stats,
+				err:   err,
+			}
+		}(i, source)
+	}
+	wg.Wait()
+
+	result := &Result{Tenders: &models.TendersFromAllSites{}}
+
+	switch {
+	case ctx.Err() != nil:
+		result.Partial = true
+		result.Errors = append(result.Errors, "Поиск отменен, результаты неполные")
+	case searchCtx.Err() != nil:
+		result.Partial = true
+		result.Errors = append(result.Errors, fmt.Sprintf("Поиск остановлен по истечении %v, результаты неполные", timeout))
+	}
+	statsBySource := make([]map[string]int, 0, len(results))
+	totalFound := 0
+
+	for _, sr := range results {
+		if sr.err != nil {
+			result.Errors = append(result.Errors, sr.err.Error())
+			result.FailedSources = append(result.FailedSources, sr.site.Name)
+		}
+
+		result.Tenders.Sites = append(result.Tenders.Sites, sr.site)
+		result.Sources = append(result.Sources, SourceInfo{Name: sr.site.Name, Title: sr.site.Title})
+		statsBySource = append(statsBySource, sr.stats)
+		totalFound += sr.stats["totalFound"+sr.site.Name]
+	}
+
+	result.Stats = mergeMaps(statsBySource...)
+	result.Stats["totalFound"] = totalFound
+
+	sortRejected(rejected, result.Sources)
+	result.Tenders.Rejected = rejected
+	result.Stats["totalRejected"] = len(rejected)
+
+	if s.Store != nil {
+		now := time.Now()
+		result.New = saveTenders(s.Store, result.Tenders, now)
+		if err := s.Store.SaveRejected(rejected, now); err != nil {
+			logger.SugaredLogger.Warnf("Failed to save rejected tenders: %v", err)
+		}
+		result.Stats["totalNew"] = len(result.New)
+	}
+
+	logger.SugaredLogger.Infof("Search completed. Total found: %d, partial: %v", totalFound, result.Partial)
+	return result
+}
+
+// RunReport выполняет поиск и сохраняет отчет Excel в хранилище отчетов
+func (s *Searcher) RunReport(ctx context.Context, config *models.Config, progress sources.Reporter) (*Result, *reports.Report, error) {
+	result := s.Run(ctx, config, progress)
+
+	file, err := excel.ToExcel(*config, result.Tenders, s.Categories)
+	if err != nil {
+		return result, nil, fmt.Errorf("Failed to create Excel file: %w", err)
+	}
+
+	report, err := s.Reports.Save(file, *config, result.Stats, result.Partial)
+	if err != nil {
+		return result, nil, fmt.Errorf("Failed to save Excel file: %w", err)
+	}
+
+	return result, report, nil
+}
+
+// searchSource ищет все выбранные категории на одной площадке
+func (s *Searcher) searchSource(ctx context.Context, source sources.TenderSource, config *models.Config, progress sources.Reporter, reject func(models.Rejection)) (models.AllTenders, map[string]int, error) {
+	allTenders := make(models.AllTenders)
+	name := source.Name()
+	stats := map[string]int{
+		"totalFound" + name: 0,
+	}
+
+	var selected []*categories.Category
+	for _, category := range s.Categories {
+		if config.IsEnabled(category.Name) && sources.Supports(source, category) {
+			selected = append(selected, category)
+		}
+	}
+
+	resultChan := make(chan parseResult, len(selected))
+	var wg sync.WaitGroup
+	var errors []string
+
+	for _, category := range selected {
+		wg.Add(1)
+		go func(category *categories.Category) {
+			defer wg.Done()
+
+			query := sources.Query{
+				Category: category,
+				Config:   config,
+				Rules:    s.Rules,
+				Progress: progress,
+				OnReject: reject,
+			}
+
+			query.Report(name, sources.Event{Kind: sources.EventStarted})
+			tenders, err := source.Search(ctx, query)
+			if err != nil {
+				query.Report(name, sources.Event{Kind: sources.EventError, Message: err.Error()})
+			} else {
+				query.Report(name, sources.Event{Kind: sources.EventDone, Kept: len(tenders)})
+			}
+
+			resultChan <- parseResult{name: category.Name, tenders: tenders, err: err}
+		}(category)
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	for result := range resultChan {
+		if result.err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", result.name, result.err))
+			// Площадка могла вернуть часть найденного до ошибки или отмены
+			if len(result.tenders) == 0 {
+				continue
+			}
+		}
+
+		models.SortByPublishDate(result.tenders)
+		allTenders[result.name] = result.tenders
+		stats[result.name+"Found"+name] = len(result.tenders)
+		stats["totalFound"+name] += len(result.tenders)
+	}
+
+	if len(errors) > 0 && stats["totalFound"+name] == 0 {
+		logger.SugaredLogger.Warnf("All parsing attempts failed from %s: %v", name, errors)
+		return allTenders, stats, fmt.Errorf("Error when parsing %s: %v", name, errors)
+	}
+
+	if len(errors) > 0 {
+		logger.SugaredLogger.Warnf("Partial parsing errors (but some tenders found) from %s: %v", name, errors)
+		return allTenders, stats, fmt.Errorf("Error when parsing %s: %v", name, errors)
+	}
+
+	if stats["totalFound"+name] == 0 {
+		logger.SugaredLogger.Warnf("0 tenders found from %s", name)
+	}
+
+	return allTenders, stats, nil
+}
+
+// saveTenders сохраняет результаты поиска в базу и возвращает новые тендеры
+func saveTenders(store *storage.Store, allTenders *models.TendersFromAllSites, now time.Time) []storage.StoredTender {
+	var created []storage.StoredTender
+
+	for _, site := range allTenders.Sites {
+		for category, tenders := range site.Tenders {
+			stored, err := store.Upsert(site.Name, category, tenders, now)
+			if err != nil {
+				logger.SugaredLogger.Warnf("Failed to save %s tenders from %s: %v", category, site.Name, err)
+				continue
+			}
+			created = append(created, stored...)
+		}
+	}
+
+	logger.SugaredLogger.Infof("Saved tenders to store, new: %d", len(created))
+	return created
+}
+
+// sortRejected упорядочивает отсеянные закупки по площадкам в порядке поиска,
+// категориям и названиям
+func sortRejected(rejected []models.Rejection, order []SourceInfo) {
+	index := make(map[string]int, len(order))
+	for i, source := range order {
+		index[source.Name] = i
+	}
+
+	sort.SliceStable(rejected, func(i, j int) bool {
+		a, b := rejected[i], rejected[j]
+		if a.Source != b.Source {
+			return index[a.Source] < index[b.Source]
+		}
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return a.Tender.Title < b.Tender.Title
+	})
+}
+
+func mergeMaps(maps ...map[string]int) map[string]int {
+	result := make(map[string]int)
+
+	for _, m := range maps {
+		for key, value := range m {
+			result[key] += value
+		}
+	}
+
+	return result
+}
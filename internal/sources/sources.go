@@ -0,0 +1,255 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tendertracker/internal/categories"
+	"tendertracker/internal/fetcher"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/rules"
+)
+
+// Query описывает поиск по одной категории на одной площадке
+type Query struct {
+	Category *categories.Category
+	Config   *models.Config
+	// Rules - правила отсева по названию, nil - без отсева
+	Rules *rules.Manager
+	// Progress получает события хода поиска, может быть nil
+	Progress Reporter
+	// OnReject получает отсеянные закупки, может быть nil
+	OnReject func(models.Rejection)
+}
+
+// Виды событий хода поиска
+const (
+	EventStarted = "started"
+	EventPage    = "page"
+	EventRetry   = "retry"
+	EventWarning = "warning"
+	EventDone    = "done"
+	EventError   = "error"
+)
+
+// Event - событие хода поиска по одной категории на одной площадке
+type Event struct {
+	Source   string `json:"source"`
+	Category string `json:"category"`
+	Kind     string `json:"kind"`
+	Page     int    `json:"page,omitempty"`
+	// Cards - карточек на странице, Kept - из них прошли фильтры
+	Cards int `json:"cards,omitempty"`
+	Kept  int `json:"kept,omitempty"`
+	// Attempt - номер неудачной попытки запроса для EventRetry
+	Attempt int    `json:"attempt,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type Reporter func(Event)
+
+// Report отправляет событие площадки source, если у запроса есть получатель
+func (q Query) Report(source string, event Event) {
+	if q.Progress == nil {
+		return
+	}
+
+	event.Source = source
+	if q.Category != nil {
+		event.Category = q.Category.Name
+	}
+	q.Progress(event)
+}
+
+// Reject сообщает об отсеянной закупке площадки source, если у запроса есть получатель
+func (q Query) Reject(source string, tender models.Tender, reason, detail string) {
+	logger.SugaredLogger.Debugf("%s: %s: отменено (%s %s): %s", source, q.categoryName(), reason, detail, tender.Title)
+
+	if q.OnReject == nil {
+		return
+	}
+	q.OnReject(models.Rejection{
+		Source:   source,
+		Category: q.categoryName(),
+		Reason:   reason,
+		Detail:   detail,
+		Tender:   tender,
+	})
+}
+
+// Passes проверяет название закупки по правилам отсева. Отсеянная закупка
+// передается в Reject с правилом, которое ее отсеяло
+func (q Query) Passes(source string, tender models.Tender) bool {
+	decision := q.Rules.Check(q.categoryName(), tender.Title)
+	if !decision.Excluded {
+		return true
+	}
+
+	if decision.Rule == nil {
+		q.Reject(source, tender, models.RejectedByInclude, decision.Reason())
+	} else {
+		q.Reject(source, tender, models.RejectedByRule, decision.Reason())
+	}
+	return false
+}
+
+// PassesMinPrice проверяет цену в копейках по минимальной цене категории в рублях.
+// Цена 0 (не указана) проходит проверку
+func (q Query) PassesMinPrice(source string, tender models.Tender, minPrice int) bool {
+	if tender.Price == 0 || tender.Price >= int64(minPrice)*100 {
+		return true
+	}
+
+	q.Reject(source, tender, models.RejectedByMinPrice, fmt.Sprintf("минимальная цена %d руб.", minPrice))
+	return false
+}
+
+// RetryReporter возвращает обработчик повторов запроса площадки source:
+// повтор пишется в журнал и отправляется в ход поиска. name - метка поиска в журнале
+func (q Query) RetryReporter(source, name string) fetcher.RetryFunc {
+	return func(attempt int, wait time.Duration, err error) {
+		logger.SugaredLogger.Warnf("%s Попытка %d не удалась, повтор через %v: %v", name, attempt, wait.Round(time.Millisecond), err)
+		q.Report(source, Event{Kind: EventRetry, Attempt: attempt, Message: err.Error()})
+	}
+}
+
+func (q Query) categoryName() string {
+	if q.Category == nil {
+		return ""
+	}
+	return q.Category.Name
+}
+
+// TenderSource - площадка, на которой ищутся закупки
+type TenderSource interface {
+	// Name - ключ площадки в статистике (ventFound<Name>, totalFound<Name>)
+	// и в поисковых строках файла категорий
+	Name() string
+	// Title - заголовок площадки в отчете
+	Title() string
+	Search(ctx context.Context, query Query) ([]models.Tender, error)
+}
+
+// DocumentSource - площадка, с которой можно получить список документов закупки
+type DocumentSource interface {
+	TenderSource
+	// Documents возвращает ссылки на файлы документации закупки
+	Documents(ctx context.Context, tender models.Tender) ([]models.Document, error)
+}
+
+// Settings - настройки обращения к площадке
+type Settings struct {
+	// HTTPTimeout - ограничение времени одного запроса
+	HTTPTimeout time.Duration
+	// PageDelay - пауза между страницами выдачи
+	PageDelay time.Duration
+	// MaxPages - предел страниц на одну поисковую строку, 0 - без предела
+	MaxPages int
+	// LookBackDays - за сколько дней назад искать закупки
+	LookBackDays int
+	// RequestsPerSecond, Burst, MaxConcurrent и MaxAttempts - ограничения
+	// запросов к площадке, см. fetcher.Limit
+	RequestsPerSecond float64
+	Burst             int
+	MaxConcurrent     int
+	MaxAttempts       int
+	// Fetcher - общий для всех площадок HTTP-клиент
+	Fetcher *fetcher.Fetcher
+	// DetailWorkers - сколько страниц закупок площадки загружать одновременно
+	// при дополнении найденного, DetailCacheTTL - сколько хранить их разбор в Cache
+	DetailWorkers  int
+	DetailCacheTTL time.Duration
+	// Cache - постоянный кэш, может быть nil
+	Cache Cache
+}
+
+// Cache - постоянный кэш данных площадок, например разобранных страниц извещений
+type Cache interface {
+	// CacheGet читает в value неустаревшее значение key и сообщает, найдено ли оно
+	CacheGet(key string, value any) (bool, error)
+	CachePut(key string, value any, ttl time.Duration) error
+}
+
+// Limit - ограничения запросов к площадке для Fetcher
+func (s Settings) Limit() fetcher.Limit {
+	return fetcher.Limit{
+		RequestsPerSecond: s.RequestsPerSecond,
+		Burst:             s.Burst,
+		MaxConcurrent:     s.MaxConcurrent,
+		Timeout:           s.HTTPTimeout,
+		MaxAttempts:       s.MaxAttempts,
+	}
+}
+
+// WithFetcher регистрирует ограничения площадки с хостом hostname в общем
+// клиенте. Без общего клиента площадка получает собственный
+func (s Settings) WithFetcher(hostname string) Settings {
+	if s.Fetcher == nil {
+		s.Fetcher = fetcher.New()
+	}
+	s.Fetcher.SetLimit(hostname, s.Limit())
+	return s
+}
+
+// LookBackFrom - начало окна поиска
+func (s Settings) LookBackFrom(now time.Time) time.Time {
+	return now.AddDate(0, 0, -s.LookBackDays)
+}
+
+var (
+	mu       sync.RWMutex
+	registry []TenderSource
+)
+
+// Register добавляет площадку в общий список. Порядок регистрации
+// определяет порядок секций в отчете
+func Register(source TenderSource) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	registry = append(registry, source)
+}
+
+// All возвращает зарегистрированные площадки
+func All() []TenderSource {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := make([]TenderSource, len(registry))
+	copy(result, registry)
+	return result
+}
+
+// Find возвращает зарегистрированную площадку по имени или nil
+func Find(name string) TenderSource {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, source := range registry {
+		if source.Name() == name {
+			return source
+		}
+	}
+	return nil
+}
+
+// Supports проверяет, заданы ли для площадки поисковые строки категории
+func Supports(source TenderSource, category *categories.Category) bool {
+	return len(category.SearchStrings(source.Name())) > 0
+}
+
+// Sleep ждет d и прерывается раньше, если ctx отменен
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
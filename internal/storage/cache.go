@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var cacheBucket = []byte("cache")
+
+// cacheEntry - значение кэша со сроком годности
+type cacheEntry struct {
+	ExpiresAt time.Time       `json:"expires_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// CacheGet читает в value неустаревшее значение key и сообщает, найдено ли оно
+func (s *Store) CacheGet(key string, value any) (bool, error) {
+	var data []byte
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if stored := tx.Bucket(cacheBucket).Get([]byte(key)); stored != nil {
+			data = append([]byte(nil), stored...)
+		}
+		return nil
+	})
+	if err != nil || data == nil {
+		return false, err
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return false, err
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(entry.Value, value)
+}
+
+// CachePut сохраняет value под ключом key на время ttl
+func (s *Store) CachePut(key string, value any, ttl time.Duration) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cacheEntry{ExpiresAt: time.Now().Add(ttl), Value: encoded})
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(cacheBucket).Put([]byte(key), data)
+	})
+}
+
+// PruneCache удаляет устаревшие значения кэша и возвращает их число
+func (s *Store) PruneCache(now time.Time) (int, error) {
+	removed := 0
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(cacheBucket)
+
+		var expired [][]byte
+		err := bucket.ForEach(func(key, data []byte) error {
+			var entry cacheEntry
+			if err := json.Unmarshal(data, &entry); err != nil || now.After(entry.ExpiresAt) {
+				expired = append(expired, append([]byte(nil), key...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		removed = len(expired)
+		return nil
+	})
+
+	return removed, err
+}
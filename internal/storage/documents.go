@@ -0,0 +1,345 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"tendertracker/internal/documents"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Индекс документов закупок: тексты файлов хранятся по ключу "закупка\x00путь",
+// а слова - по ключу "слово\x00закупка". Слова отсортированы, поэтому поиск
+// по началу слова ("вентиляц") - проход курсора по соседним ключам
+var (
+	documentTextsBucket = []byte("document_texts")
+	documentWordsBucket = []byte("document_words")
+)
+
+const (
+	// maxWordLength - длиннее слова в индексе обрезаются
+	maxWordLength = 40
+	// snippetRunes - сколько символов показывать вокруг найденного слова
+	snippetRunes = 80
+	// maxSnippets - предел фрагментов одного файла
+	maxSnippets = 3
+)
+
+// ErrEmptyQuery - в запросе поиска по документам нет ни одного слова
+var ErrEmptyQuery = errors.New("в запросе нет слов")
+
+// DocumentQuery - поиск по тексту документов закупок
+type DocumentQuery struct {
+	// Text - слова запроса. Каждое слово ищется как начало слова документа
+	// без учета регистра, закупка подходит, если в ее документах есть все слова
+	Text string
+	// Filter - отбор закупок, Sort, Cursor и Limit в нем не учитываются
+	Filter TenderQuery
+	Limit  int
+}
+
+// DocumentHit - файл закупки с фрагментами текста вокруг найденных слов
+type DocumentHit struct {
+	Path     string   `json:"path"`
+	Name     string   `json:"name"`
+	Hits     int      `json:"hits"`
+	Snippets []string `json:"snippets"`
+}
+
+// DocumentMatch - закупка, в документах которой найдены слова запроса
+type DocumentMatch struct {
+	Tender StoredTender  `json:"tender"`
+	Files  []DocumentHit `json:"files"`
+}
+
+// DocumentResult - результат поиска по документам, сначала последние увиденные закупки
+type DocumentResult struct {
+	Matches []DocumentMatch `json:"matches"`
+	// Total - число подходящих закупок без учета Limit
+	Total int `json:"total"`
+}
+
+// IndexDocuments заменяет тексты документов закупки id в индексе
+func (s *Store) IndexDocuments(id string, texts []documents.Text) error {
+	prefix := []byte(id + "\x00")
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		textsBucket := tx.Bucket(documentTextsBucket)
+		wordsBucket := tx.Bucket(documentWordsBucket)
+
+		// Прежние тексты и их слова
+		var oldKeys [][]byte
+		oldWords := make(map[string]bool)
+		cursor := textsBucket.Cursor()
+		for key, data := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, data = cursor.Next() {
+			oldKeys = append(oldKeys, append([]byte(nil), key...))
+
+			var text documents.Text
+			if err := json.Unmarshal(data, &text); err == nil {
+				for _, word := range indexWords(text.Text) {
+					oldWords[word] = true
+				}
+			}
+		}
+		for _, key := range oldKeys {
+			if err := textsBucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		for word := range oldWords {
+			if err := wordsBucket.Delete(wordKey(word, id)); err != nil {
+				return err
+			}
+		}
+
+		for _, text := range texts {
+			data, err := json.Marshal(text)
+			if err != nil {
+				return err
+			}
+			if err := textsBucket.Put(append(append([]byte(nil), prefix...), text.Path...), data); err != nil {
+				return err
+			}
+			for _, word := range indexWords(text.Text) {
+				if err := wordsBucket.Put(wordKey(word, id), []byte{}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func wordKey(word, id string) []byte {
+	return []byte(word + "\x00" + id)
+}
+
+// SearchDocuments ищет закупки по словам в тексте их документов
+func (s *Store) SearchDocuments(query DocumentQuery) (*DocumentResult, error) {
+	terms := uniqueWords(query.Text)
+	if len(terms) == 0 {
+		return nil, ErrEmptyQuery
+	}
+
+	var found map[string]bool
+	var matched []StoredTender
+	err := s.db.View(func(tx *bolt.Tx) error {
+		wordsCursor := tx.Bucket(documentWordsBucket).Cursor()
+		for _, term := range terms {
+			ids := make(map[string]bool)
+			prefix := []byte(term)
+			for key, _ := wordsCursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = wordsCursor.Next() {
+				if i := bytes.IndexByte(key, 0); i >= 0 && (found == nil || found[string(key[i+1:])]) {
+					ids[string(key[i+1:])] = true
+				}
+			}
+			found = ids
+			if len(found) == 0 {
+				return nil
+			}
+		}
+
+		tenders := tx.Bucket(tendersBucket)
+		for id := range found {
+			data := tenders.Get([]byte(id))
+			if data == nil {
+				continue
+			}
+			var stored StoredTender
+			if err := json.Unmarshal(data, &stored); err != nil {
+				return err
+			}
+			if query.Filter.matches(stored) {
+				matched = append(matched, stored)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].LastSeen.Equal(matched[j].LastSeen) {
+			return matched[i].LastSeen.After(matched[j].LastSeen)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	result := &DocumentResult{Matches: []DocumentMatch{}, Total: len(matched)}
+	if query.Limit > 0 && len(matched) > query.Limit {
+		matched = matched[:query.Limit]
+	}
+
+	// Фрагменты нужны только для закупок страницы
+	for _, stored := range matched {
+		files, err := s.documentHits(stored.ID, terms)
+		if err != nil {
+			return nil, err
+		}
+		result.Matches = append(result.Matches, DocumentMatch{Tender: stored, Files: files})
+	}
+
+	return result, nil
+}
+
+// documentHits находит слова запроса в текстах файлов закупки
+func (s *Store) documentHits(id string, terms []string) ([]DocumentHit, error) {
+	hits := []DocumentHit{}
+	prefix := []byte(id + "\x00")
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(documentTextsBucket).Cursor()
+		for key, data := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, data = cursor.Next() {
+			var text documents.Text
+			if err := json.Unmarshal(data, &text); err != nil {
+				return err
+			}
+
+			count, snippets := findSnippets(text.Text, terms)
+			if count > 0 {
+				hits = append(hits, DocumentHit{Path: text.Path, Name: text.Name, Hits: count, Snippets: snippets})
+			}
+		}
+		return nil
+	})
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Hits > hits[j].Hits
+	})
+	return hits, err
+}
+
+// findSnippets считает вхождения слов запроса в начале слов текста и
+// возвращает фрагменты вокруг первых из них
+func findSnippets(text string, terms []string) (int, []string) {
+	original := []rune(text)
+	normalized := make([]rune, len(original))
+	for i, r := range original {
+		normalized[i] = normalizeRune(r)
+	}
+
+	count := 0
+	snippets := []string{}
+	snippetEnd := -1
+	for i := range normalized {
+		if !isWordRune(normalized[i]) || (i > 0 && isWordRune(normalized[i-1])) {
+			continue
+		}
+
+		for _, term := range terms {
+			if !hasRunePrefix(normalized[i:], term) {
+				continue
+			}
+			count++
+			if len(snippets) < maxSnippets && i >= snippetEnd {
+				var snippet string
+				snippet, snippetEnd = snippetAt(original, i, len([]rune(term)))
+				snippets = append(snippets, snippet)
+			}
+			break
+		}
+	}
+
+	return count, snippets
+}
+
+func hasRunePrefix(runes []rune, prefix string) bool {
+	i := 0
+	for _, r := range prefix {
+		if i >= len(runes) || runes[i] != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// snippetAt вырезает фрагмент вокруг слова, начинающегося с start, по границам
+// слов и возвращает его вместе с концом фрагмента
+func snippetAt(runes []rune, start, length int) (string, int) {
+	from := max(start-snippetRunes/2, 0)
+	to := min(start+length+snippetRunes, len(runes))
+	for from > 0 && from < start && !unicode.IsSpace(runes[from-1]) {
+		from++
+	}
+	for to < len(runes) && to > start+length && !unicode.IsSpace(runes[to]) {
+		to--
+	}
+
+	snippet := strings.Join(strings.Fields(string(runes[from:to])), " ")
+	if from > 0 {
+		snippet = "…" + snippet
+	}
+	if to < len(runes) {
+		snippet += "…"
+	}
+	return snippet, to
+}
+
+// indexWords возвращает различные слова текста в виде для индекса
+func indexWords(text string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, word := range splitWords(text) {
+		if len([]rune(word)) < 2 || seen[word] {
+			continue
+		}
+		seen[word] = true
+		words = append(words, word)
+	}
+	return words
+}
+
+// uniqueWords - слова запроса без повторов
+func uniqueWords(text string) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, word := range splitWords(text) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// splitWords делит текст на слова из букв и цифр в нижнем регистре, ё заменяется на е
+func splitWords(text string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word[:min(len(word), maxWordLength)]))
+			word = word[:0]
+		}
+	}
+
+	for _, r := range text {
+		r = normalizeRune(r)
+		if isWordRune(r) {
+			word = append(word, r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+func normalizeRune(r rune) rune {
+	r = unicode.ToLower(r)
+	if r == 'ё' {
+		return 'е'
+	}
+	return r
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,262 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"tendertracker/internal/districts"
+)
+
+// Поля сортировки тендеров
+const (
+	SortPublishDate = "publish_date"
+	SortEndDate     = "end_date"
+	SortPrice       = "price"
+	SortFirstSeen   = "first_seen"
+	SortLastSeen    = "last_seen"
+)
+
+// ErrInvalidCursor - курсор не от этой выборки или поврежден
+var ErrInvalidCursor = errors.New("некорректный курсор")
+
+// TenderQuery - отбор сохраненных тендеров. Пустые поля не ограничивают выборку
+type TenderQuery struct {
+	Categories []string
+	Sources    []string
+	// Region - подстрока региона без учета регистра
+	Region string
+	// Districts - коды федеральных округов (OKER30...)
+	Districts []string
+	// PriceFrom и PriceTo - границы цены в копейках, 0 - без границы
+	PriceFrom int64
+	PriceTo   int64
+	// Границы дат включительно
+	PublishedFrom time.Time
+	PublishedTo   time.Time
+	EndFrom       time.Time
+	EndTo         time.Time
+	// Text - подстрока названия, заказчика, номера или региона
+	Text string
+
+	Sort string
+	Desc bool
+	// Cursor - значение NextCursor предыдущей страницы
+	Cursor string
+	Limit  int
+}
+
+// TenderPage - страница результатов отбора
+type TenderPage struct {
+	Tenders []StoredTender `json:"tenders"`
+	// Total - число тендеров, подходящих под отбор
+	Total int `json:"total"`
+	// NextCursor - курсор следующей страницы, пусто на последней
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ValidSort проверяет поле сортировки
+func ValidSort(field string) bool {
+	switch field {
+	case SortPublishDate, SortEndDate, SortPrice, SortFirstSeen, SortLastSeen:
+		return true
+	}
+	return false
+}
+
+// Find отбирает тендеры, сортирует их и возвращает страницу после курсора.
+// Курсор хранит значение сортировки и ключ последнего тендера страницы,
+// поэтому новые тендеры не сдвигают уже просмотренные страницы
+func (s *Store) Find(query TenderQuery) (*TenderPage, error) {
+	if query.Sort == "" {
+		query.Sort = SortPublishDate
+	}
+	if !ValidSort(query.Sort) {
+		return nil, fmt.Errorf("неизвестное поле сортировки %q", query.Sort)
+	}
+
+	var after *cursor
+	if query.Cursor != "" {
+		decoded, err := decodeCursor(query.Cursor)
+		if err != nil || decoded.sort != query.Sort || decoded.desc != query.Desc {
+			return nil, ErrInvalidCursor
+		}
+		after = decoded
+	}
+
+	all, err := s.All()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]StoredTender, 0, len(all))
+	for _, stored := range all {
+		if query.matches(stored) {
+			matched = append(matched, stored)
+		}
+	}
+
+	less := func(a, b cursor) bool {
+		if a.value != b.value {
+			return (a.value < b.value) != query.Desc
+		}
+		return a.id < b.id
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return less(cursorOf(matched[i], query), cursorOf(matched[j], query))
+	})
+
+	page := &TenderPage{Tenders: []StoredTender{}, Total: len(matched)}
+
+	start := 0
+	if after != nil {
+		start = sort.Search(len(matched), func(i int) bool {
+			return less(*after, cursorOf(matched[i], query))
+		})
+	}
+
+	end := len(matched)
+	if query.Limit > 0 && start+query.Limit < end {
+		end = start + query.Limit
+		page.NextCursor = cursorOf(matched[end-1], query).encode()
+	}
+
+	page.Tenders = append(page.Tenders, matched[start:end]...)
+	return page, nil
+}
+
+func (q TenderQuery) matches(stored StoredTender) bool {
+	tender := stored.Tender
+
+	if len(q.Sources) > 0 && !contains(q.Sources, stored.Source) {
+		return false
+	}
+
+	if len(q.Categories) > 0 {
+		found := false
+		for _, category := range stored.Categories {
+			if contains(q.Categories, category) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if q.Region != "" && !strings.Contains(strings.ToLower(tender.Region), strings.ToLower(q.Region)) {
+		return false
+	}
+	if len(q.Districts) > 0 && (tender.Region == "" || !districts.Contains(tender.Region, q.Districts)) {
+		return false
+	}
+
+	if q.PriceFrom > 0 && tender.Price < q.PriceFrom {
+		return false
+	}
+	if q.PriceTo > 0 && (tender.Price == 0 || tender.Price > q.PriceTo) {
+		return false
+	}
+
+	if !inRange(tender.PublishDate, q.PublishedFrom, q.PublishedTo) || !inRange(tender.EndDate, q.EndFrom, q.EndTo) {
+		return false
+	}
+
+	if q.Text != "" {
+		text := strings.ToLower(q.Text)
+		found := false
+		for _, field := range []string{tender.Title, tender.Customer, tender.Number, tender.Region} {
+			if strings.Contains(strings.ToLower(field), text) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// inRange проверяет дату по границам. Тендер без даты не проходит заданные границы
+func inRange(value, from, to time.Time) bool {
+	if from.IsZero() && to.IsZero() {
+		return true
+	}
+	if value.IsZero() {
+		return false
+	}
+	return (from.IsZero() || !value.Before(from)) && (to.IsZero() || !value.After(to))
+}
+
+// cursor - позиция в отсортированной выборке
+type cursor struct {
+	sort  string
+	desc  bool
+	value int64
+	id    string
+}
+
+func cursorOf(stored StoredTender, query TenderQuery) cursor {
+	c := cursor{sort: query.Sort, desc: query.Desc, id: stored.ID}
+
+	switch query.Sort {
+	case SortPrice:
+		c.value = stored.Tender.Price
+	case SortEndDate:
+		c.value = timeValue(stored.Tender.EndDate)
+	case SortFirstSeen:
+		c.value = timeValue(stored.FirstSeen)
+	case SortLastSeen:
+		c.value = timeValue(stored.LastSeen)
+	default:
+		c.value = timeValue(stored.Tender.PublishDate)
+	}
+
+	return c
+}
+
+// timeValue - дата для сравнения, тендеры без даты идут в конце по убыванию
+func timeValue(t time.Time) int64 {
+	if t.IsZero() {
+		return math.MinInt64
+	}
+	return t.UnixNano()
+}
+
+func (c cursor) encode() string {
+	order := "asc"
+	if c.desc {
+		order = "desc"
+	}
+	raw := c.sort + "|" + order + "|" + strconv.FormatInt(c.value, 10) + "|" + c.id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(encoded string) (*cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ключ тендера может содержать "|", поэтому он последний
+	parts := strings.SplitN(string(raw), "|", 4)
+	if len(parts) != 4 || (parts[1] != "asc" && parts[1] != "desc") {
+		return nil, ErrInvalidCursor
+	}
+
+	value, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cursor{sort: parts[0], desc: parts[1] == "desc", value: value, id: parts[3]}, nil
+}
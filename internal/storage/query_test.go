@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tendertracker/internal/models"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []cursor{
+		{sort: SortPublishDate, desc: true, value: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC).UnixNano(), id: "sber:0123"},
+		{sort: SortPrice, value: 150000000, id: "govru:0373100000126000001"},
+		{sort: SortEndDate, desc: true, value: math.MinInt64, id: "govru:"},
+		{sort: SortLastSeen, value: -1, id: "sber:a|b|c"},
+	}
+
+	for _, want := range tests {
+		got, err := decodeCursor(want.encode())
+		if err != nil {
+			t.Errorf("decodeCursor(%+v): %v", want, err)
+			continue
+		}
+		if *got != want {
+			t.Errorf("decodeCursor(encode(%+v)) = %+v", want, *got)
+		}
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, encoded := range []string{"!!!", "cHJpY2U", "cHJpY2V8dXB8MXxpZA", "cHJpY2V8YXNjfHh8aWQ"} {
+		if _, err := decodeCursor(encoded); err == nil {
+			t.Errorf("decodeCursor(%q) без ошибки", encoded)
+		}
+	}
+}
+
+func TestFindPages(t *testing.T) {
+	store := openTestStore(t)
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	var tenders []models.Tender
+	for i := range 7 {
+		tenders = append(tenders, models.Tender{
+			Number: fmt.Sprintf("%03d", i),
+			Title:  fmt.Sprintf("Тендер %d", i),
+			// Повторы цены упорядочиваются по ключу
+			Price:       int64(i/2) * 100,
+			PublishDate: now.AddDate(0, 0, -i),
+		})
+	}
+	// Тендер без даты публикации по убыванию идет последним
+	tenders = append(tenders, models.Tender{Number: "nodate", Title: "Без даты"})
+	if _, err := store.Upsert("sber", "Категория", tenders, now); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sort string
+		desc bool
+		want []string
+	}{
+		{SortPublishDate, true, []string{"000", "001", "002", "003", "004", "005", "006", "nodate"}},
+		{SortPrice, false, []string{"000", "001", "nodate", "002", "003", "004", "005", "006"}},
+		{SortPrice, true, []string{"006", "004", "005", "002", "003", "000", "001", "nodate"}},
+	}
+
+	for _, tt := range tests {
+		for _, limit := range []int{1, 3, 8, 10} {
+			query := TenderQuery{Sort: tt.sort, Desc: tt.desc, Limit: limit}
+			var got []string
+			for pages := 0; ; pages++ {
+				if pages > len(tt.want) {
+					t.Fatalf("%s desc=%v limit=%d: страницы не кончаются", tt.sort, tt.desc, limit)
+				}
+				page, err := store.Find(query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if page.Total != len(tt.want) {
+					t.Errorf("Total = %d, want %d", page.Total, len(tt.want))
+				}
+				for _, stored := range page.Tenders {
+					got = append(got, stored.Tender.Number)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				query.Cursor = page.NextCursor
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("%s desc=%v limit=%d: %v, want %v", tt.sort, tt.desc, limit, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestFindCursorStable(t *testing.T) {
+	store := openTestStore(t)
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	var tenders []models.Tender
+	for i := range 4 {
+		tenders = append(tenders, models.Tender{Number: fmt.Sprintf("%03d", i), PublishDate: now.AddDate(0, 0, -i)})
+	}
+	if _, err := store.Upsert("sber", "Категория", tenders, now); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := store.Find(TenderQuery{Sort: SortPublishDate, Desc: true, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Новый тендер в начале выборки не сдвигает следующую страницу
+	fresh := models.Tender{Number: "new", PublishDate: now.AddDate(0, 0, 1)}
+	if _, err := store.Upsert("sber", "Категория", []models.Tender{fresh}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := store.Find(TenderQuery{Sort: SortPublishDate, Desc: true, Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.Tenders) != 2 || next.Tenders[0].Tender.Number != "002" || next.Tenders[1].Tender.Number != "003" {
+		t.Errorf("вторая страница сдвинулась: %+v", next.Tenders)
+	}
+
+	// Курсор другой сортировки не принимается
+	if _, err := store.Find(TenderQuery{Sort: SortPrice, Limit: 2, Cursor: page.NextCursor}); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("курсор другой сортировки: %v, want %v", err, ErrInvalidCursor)
+	}
+}
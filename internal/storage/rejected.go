@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"tendertracker/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Закупки, отсеянные правилами, по ключу "тендер\x00категория". Нужны пробному
+// прогону правил: без них он не видит закупки, которые новые правила пропустят
+var rejectedBucket = []byte("rejected")
+
+// rejectedRetention - сколько хранить отсеянную закупку после последнего поиска, где она встретилась
+const rejectedRetention = 90 * 24 * time.Hour
+
+// RejectedTender - закупка, отсеянная правилами в одной категории
+type RejectedTender struct {
+	ID       string `json:"id"`
+	Source   string `json:"source"`
+	Category string `json:"category"`
+	// Reason и Detail - причина отсева при последнем поиске, см. models.Rejection
+	Reason   string        `json:"reason"`
+	Detail   string        `json:"detail"`
+	LastSeen time.Time     `json:"last_seen"`
+	Tender   models.Tender `json:"tender"`
+}
+
+func rejectedKey(id, category string) []byte {
+	return []byte(id + "\x00" + category)
+}
+
+// SaveRejected сохраняет закупки, отсеянные правилами. Отсев по цене не
+// сохраняется: от правил он не зависит. Заодно удаляются записи, не
+// встречавшиеся дольше rejectedRetention
+func (s *Store) SaveRejected(rejected []models.Rejection, now time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(rejectedBucket)
+
+		for _, rejection := range rejected {
+			if rejection.Reason != models.RejectedByRule && rejection.Reason != models.RejectedByInclude {
+				continue
+			}
+
+			id := TenderID(rejection.Source, rejection.Tender)
+			encoded, err := json.Marshal(RejectedTender{
+				ID:       id,
+				Source:   rejection.Source,
+				Category: rejection.Category,
+				Reason:   rejection.Reason,
+				Detail:   rejection.Detail,
+				LastSeen: now,
+				Tender:   rejection.Tender,
+			})
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(rejectedKey(id, rejection.Category), encoded); err != nil {
+				return err
+			}
+		}
+
+		var expired [][]byte
+		err := bucket.ForEach(func(key, data []byte) error {
+			var stored RejectedTender
+			if err := json.Unmarshal(data, &stored); err != nil || now.Sub(stored.LastSeen) > rejectedRetention {
+				expired = append(expired, append([]byte(nil), key...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AllRejected возвращает сохраненные отсеянные закупки, сначала последние увиденные
+func (s *Store) AllRejected() ([]RejectedTender, error) {
+	var result []RejectedTender
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(rejectedBucket).ForEach(func(_, data []byte) error {
+			var stored RejectedTender
+			if err := json.Unmarshal(data, &stored); err != nil {
+				return err
+			}
+			result = append(result, stored)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastSeen.After(result[j].LastSeen)
+	})
+
+	return result, nil
+}
+
+// deleteRejected удаляет отметку об отсеве закупки id в категории, где она
+// теперь прошла правила
+func deleteRejected(tx *bolt.Tx, id, category string) error {
+	return tx.Bucket(rejectedBucket).Delete(rejectedKey(id, category))
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"tendertracker/internal/models"
+)
+
+func TestSaveRejected(t *testing.T) {
+	store := openTestStore(t)
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	old := models.Tender{Number: "old", Title: "Укладка асфальта"}
+	ruled := models.Tender{Number: "001", Title: "Ремонт мостов"}
+	cheap := models.Tender{Number: "002", Title: "Вентиляция", Price: 100}
+
+	if err := store.SaveRejected([]models.Rejection{
+		{Source: "sber", Category: "vent", Reason: models.RejectedByRule, Detail: "rules.txt:3: асфальт", Tender: old},
+	}, now.Add(-rejectedRetention-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveRejected([]models.Rejection{
+		{Source: "sber", Category: "vent", Reason: models.RejectedByRule, Detail: "rules.txt:5: мост", Tender: ruled},
+		{Source: "sber", Category: "doors", Reason: models.RejectedByInclude, Tender: ruled},
+		{Source: "sber", Category: "vent", Reason: models.RejectedByMinPrice, Tender: cheap},
+	}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// Старая запись удалена, отсев по цене не сохраняется
+	rejected, err := store.AllRejected()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rejected) != 2 {
+		t.Fatalf("отсеянных %d, want 2: %+v", len(rejected), rejected)
+	}
+	for _, item := range rejected {
+		if item.ID != "sber:001" || !item.LastSeen.Equal(now) {
+			t.Errorf("лишняя запись: %+v", item)
+		}
+	}
+
+	// Закупка, прошедшая правила в категории, больше не числится в ней отсеянной
+	if _, err := store.Upsert("sber", "doors", []models.Tender{ruled}, now); err != nil {
+		t.Fatal(err)
+	}
+	rejected, err = store.AllRejected()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rejected) != 1 || rejected[0].Category != "vent" || rejected[0].Detail != "rules.txt:5: мост" {
+		t.Errorf("после Upsert: %+v", rejected)
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"tendertracker/internal/rules"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var rulesVersionsBucket = []byte("rules_versions")
+
+func rulesVersionKey(id int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(id))
+	return key
+}
+
+// SaveRulesVersion добавляет версию правил и присваивает ей следующий номер
+func (s *Store) SaveRulesVersion(version *rules.Version) error {
+	if version.CreatedAt.IsZero() {
+		version.CreatedAt = time.Now()
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(rulesVersionsBucket)
+
+		id, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		version.ID = int(id)
+
+		encoded, err := json.Marshal(version)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(rulesVersionKey(version.ID), encoded)
+	})
+}
+
+// GetRulesVersion возвращает версию правил или nil, если ее нет
+func (s *Store) GetRulesVersion(id int) (*rules.Version, error) {
+	var version *rules.Version
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(rulesVersionsBucket).Get(rulesVersionKey(id))
+		if data == nil {
+			return nil
+		}
+
+		version = &rules.Version{}
+		return json.Unmarshal(data, version)
+	})
+
+	return version, err
+}
+
+// RulesVersions возвращает до limit последних версий правил, начиная с последней
+func (s *Store) RulesVersions(limit int) ([]rules.Version, error) {
+	var result []rules.Version
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(rulesVersionsBucket).Cursor()
+		for key, data := cursor.Last(); key != nil; key, data = cursor.Prev() {
+			if limit > 0 && len(result) >= limit {
+				break
+			}
+
+			var version rules.Version
+			if err := json.Unmarshal(data, &version); err != nil {
+				return err
+			}
+			result = append(result, version)
+		}
+		return nil
+	})
+
+	return result, err
+}
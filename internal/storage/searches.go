@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"tendertracker/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	searchesBucket  = []byte("searches")
+	runsBucket      = []byte("search_runs")
+	snapshotsBucket = []byte("search_snapshots")
+)
+
+// SavedSearch - сохраненный поиск, который планировщик запускает по расписанию
+type SavedSearch struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Schedule - расписание в формате cron: минута час день месяц день_недели
+	Schedule string        `json:"schedule"`
+	Config   models.Config `json:"config"`
+	Enabled  bool          `json:"enabled"`
+	// Emails - получатели дайджеста новых тендеров
+	Emails []string `json:"emails,omitempty"`
+	// AttachReport - прикладывать к дайджесту отчет Excel
+	AttachReport bool      `json:"attach_report,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastRun      time.Time `json:"last_run,omitempty"`
+}
+
+// TenderState - то, что запоминается о тендере между запусками
+type TenderState struct {
+	ID       string    `json:"id"`
+	Source   string    `json:"source"`
+	Category string    `json:"category"`
+	Title    string    `json:"title"`
+	Link     string    `json:"link"`
+	Customer string    `json:"customer"`
+	Region   string    `json:"region"`
+	Currency string    `json:"currency"`
+	Price    int64     `json:"price_kopecks"`
+	EndDate  time.Time `json:"end_date"`
+}
+
+// TenderChange - тендер, у которого изменились цена или срок подачи заявок
+type TenderChange struct {
+	Old TenderState `json:"old"`
+	New TenderState `json:"new"`
+}
+
+// SearchRun - результат одного запуска сохраненного поиска
+type SearchRun struct {
+	SearchID   string    `json:"search_id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	ReportID   string    `json:"report_id,omitempty"`
+	Partial    bool      `json:"partial,omitempty"`
+	Errors     []string  `json:"errors,omitempty"`
+	// FirstRun - сравнивать было не с чем, все найденное считается новым
+	FirstRun bool           `json:"first_run,omitempty"`
+	Found    int            `json:"found"`
+	New      []TenderState  `json:"new"`
+	Changed  []TenderChange `json:"changed"`
+	Gone     []TenderState  `json:"gone"`
+	// Summary - число новых тендеров по категориям и площадкам
+	Summary map[string]map[string]int `json:"summary"`
+}
+
+// Snapshot - тендеры, найденные сохраненным поиском, по ключам TenderID.
+// С ним сравнивается следующий запуск
+type Snapshot map[string]TenderState
+
+// SaveSearch создает или обновляет сохраненный поиск
+func (s *Store) SaveSearch(search *SavedSearch) error {
+	if search.ID == "" {
+		search.ID = newSearchID()
+		search.CreatedAt = time.Now()
+	}
+
+	encoded, err := json.Marshal(search)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(searchesBucket).Put([]byte(search.ID), encoded)
+	})
+}
+
+// GetSearch возвращает сохраненный поиск или nil, если его нет
+func (s *Store) GetSearch(id string) (*SavedSearch, error) {
+	var search *SavedSearch
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(searchesBucket).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+
+		search = &SavedSearch{}
+		return json.Unmarshal(data, search)
+	})
+
+	return search, err
+}
+
+// Searches возвращает сохраненные поиски в порядке создания
+func (s *Store) Searches() ([]SavedSearch, error) {
+	var result []SavedSearch
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(searchesBucket).ForEach(func(_, data []byte) error {
+			var search SavedSearch
+			if err := json.Unmarshal(data, &search); err != nil {
+				return err
+			}
+			result = append(result, search)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+// SetLastRun отмечает время последнего запуска, не трогая остальные поля
+func (s *Store) SetLastRun(id string, at time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(searchesBucket)
+
+		data := bucket.Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("сохраненный поиск %s не найден", id)
+		}
+
+		var search SavedSearch
+		if err := json.Unmarshal(data, &search); err != nil {
+			return err
+		}
+		search.LastRun = at
+
+		encoded, err := json.Marshal(search)
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(id), encoded)
+	})
+}
+
+// DeleteSearch удаляет сохраненный поиск вместе с историей запусков
+func (s *Store) DeleteSearch(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(searchesBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		if err := tx.Bucket(snapshotsBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+
+		prefix := runPrefix(id)
+		cursor := tx.Bucket(runsBucket).Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Seek(prefix) {
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// AddRun сохраняет результат запуска
+func (s *Store) AddRun(run *SearchRun) error {
+	encoded, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	key := append(runPrefix(run.SearchID), []byte(run.StartedAt.UTC().Format("20060102T150405.000000000"))...)
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(runsBucket).Put(key, encoded)
+	})
+}
+
+// LastRun возвращает последний запуск поиска или nil, если запусков не было
+func (s *Store) LastRun(searchID string) (*SearchRun, error) {
+	runs, err := s.Runs(searchID, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// Runs возвращает до limit последних запусков поиска, начиная с последнего
+func (s *Store) Runs(searchID string, limit int) ([]SearchRun, error) {
+	var result []SearchRun
+	prefix := runPrefix(searchID)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(runsBucket).Cursor()
+
+		// Переходим за последний ключ с префиксом и идем назад
+		key, data := cursor.Seek(append(append([]byte{}, prefix...), 0xFF))
+		if key == nil {
+			key, data = cursor.Last()
+		} else {
+			key, data = cursor.Prev()
+		}
+
+		for ; key != nil && bytes.HasPrefix(key, prefix); key, data = cursor.Prev() {
+			if limit > 0 && len(result) >= limit {
+				break
+			}
+
+			var run SearchRun
+			if err := json.Unmarshal(data, &run); err != nil {
+				return fmt.Errorf("ошибка чтения запуска %s: %w", key, err)
+			}
+			result = append(result, run)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// Snapshot возвращает тендеры последнего запуска или nil, если запусков не было
+func (s *Store) Snapshot(searchID string) (Snapshot, error) {
+	var snapshot Snapshot
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(snapshotsBucket).Get([]byte(searchID))
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, &snapshot)
+	})
+
+	return snapshot, err
+}
+
+// SaveSnapshot запоминает тендеры запуска для сравнения со следующим
+func (s *Store) SaveSnapshot(searchID string, snapshot Snapshot) error {
+	encoded, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).Put([]byte(searchID), encoded)
+	})
+}
+
+func runPrefix(searchID string) []byte {
+	return []byte(searchID + "/")
+}
+
+func newSearchID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405")
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var sentBucket = []byte("sent")
+
+// sentRetention - сколько хранить отметку об отправке. Отметка удаляется, только
+// если и сам тендер не встречался в поиске столько же: иначе он ушел бы повторно
+const sentRetention = 90 * 24 * time.Hour
+
+// sentKey - ключ отметки об отправке: канал, получатель, тендер
+func sentKey(channel, recipient, tenderID string) []byte {
+	return []byte(channel + "\x00" + recipient + "\x00" + tenderID)
+}
+
+// Unsent возвращает тендеры, которые еще не отправлялись получателю по каналу
+func (s *Store) Unsent(channel, recipient string, tenders []TenderState) ([]TenderState, error) {
+	var result []TenderState
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(sentBucket)
+		for _, tender := range tenders {
+			if bucket.Get(sentKey(channel, recipient, tender.ID)) == nil {
+				result = append(result, tender)
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// MarkSent отмечает тендеры как отправленные получателю. Заодно удаляются
+// отметки старше sentRetention
+func (s *Store) MarkSent(channel, recipient string, tenders []TenderState, at time.Time) error {
+	value := []byte(at.UTC().Format(time.RFC3339))
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(sentBucket)
+		for _, tender := range tenders {
+			if err := bucket.Put(sentKey(channel, recipient, tender.ID), value); err != nil {
+				return err
+			}
+		}
+		return pruneSent(tx, at)
+	})
+}
+
+// pruneSent удаляет отметки, отправленные дольше sentRetention назад, о
+// тендерах, которые с тех пор не встречались в поиске
+func pruneSent(tx *bolt.Tx, now time.Time) error {
+	bucket := tx.Bucket(sentBucket)
+	tenders := tx.Bucket(tendersBucket)
+
+	var expired [][]byte
+	err := bucket.ForEach(func(key, value []byte) error {
+		sentAt, err := time.Parse(time.RFC3339, string(value))
+		if err == nil && now.Sub(sentAt) <= sentRetention {
+			return nil
+		}
+
+		if index := bytes.LastIndexByte(key, 0); index >= 0 {
+			if data := tenders.Get(key[index+1:]); data != nil {
+				var stored StoredTender
+				if err := json.Unmarshal(data, &stored); err == nil && now.Sub(stored.LastSeen) <= sentRetention {
+					return nil
+				}
+			}
+		}
+
+		expired = append(expired, append([]byte(nil), key...))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range expired {
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"tendertracker/internal/models"
+)
+
+func TestMarkSentPrunesOldMarks(t *testing.T) {
+	store := openTestStore(t)
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	old := now.Add(-sentRetention - time.Hour)
+
+	// Тендер 001 давно не встречался, 002 встретился недавно, 003 в базе нет
+	if _, err := store.Upsert("sber", "vent", []models.Tender{{Number: "001"}}, old); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Upsert("sber", "vent", []models.Tender{{Number: "002"}}, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := []TenderState{{ID: "sber:001"}, {ID: "sber:002"}, {ID: "sber:003"}}
+	if err := store.MarkSent("email", "a@example.com", stale, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.MarkSent("email", "b@example.com", []TenderState{{ID: "sber:001"}}, now.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Следующая отправка удаляет устаревшие отметки
+	if err := store.MarkSent("telegram", "42", []TenderState{{ID: "sber:004"}}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		channel   string
+		recipient string
+		id        string
+		sent      bool
+	}{
+		{"email", "a@example.com", "sber:001", false},
+		// Тендер еще встречается в поиске: отметка нужна, чтобы не отправить его снова
+		{"email", "a@example.com", "sber:002", true},
+		{"email", "a@example.com", "sber:003", false},
+		{"email", "b@example.com", "sber:001", true},
+		{"telegram", "42", "sber:004", true},
+	}
+
+	for _, tt := range tests {
+		unsent, err := store.Unsent(tt.channel, tt.recipient, []TenderState{{ID: tt.id}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sent := len(unsent) == 0; sent != tt.sent {
+			t.Errorf("%s/%s/%s sent = %v, want %v", tt.channel, tt.recipient, tt.id, sent, tt.sent)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"tendertracker/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var tendersBucket = []byte("tenders")
+
+// Store - встроенная база найденных тендеров
+type Store struct {
+	db *bolt.DB
+}
+
+// StoredTender - тендер с историей появления в поиске
+type StoredTender struct {
+	ID         string        `json:"id"`
+	Source     string        `json:"source"`
+	Categories []string      `json:"categories"`
+	FirstSeen  time.Time     `json:"first_seen"`
+	LastSeen   time.Time     `json:"last_seen"`
+	Tender     models.Tender `json:"tender"`
+}
+
+// Open открывает (или создает) базу по указанному пути
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога базы: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия базы %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range [][]byte{tendersBucket, searchesBucket, runsBucket, snapshotsBucket, sentBucket, subscriptionsBucket, webhooksBucket, deliveriesBucket, rulesVersionsBucket, cacheBucket, documentTextsBucket, documentWordsBucket, rejectedBucket} {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка инициализации базы: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// TenderID возвращает стабильный ключ тендера: площадка + реестровый номер.
+// Если площадка не отдала номер, он берется из ссылки на извещение,
+// а в крайнем случае ключом служит сама ссылка
+func TenderID(source string, tender models.Tender) string {
+	number := tender.Number
+	if number == "" {
+		number = registryNumber(tender.Link)
+	}
+	if number == "" {
+		number = tender.Link
+	}
+	if number == "" {
+		number = tender.Title
+	}
+	return source + ":" + number
+}
+
+func registryNumber(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || link == "" {
+		return ""
+	}
+
+	query := u.Query()
+	for _, param := range []string{"regNumber", "noticeInfoId", "purchId", "PurchId", "id"} {
+		if value := query.Get(param); value != "" {
+			return value
+		}
+	}
+
+	// Ссылки вида /requests/public/buy/<id>
+	if segments := strings.Split(strings.Trim(u.Path, "/"), "/"); len(segments) > 0 {
+		last := segments[len(segments)-1]
+		if last != "" && !strings.Contains(last, ".") {
+			return last
+		}
+	}
+
+	return ""
+}
+
+// Upsert сохраняет найденные тендеры и возвращает те, что встретились впервые
+func (s *Store) Upsert(source, category string, tenders []models.Tender, now time.Time) ([]StoredTender, error) {
+	var created []StoredTender
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(tendersBucket)
+
+		for _, tender := range tenders {
+			id := TenderID(source, tender)
+
+			var stored StoredTender
+			data := bucket.Get([]byte(id))
+			isNew := data == nil
+
+			if isNew {
+				stored = StoredTender{
+					ID:        id,
+					Source:    source,
+					FirstSeen: now,
+				}
+			} else if err := json.Unmarshal(data, &stored); err != nil {
+				return fmt.Errorf("ошибка чтения тендера %s: %w", id, err)
+			}
+
+			stored.LastSeen = now
+			// Поиск без дополнения извещений не стирает сведения, найденные раньше
+			if tender.Details == nil && stored.Tender.Details != nil {
+				tender.Details = stored.Tender.Details
+			}
+			stored.Tender = tender
+			if !contains(stored.Categories, category) {
+				stored.Categories = append(stored.Categories, category)
+			}
+			if err := deleteRejected(tx, id, category); err != nil {
+				return err
+			}
+
+			encoded, err := json.Marshal(stored)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(id), encoded); err != nil {
+				return err
+			}
+
+			if isNew {
+				created = append(created, stored)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// Get возвращает тендер по ключу или nil, если его нет
+func (s *Store) Get(id string) (*StoredTender, error) {
+	var stored *StoredTender
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(tendersBucket).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+
+		stored = &StoredTender{}
+		return json.Unmarshal(data, stored)
+	})
+
+	return stored, err
+}
+
+// All возвращает все сохраненные тендеры, начиная с последних увиденных
+func (s *Store) All() ([]StoredTender, error) {
+	var result []StoredTender
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(tendersBucket).ForEach(func(_, data []byte) error {
+			var stored StoredTender
+			if err := json.Unmarshal(data, &stored); err != nil {
+				return err
+			}
+			result = append(result, stored)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].LastSeen.After(result[j].LastSeen)
+	})
+
+	return result, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
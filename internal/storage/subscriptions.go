@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var subscriptionsBucket = []byte("telegram_subscriptions")
+
+// Subscription - подписка чата Telegram на новые тендеры
+type Subscription struct {
+	ChatID     int64    `json:"chat_id"`
+	Categories []string `json:"categories"`
+	// Districts - коды федеральных округов (OKER30...), пусто - все регионы
+	Districts []string  `json:"districts,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func subscriptionKey(chatID int64) []byte {
+	return []byte(strconv.FormatInt(chatID, 10))
+}
+
+// SaveSubscription создает или обновляет подписку чата
+func (s *Store) SaveSubscription(subscription *Subscription) error {
+	if subscription.CreatedAt.IsZero() {
+		subscription.CreatedAt = time.Now()
+	}
+
+	encoded, err := json.Marshal(subscription)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(subscriptionsBucket).Put(subscriptionKey(subscription.ChatID), encoded)
+	})
+}
+
+// GetSubscription возвращает подписку чата или nil, если ее нет
+func (s *Store) GetSubscription(chatID int64) (*Subscription, error) {
+	var subscription *Subscription
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(subscriptionsBucket).Get(subscriptionKey(chatID))
+		if data == nil {
+			return nil
+		}
+
+		subscription = &Subscription{}
+		return json.Unmarshal(data, subscription)
+	})
+
+	return subscription, err
+}
+
+// DeleteSubscription удаляет подписку чата
+func (s *Store) DeleteSubscription(chatID int64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(subscriptionsBucket).Delete(subscriptionKey(chatID))
+	})
+}
+
+// Subscriptions возвращает все подписки в порядке создания
+func (s *Store) Subscriptions() ([]Subscription, error) {
+	var result []Subscription
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(subscriptionsBucket).ForEach(func(_, data []byte) error {
+			var subscription Subscription
+			if err := json.Unmarshal(data, &subscription); err != nil {
+				return err
+			}
+			result = append(result, subscription)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
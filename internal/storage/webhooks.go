@@ -0,0 +1,213 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	webhooksBucket   = []byte("webhooks")
+	deliveriesBucket = []byte("webhook_deliveries")
+)
+
+// maxDeliveries - сколько последних доставок хранится для каждого вебхука
+const maxDeliveries = 200
+
+// Webhook - адрес, на который отправляются новые тендеры сохраненных поисков
+type Webhook struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Secret - ключ подписи HMAC-SHA256
+	Secret  string `json:"secret,omitempty"`
+	Enabled bool   `json:"enabled"`
+	// SearchIDs - сохраненные поиски, о которых сообщать, пусто - обо всех
+	SearchIDs []string  `json:"search_ids,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Статусы доставки
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// DeliveryAttempt - одна попытка отправки
+type DeliveryAttempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// Delivery - запись журнала доставки вебхука
+type Delivery struct {
+	ID         string            `json:"id"`
+	WebhookID  string            `json:"webhook_id"`
+	Event      string            `json:"event"`
+	SearchID   string            `json:"search_id,omitempty"`
+	Tenders    int               `json:"tenders"`
+	Status     string            `json:"status"`
+	Attempts   []DeliveryAttempt `json:"attempts"`
+	CreatedAt  time.Time         `json:"created_at"`
+	FinishedAt time.Time         `json:"finished_at,omitempty"`
+}
+
+// SaveWebhook создает или обновляет вебхук
+func (s *Store) SaveWebhook(webhook *Webhook) error {
+	if webhook.ID == "" {
+		webhook.ID = newSearchID()
+		webhook.CreatedAt = time.Now()
+	}
+
+	encoded, err := json.Marshal(webhook)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(webhooksBucket).Put([]byte(webhook.ID), encoded)
+	})
+}
+
+// GetWebhook возвращает вебхук или nil, если его нет
+func (s *Store) GetWebhook(id string) (*Webhook, error) {
+	var webhook *Webhook
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(webhooksBucket).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+
+		webhook = &Webhook{}
+		return json.Unmarshal(data, webhook)
+	})
+
+	return webhook, err
+}
+
+// Webhooks возвращает вебхуки в порядке создания
+func (s *Store) Webhooks() ([]Webhook, error) {
+	var result []Webhook
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(webhooksBucket).ForEach(func(_, data []byte) error {
+			var webhook Webhook
+			if err := json.Unmarshal(data, &webhook); err != nil {
+				return err
+			}
+			result = append(result, webhook)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result, nil
+}
+
+// DeleteWebhook удаляет вебхук вместе с журналом доставок
+func (s *Store) DeleteWebhook(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(webhooksBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+
+		prefix := runPrefix(id)
+		cursor := tx.Bucket(deliveriesBucket).Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Seek(prefix) {
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func deliveryKey(delivery *Delivery) []byte {
+	return append(runPrefix(delivery.WebhookID),
+		[]byte(delivery.CreatedAt.UTC().Format("20060102T150405.000000000")+"/"+delivery.ID)...)
+}
+
+// SaveDelivery создает или обновляет запись журнала. Старые записи
+// сверх maxDeliveries удаляются
+func (s *Store) SaveDelivery(delivery *Delivery) error {
+	if delivery.ID == "" {
+		delivery.ID = newSearchID()
+		delivery.CreatedAt = time.Now()
+	}
+
+	encoded, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(deliveriesBucket)
+		if err := bucket.Put(deliveryKey(delivery), encoded); err != nil {
+			return err
+		}
+
+		prefix := runPrefix(delivery.WebhookID)
+		var keys [][]byte
+		cursor := bucket.Cursor()
+		for key, _ := cursor.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+			keys = append(keys, append([]byte{}, key...))
+		}
+
+		for len(keys) > maxDeliveries {
+			if err := bucket.Delete(keys[0]); err != nil {
+				return err
+			}
+			keys = keys[1:]
+		}
+
+		return nil
+	})
+}
+
+// Deliveries возвращает до limit последних доставок вебхука, начиная с последней
+func (s *Store) Deliveries(webhookID string, limit int) ([]Delivery, error) {
+	var result []Delivery
+	prefix := runPrefix(webhookID)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(deliveriesBucket).Cursor()
+
+		key, data := cursor.Seek(append(append([]byte{}, prefix...), 0xFF))
+		if key == nil {
+			key, data = cursor.Last()
+		} else {
+			key, data = cursor.Prev()
+		}
+
+		for ; key != nil && bytes.HasPrefix(key, prefix); key, data = cursor.Prev() {
+			if limit > 0 && len(result) >= limit {
+				break
+			}
+
+			var delivery Delivery
+			if err := json.Unmarshal(data, &delivery); err != nil {
+				return fmt.Errorf("ошибка чтения доставки %s: %w", key, err)
+			}
+			result = append(result, delivery)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
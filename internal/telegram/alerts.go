@@ -0,0 +1,167 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"tendertracker/internal/districts"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/notify"
+	"tendertracker/internal/storage"
+)
+
+// channel - канал в отметках об отправке
+const channel = "telegram"
+
+// maxMessageLength - длина сообщения с запасом до ограничения Bot API в 4096 символов
+const maxMessageLength = 3500
+
+// maxTitleLength - длиннее название закупки в уведомлении обрезается
+const maxTitleLength = 300
+
+// Notify рассылает подписчикам новые тендеры запуска, подходящие по категории
+// и округу. Каждому чату тендер отправляется один раз
+func (b *Bot) Notify(ctx context.Context, saved storage.SavedSearch, run *storage.SearchRun) error {
+	if len(run.New) == 0 {
+		return nil
+	}
+
+	subscriptions, err := b.store.Subscriptions()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, subscription := range subscriptions {
+		// Подписки чатов, убранных из telegram.allowed_chats, не обслуживаются
+		if !b.allowed[subscription.ChatID] {
+			continue
+		}
+
+		matched := matchSubscription(subscription, run.New)
+		if len(matched) == 0 {
+			continue
+		}
+
+		recipient := strconv.FormatInt(subscription.ChatID, 10)
+		tenders, err := b.store.Unsent(channel, recipient, matched)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(tenders) == 0 {
+			continue
+		}
+
+		if err := b.sendAlert(ctx, subscription.ChatID, saved, tenders); err != nil {
+			var apiErr *APIError
+			// Бот заблокирован или удален из чата - подписка больше не нужна
+			if errors.As(err, &apiErr) && apiErr.Code == 403 {
+				logger.SugaredLogger.Infof("Telegram: чат %d недоступен, подписка удалена", subscription.ChatID)
+				if err := b.store.DeleteSubscription(subscription.ChatID); err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			errs = append(errs, fmt.Errorf("чат %d: %w", subscription.ChatID, err))
+			continue
+		}
+
+		if err := b.store.MarkSent(channel, recipient, tenders, time.Now()); err != nil {
+			errs = append(errs, err)
+		}
+
+		logger.SugaredLogger.Infof("Telegram: %d новых закупок поиска %s отправлено в чат %d", len(tenders), saved.Name, subscription.ChatID)
+	}
+
+	return errors.Join(errs...)
+}
+
+// matchSubscription отбирает тендеры подписанных категорий из выбранных округов
+func matchSubscription(subscription storage.Subscription, tenders []storage.TenderState) []storage.TenderState {
+	var matched []storage.TenderState
+	for _, tender := range tenders {
+		if slices.Contains(subscription.Categories, tender.Category) && districts.Contains(tender.Region, subscription.Districts) {
+			matched = append(matched, tender)
+		}
+	}
+	return matched
+}
+
+func (b *Bot) sendAlert(ctx context.Context, chatID int64, saved storage.SavedSearch, tenders []storage.TenderState) error {
+	digest := notify.NewDigest(saved, tenders, b.categories)
+
+	for _, text := range alertMessages(digest) {
+		if err := b.client.SendMessage(ctx, chatID, text); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// alertMessages раскладывает дайджест по сообщениям не длиннее maxMessageLength
+func alertMessages(digest notify.Digest) []string {
+	var messages []string
+	var text strings.Builder
+
+	fmt.Fprintf(&text, "<b>Новые закупки: %s</b> (%d)\n", html.EscapeString(digest.Search), digest.Total)
+
+	for _, group := range digest.Groups {
+		heading := "\n<b>" + html.EscapeString(group.Title) + "</b>\n"
+		text.WriteString(heading)
+
+		for _, tender := range group.Tenders {
+			item := alertItem(tender)
+			if utf8.RuneCountInString(text.String())+utf8.RuneCountInString(item) > maxMessageLength {
+				messages = append(messages, text.String())
+				text.Reset()
+				text.WriteString(strings.TrimPrefix(heading, "\n"))
+			}
+			text.WriteString(item)
+		}
+	}
+
+	return append(messages, text.String())
+}
+
+func alertItem(tender notify.DigestTender) string {
+	title := tender.Title
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		title = string([]rune(title)[:maxTitleLength]) + "…"
+	}
+	title = html.EscapeString(title)
+
+	var item strings.Builder
+	if tender.Link != "" {
+		fmt.Fprintf(&item, "\n• <a href=\"%s\">%s</a>\n", html.EscapeString(tender.Link), title)
+	} else {
+		fmt.Fprintf(&item, "\n• %s\n", title)
+	}
+
+	var place []string
+	for _, value := range []string{tender.Customer, tender.Region} {
+		if value != "" {
+			place = append(place, html.EscapeString(value))
+		}
+	}
+	if len(place) > 0 {
+		item.WriteString(strings.Join(place, ", ") + "\n")
+	}
+
+	details := []string{tender.Price}
+	if tender.EndDate != "" {
+		details = append(details, "до "+tender.EndDate)
+	}
+	details = append(details, html.EscapeString(tender.Source))
+	item.WriteString(strings.Join(details, " · ") + "\n")
+
+	return item.String()
+}
@@ -0,0 +1,145 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"tendertracker/internal/categories"
+	"tendertracker/internal/jobs"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/reports"
+	"tendertracker/internal/search"
+	"tendertracker/internal/sources"
+	"tendertracker/internal/storage"
+)
+
+// pollTimeout - время ожидания событий в одном запросе getUpdates
+const pollTimeout = 30 * time.Second
+
+// Config - настройки бота
+type Config struct {
+	Token string
+	// APIURL - адрес Bot API, по умолчанию DefaultAPIURL
+	APIURL string
+	// AllowedChats - чаты, которым разрешено пользоваться ботом
+	AllowedChats []int64
+}
+
+// Enabled - токен бота задан
+func (c Config) Enabled() bool {
+	return c.Token != ""
+}
+
+// Bot отвечает на команды в чатах и рассылает подписчикам новые тендеры
+// по итогам поисков по расписанию
+type Bot struct {
+	client     *Client
+	searcher   *search.Searcher
+	manager    *jobs.Manager
+	store      *storage.Store
+	reports    *reports.Store
+	categories categories.List
+	// allowed - чаты из Config.AllowedChats
+	allowed map[int64]bool
+
+	mu sync.Mutex
+	// searching - чаты, для которых сейчас выполняется поиск
+	searching map[int64]*jobs.Job
+}
+
+func NewBot(config Config, searcher *search.Searcher, manager *jobs.Manager, store *storage.Store, reportStore *reports.Store, list categories.List) *Bot {
+	allowed := make(map[int64]bool, len(config.AllowedChats))
+	for _, chatID := range config.AllowedChats {
+		allowed[chatID] = true
+	}
+
+	return &Bot{
+		client:     NewClient(config.APIURL, config.Token),
+		searcher:   searcher,
+		manager:    manager,
+		store:      store,
+		reports:    reportStore,
+		categories: list,
+		allowed:    allowed,
+		searching:  make(map[int64]*jobs.Job),
+	}
+}
+
+func (b *Bot) Name() string {
+	return channel
+}
+
+// Start получает команды из чатов, пока не отменен ctx
+func (b *Bot) Start(ctx context.Context) {
+	go b.poll(ctx)
+}
+
+func (b *Bot) poll(ctx context.Context) {
+	var offset int64
+
+	for {
+		updates, err := b.client.GetUpdates(ctx, offset, pollTimeout)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.SugaredLogger.Warnf("Telegram: ошибка получения сообщений: %v", err)
+			if sources.Sleep(ctx, 5*time.Second) != nil {
+				return
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			offset = update.UpdateID + 1
+			if update.Message == nil || update.Message.Text == "" {
+				continue
+			}
+			b.handle(ctx, update.Message)
+		}
+	}
+}
+
+// handle разбирает команду и отвечает в чат
+func (b *Bot) handle(ctx context.Context, message *Message) {
+	fields := strings.Fields(message.Text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return
+	}
+
+	// В группах команда приходит в виде /search@имя_бота
+	command, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	args := fields[1:]
+	chatID := message.Chat.ID
+
+	if !b.allowed[chatID] {
+		logger.SugaredLogger.Warnf("Telegram: команда %s от чата %d отклонена, чата нет в telegram.allowed_chats", command, chatID)
+		b.reply(ctx, chatID, fmt.Sprintf("Доступ запрещен. Чтобы пользоваться ботом, попросите администратора добавить чат %d в настройки", chatID))
+		return
+	}
+
+	logger.SugaredLogger.Infof("Telegram: команда %s от чата %d", command, chatID)
+
+	var reply string
+	switch command {
+	case "/start", "/help":
+		reply = b.help()
+	case "/categories":
+		reply = b.categoryList()
+	case "/search":
+		reply = b.search(chatID, args)
+	case "/subscribe":
+		reply = b.subscribe(chatID, args)
+	case "/unsubscribe":
+		reply = b.unsubscribe(chatID, args)
+	case "/subscriptions":
+		reply = b.subscriptions(chatID)
+	default:
+		reply = "Неизвестная команда. Список команд: /help"
+	}
+
+	b.reply(ctx, chatID, reply)
+}
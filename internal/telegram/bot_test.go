@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"tendertracker/internal/logger"
+)
+
+func TestHandleRejectsUnknownChats(t *testing.T) {
+	logger.InitLogger("error")
+
+	var (
+		mu      sync.Mutex
+		replies = make(map[int64]string)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		chatID, _ := strconv.ParseInt(r.PostForm.Get("chat_id"), 10, 64)
+
+		mu.Lock()
+		replies[chatID] = r.PostForm.Get("text")
+		mu.Unlock()
+
+		w.Write([]byte(`{"ok":true,"result":{}}`))
+	}))
+	defer server.Close()
+
+	// Хранилище не задано: команда из чужого чата не должна до него дойти
+	bot := NewBot(Config{Token: "token", APIURL: server.URL, AllowedChats: []int64{42, -100}}, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		chatID  int64
+		text    string
+		allowed bool
+	}{
+		{42, "/help", true},
+		{-100, "/help@tender_bot", true},
+		{7, "/help", false},
+		{8, "/subscriptions", false},
+		{9, "/search vent", false},
+	}
+
+	for _, tt := range tests {
+		bot.handle(context.Background(), &Message{Chat: Chat{ID: tt.chatID}, Text: tt.text})
+
+		mu.Lock()
+		reply := replies[tt.chatID]
+		mu.Unlock()
+
+		if denied := strings.HasPrefix(reply, "Доступ запрещен"); denied == tt.allowed {
+			t.Errorf("chat %d %q: reply %q, allowed = %v", tt.chatID, tt.text, reply, tt.allowed)
+		}
+		if !tt.allowed && !strings.Contains(reply, strconv.FormatInt(tt.chatID, 10)) {
+			t.Errorf("chat %d: reply %q has no chat id", tt.chatID, reply)
+		}
+	}
+}
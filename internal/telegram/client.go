@@ -0,0 +1,203 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"tendertracker/internal/sources"
+)
+
+// DefaultAPIURL - адрес Bot API по умолчанию
+const DefaultAPIURL = "https://api.telegram.org"
+
+// maxRetries - сколько раз повторять запрос, если Bot API просит подождать
+const maxRetries = 3
+
+// Client - клиент Bot API. Адрес задается, чтобы бота можно было проверить
+// на локальной заглушке или собственном сервере Bot API
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Update - входящее событие бота
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message,omitempty"`
+}
+
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	Chat      Chat   `json:"chat"`
+	From      *User  `json:"from,omitempty"`
+	Text      string `json:"text"`
+}
+
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// APIError - ошибка, которую вернул Bot API
+type APIError struct {
+	Code        int
+	Description string
+	// RetryAfter - сколько ждать перед повтором при превышении лимита
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram: %d %s", e.Code, e.Description)
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  *struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// GetUpdates ждет новые события не дольше timeout (long polling)
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	params := url.Values{}
+	params.Set("offset", strconv.FormatInt(offset, 10))
+	params.Set("timeout", strconv.Itoa(int(timeout.Seconds())))
+	params.Set("allowed_updates", `["message"]`)
+
+	var updates []Update
+	err := c.call(ctx, "getUpdates", func() (io.Reader, string, error) {
+		return strings.NewReader(params.Encode()), "application/x-www-form-urlencoded", nil
+	}, &updates)
+
+	return updates, err
+}
+
+// SendMessage отправляет сообщение с HTML-разметкой
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	params := url.Values{}
+	params.Set("chat_id", strconv.FormatInt(chatID, 10))
+	params.Set("text", text)
+	params.Set("parse_mode", "HTML")
+	params.Set("disable_web_page_preview", "true")
+
+	return c.call(ctx, "sendMessage", func() (io.Reader, string, error) {
+		return strings.NewReader(params.Encode()), "application/x-www-form-urlencoded", nil
+	}, nil)
+}
+
+// SendDocument отправляет файл с подписью
+func (c *Client) SendDocument(ctx context.Context, chatID int64, filename string, data []byte, caption string) error {
+	return c.call(ctx, "sendDocument", func() (io.Reader, string, error) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+
+		writer.WriteField("chat_id", strconv.FormatInt(chatID, 10))
+		if caption != "" {
+			writer.WriteField("caption", caption)
+			writer.WriteField("parse_mode", "HTML")
+		}
+
+		part, err := writer.CreateFormFile("document", filename)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(data); err != nil {
+			return nil, "", err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, "", err
+		}
+
+		return &body, writer.FormDataContentType(), nil
+	}, nil)
+}
+
+// call выполняет метод Bot API. Тело собирается заново для каждой попытки.
+// При превышении лимита запрос повторяется через указанное API время
+func (c *Client) call(ctx context.Context, method string, body func() (io.Reader, string, error), result any) error {
+	endpoint := c.baseURL + "/bot" + c.token + "/" + method
+
+	for attempt := 0; ; attempt++ {
+		reader, contentType, err := body()
+		if err != nil {
+			return err
+		}
+
+		err = c.do(ctx, endpoint, reader, contentType, result)
+
+		apiErr, ok := err.(*APIError)
+		if !ok || apiErr.RetryAfter == 0 || attempt >= maxRetries {
+			return err
+		}
+
+		if err := sources.Sleep(ctx, apiErr.RetryAfter); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, endpoint string, body io.Reader, contentType string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// В ошибке может оказаться адрес с токеном
+		if urlErr, ok := err.(*url.Error); ok {
+			return fmt.Errorf("telegram: %w", urlErr.Err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("telegram: некорректный ответ (HTTP %d): %w", resp.StatusCode, err)
+	}
+
+	if !response.OK {
+		apiErr := &APIError{Code: response.ErrorCode, Description: response.Description}
+		if response.Parameters != nil {
+			apiErr.RetryAfter = time.Duration(response.Parameters.RetryAfter) * time.Second
+		}
+		return apiErr
+	}
+
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
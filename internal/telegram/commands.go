@@ -0,0 +1,354 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"tendertracker/internal/districts"
+	"tendertracker/internal/jobs"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/reports"
+	"tendertracker/internal/search"
+	"tendertracker/internal/storage"
+)
+
+// searchArgs - разобранные параметры команд /search и /subscribe
+type searchArgs struct {
+	categories []string
+	districts  []string
+	// minPrice - минимальная цена для всех категорий, -1 - не указана
+	minPrice int
+}
+
+// parseArgs разбирает параметры: ключи категорий, коды округов (OKER30...)
+// и минимальную цену в рублях. Параметры можно разделять пробелами и запятыми
+func (b *Bot) parseArgs(args []string) (searchArgs, error) {
+	parsed := searchArgs{minPrice: -1}
+
+	var tokens []string
+	for _, arg := range args {
+		tokens = append(tokens, strings.FieldsFunc(arg, func(r rune) bool { return r == ',' })...)
+	}
+
+	for _, token := range tokens {
+		if category := b.categories.Get(strings.ToLower(token)); category != nil {
+			if !slices.Contains(parsed.categories, category.Name) {
+				parsed.categories = append(parsed.categories, category.Name)
+			}
+			continue
+		}
+
+		if code := districts.Code(token); code != "" {
+			if !slices.Contains(parsed.districts, code) {
+				parsed.districts = append(parsed.districts, code)
+			}
+			continue
+		}
+
+		if price, err := strconv.Atoi(token); err == nil && price >= 0 {
+			parsed.minPrice = price
+			continue
+		}
+
+		return parsed, fmt.Errorf("Неизвестный параметр %q. Категории и округа: /categories", token)
+	}
+
+	return parsed, nil
+}
+
+// orderCategories возвращает категории в порядке файла категорий
+func (b *Bot) orderCategories(names []string) []string {
+	var ordered []string
+	for _, category := range b.categories {
+		if slices.Contains(names, category.Name) {
+			ordered = append(ordered, category.Name)
+		}
+	}
+	return ordered
+}
+
+func (b *Bot) help() string {
+	return strings.Join([]string{
+		"<b>TenderTracker</b> - поиск закупок на ЕИС, Сбер-АСТ и Bidzaar.",
+		"",
+		"/search vent OKER31 500000 - найти закупки категории vent в Северо-Западном ФО от 500 000 ₽ и прислать отчет Excel. Без категорий ищутся категории по умолчанию, без округов - по всей России",
+		"/subscribe vent OKER31 - присылать новые закупки категории vent из Северо-Западного ФО",
+		"/unsubscribe vent - отписаться от категории или округа, без параметров - от всего",
+		"/subscriptions - текущая подписка",
+		"/categories - категории и коды округов",
+		"",
+		"Новые закупки по подписке приходят по итогам поисков по расписанию.",
+	}, "\n")
+}
+
+func (b *Bot) categoryList() string {
+	var text strings.Builder
+
+	text.WriteString("<b>Категории</b>\n")
+	for _, category := range b.categories {
+		fmt.Fprintf(&text, "<code>%s</code> - %s\n", category.Name, html.EscapeString(category.Title))
+	}
+
+	text.WriteString("\n<b>Федеральные округа</b>\n")
+	for _, code := range districts.Codes {
+		fmt.Fprintf(&text, "<code>%s</code> - %s\n", code, districts.Title(code))
+	}
+
+	return text.String()
+}
+
+// search запускает поиск в фоне, итог и отчет придут отдельными сообщениями.
+// Это та же задача поиска, что и из веб-формы, ее ход виден через /tender/jobs/{id}
+func (b *Bot) search(chatID int64, args []string) string {
+	parsed, err := b.parseArgs(args)
+	if err != nil {
+		return html.EscapeString(err.Error())
+	}
+
+	names := parsed.categories
+	if len(names) == 0 {
+		for _, category := range b.categories {
+			if category.Default {
+				names = append(names, category.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return "Укажите категорию. Список категорий: /categories"
+	}
+
+	config := models.Config{
+		Categories:        b.orderCategories(names),
+		MinPrices:         make(map[string]int),
+		VentCustomerPlace: parsed.districts,
+		ProcurementType:   "active",
+	}
+	for _, name := range config.Categories {
+		if parsed.minPrice >= 0 {
+			config.MinPrices[name] = parsed.minPrice
+		} else {
+			config.MinPrices[name] = b.categories.Get(name).MinPrice
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if job, ok := b.searching[chatID]; ok {
+		return fmt.Sprintf("Поиск уже выполняется (задача <code>%s</code>), дождитесь результата", job.ID())
+	}
+
+	job := b.manager.Start(config, func(ctx context.Context, job *jobs.Job) (any, error) {
+		defer func() {
+			b.mu.Lock()
+			delete(b.searching, chatID)
+			b.mu.Unlock()
+		}()
+
+		result, report, err := b.searcher.RunReport(ctx, &config, job.Report)
+
+		// Итог отправляется и после отмены поиска, по тому, что успели найти
+		replyCtx := context.WithoutCancel(ctx)
+		if err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			b.reply(replyCtx, chatID, "Ошибка поиска: "+html.EscapeString(err.Error()))
+			return nil, err
+		}
+
+		b.sendResult(replyCtx, chatID, config, result, report)
+		return report, nil
+	})
+	b.searching[chatID] = job
+
+	return fmt.Sprintf("Поиск запущен: %s. Задача <code>%s</code>, результат придет сюда",
+		b.describe(config.Categories, config.VentCustomerPlace), job.ID())
+}
+
+// sendResult отправляет итог поиска по категориям и площадкам и отчет Excel
+func (b *Bot) sendResult(ctx context.Context, chatID int64, config models.Config, result *search.Result, report *reports.Report) {
+	var text strings.Builder
+
+	if result.Partial {
+		text.WriteString("<b>Поиск прерван, результаты неполные</b>\n\n")
+	} else {
+		text.WriteString("<b>Поиск завершен</b>\n\n")
+	}
+
+	for _, name := range config.Categories {
+		var bySource []string
+		total := 0
+		for _, source := range result.Sources {
+			if found := result.Stats[name+"Found"+source.Name]; found > 0 {
+				bySource = append(bySource, fmt.Sprintf("%s: %d", html.EscapeString(source.Title), found))
+				total += found
+			}
+		}
+
+		fmt.Fprintf(&text, "%s: %d", html.EscapeString(b.categories.Get(name).Title), total)
+		if len(bySource) > 0 {
+			fmt.Fprintf(&text, " (%s)", strings.Join(bySource, ", "))
+		}
+		text.WriteString("\n")
+	}
+
+	fmt.Fprintf(&text, "\nВсего найдено: %d, новых в базе: %d", result.Stats["totalFound"], result.Stats["totalNew"])
+
+	for _, message := range result.Errors {
+		text.WriteString("\n⚠️ " + html.EscapeString(message))
+	}
+
+	b.reply(ctx, chatID, text.String())
+
+	if result.Stats["totalFound"] == 0 {
+		return
+	}
+
+	_, path, err := b.reports.Get(report.ID)
+	if err != nil {
+		logger.SugaredLogger.Warnf("Telegram: отчет %s: %v", report.ID, err)
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.SugaredLogger.Warnf("Telegram: ошибка чтения отчета %s: %v", report.ID, err)
+		return
+	}
+
+	if err := b.client.SendDocument(ctx, chatID, report.Filename, data, ""); err != nil {
+		logger.SugaredLogger.Warnf("Telegram: ошибка отправки отчета в чат %d: %v", chatID, err)
+	}
+}
+
+func (b *Bot) subscribe(chatID int64, args []string) string {
+	parsed, err := b.parseArgs(args)
+	if err != nil {
+		return html.EscapeString(err.Error())
+	}
+	if len(parsed.categories) == 0 && len(parsed.districts) == 0 {
+		return "Укажите категории и округа, например: /subscribe vent OKER31"
+	}
+
+	subscription, err := b.store.GetSubscription(chatID)
+	if err != nil {
+		logger.SugaredLogger.Warnf(err.Error())
+		return "Не удалось прочитать подписку, попробуйте позже"
+	}
+	if subscription == nil {
+		subscription = &storage.Subscription{ChatID: chatID}
+	}
+
+	for _, name := range parsed.categories {
+		if !slices.Contains(subscription.Categories, name) {
+			subscription.Categories = append(subscription.Categories, name)
+		}
+	}
+	subscription.Categories = b.orderCategories(subscription.Categories)
+
+	for _, code := range parsed.districts {
+		if !slices.Contains(subscription.Districts, code) {
+			subscription.Districts = append(subscription.Districts, code)
+		}
+	}
+
+	if err := b.store.SaveSubscription(subscription); err != nil {
+		logger.SugaredLogger.Warnf(err.Error())
+		return "Не удалось сохранить подписку, попробуйте позже"
+	}
+
+	return b.subscriptionText(subscription)
+}
+
+func (b *Bot) unsubscribe(chatID int64, args []string) string {
+	parsed, err := b.parseArgs(args)
+	if err != nil {
+		return html.EscapeString(err.Error())
+	}
+
+	subscription, err := b.store.GetSubscription(chatID)
+	if err != nil {
+		logger.SugaredLogger.Warnf(err.Error())
+		return "Не удалось прочитать подписку, попробуйте позже"
+	}
+	if subscription == nil {
+		return "Подписки нет"
+	}
+
+	subscription.Categories = slices.DeleteFunc(subscription.Categories, func(name string) bool {
+		return slices.Contains(parsed.categories, name)
+	})
+	subscription.Districts = slices.DeleteFunc(subscription.Districts, func(code string) bool {
+		return slices.Contains(parsed.districts, code)
+	})
+
+	// Без параметров или без категорий подписка удаляется целиком
+	if len(args) == 0 || len(subscription.Categories) == 0 {
+		if err := b.store.DeleteSubscription(chatID); err != nil {
+			logger.SugaredLogger.Warnf(err.Error())
+			return "Не удалось удалить подписку, попробуйте позже"
+		}
+		return "Подписка удалена"
+	}
+
+	if err := b.store.SaveSubscription(subscription); err != nil {
+		logger.SugaredLogger.Warnf(err.Error())
+		return "Не удалось сохранить подписку, попробуйте позже"
+	}
+
+	return b.subscriptionText(subscription)
+}
+
+func (b *Bot) subscriptions(chatID int64) string {
+	subscription, err := b.store.GetSubscription(chatID)
+	if err != nil {
+		logger.SugaredLogger.Warnf(err.Error())
+		return "Не удалось прочитать подписку, попробуйте позже"
+	}
+	if subscription == nil {
+		return "Подписки нет. Подписаться: /subscribe vent OKER31"
+	}
+
+	return b.subscriptionText(subscription)
+}
+
+func (b *Bot) subscriptionText(subscription *storage.Subscription) string {
+	if len(subscription.Categories) == 0 {
+		return "Подписка сохранена, но категории не выбраны. Добавьте категорию: /subscribe vent"
+	}
+	return "Подписка: " + b.describe(subscription.Categories, subscription.Districts)
+}
+
+// describe - категории и округа для сообщений
+func (b *Bot) describe(names []string, codes []string) string {
+	titles := make([]string, 0, len(names))
+	for _, name := range names {
+		title := name
+		if category := b.categories.Get(name); category != nil {
+			title = category.Title
+		}
+		titles = append(titles, html.EscapeString(title))
+	}
+
+	places := "вся Россия"
+	if len(codes) > 0 {
+		var names []string
+		for _, code := range codes {
+			names = append(names, districts.Title(code))
+		}
+		places = strings.Join(names, ", ")
+	}
+
+	return strings.Join(titles, ", ") + "; " + places
+}
+
+// reply отправляет сообщение, ошибка только записывается в журнал
+func (b *Bot) reply(ctx context.Context, chatID int64, text string) {
+	if err := b.client.SendMessage(ctx, chatID, text); err != nil {
+		logger.SugaredLogger.Warnf("Telegram: ошибка отправки в чат %d: %v", chatID, err)
+	}
+}
@@ -1,37 +1,98 @@
 package main
 
 import (
+	"fmt"
+	"io"
 	"os"
-	"regexp"
 	"strings"
-	"tendertracker/internal/handlers"
+	"tendertracker/internal/categories"
+	"tendertracker/internal/config"
+	"tendertracker/internal/fetcher"
 	"tendertracker/internal/logger"
+	"tendertracker/internal/parserbidzaar"
+	"tendertracker/internal/parsergovru"
+	"tendertracker/internal/parsersber"
+	"tendertracker/internal/rules"
+	"tendertracker/internal/search"
+	"tendertracker/internal/sources"
+)
+
+// Коды завершения
+const (
+	exitOK = 0
+	// exitFailed - поиск не удался ни на одной площадке или результат не записан
+	exitFailed = 1
+	exitUsage  = 2
+	// exitPartial - часть площадок вернула ошибку или поиск прерван
+	exitPartial = 3
 )
 
 func main() {
-	logger.InitLogger("debug")
-	defer logger.Close()
+	os.Exit(run(os.Args[1:]))
+}
 
-	re, err := loadFilterPatterns("filter_patterns_vent.txt")
-	if err != nil {
-		logger.SugaredLogger.Errorf(err.Error())
+// run выбирает команду. Без команды запускается веб-сервер, как раньше
+func run(args []string) int {
+	command := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
 	}
 
-	router := handlers.SetupRouter(re)
-
-	if err := router.Run(":8081"); err != nil {
-		logger.SugaredLogger.Errorf(err.Error())
+	switch command {
+	case "serve":
+		return serve(args)
+	case "search":
+		return searchCommand(args)
+	case "help":
+		usage(os.Stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(os.Stderr, "неизвестная команда %q\n\n", command)
+		usage(os.Stderr)
+		return exitUsage
 	}
+}
 
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Использование:
+  tendertracker [serve] [флаги]   веб-интерфейс и API
+  tendertracker search [флаги]    поиск без веб-сервера, отчет в файл
+
+Подробнее о флагах: tendertracker <команда> -h
+
+Настройки читаются из config.json (или файла из -config и $TENDERTRACKER_CONFIG),
+переменных окружения TENDERTRACKER_* и флагов, см. config.example.json
+
+Коды завершения search:
+  0  поиск выполнен на всех площадках
+  1  поиск не удался ни на одной площадке или отчет не записан
+  2  неверные параметры
+  3  часть площадок вернула ошибку или поиск прерван, отчет неполный
+`)
 }
 
-func loadFilterPatterns(filename string) (*regexp.Regexp, error) {
-	data, err := os.ReadFile(filename)
+// newSearcher загружает категории и правила отсева и регистрирует площадки.
+// client - общий HTTP-клиент площадок, cache хранит разобранные страницы
+// закупок между поисками и может быть nil
+func newSearcher(cfg *config.Config, client *fetcher.Fetcher, cache sources.Cache) (*search.Searcher, error) {
+	list, err := categories.Load(cfg.Paths.Categories)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось загрузить категории: %w", err)
+	}
+
+	ruleManager, err := rules.NewManager(cfg.Paths.Rules, list)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("не удалось загрузить правила отсева:\n%w", err)
 	}
+	logger.SugaredLogger.Infof("Загружено правил отсева: %d", len(ruleManager.Current().Rules))
 
-	pattern := strings.TrimSpace(string(data))
+	sources.Register(parsergovru.NewSource(cfg.Sources.ZakupkiGovRu.Settings(client, cache)))
+	sources.Register(parsersber.NewSource(cfg.Sources.Sber.Settings(client, cache)))
+	sources.Register(parserbidzaar.NewSource(cfg.Sources.Bidzaar.Settings(client, cache)))
 
-	return regexp.MustCompile(pattern), nil
+	return &search.Searcher{
+		Rules:      ruleManager,
+		Categories: list,
+		Timeout:    cfg.Search.Timeout.Duration(),
+	}, nil
 }
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"tendertracker/internal/categories"
+	"tendertracker/internal/config"
+	"tendertracker/internal/districts"
+	"tendertracker/internal/excel"
+	"tendertracker/internal/fetcher"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/models"
+	"tendertracker/internal/reports"
+	"tendertracker/internal/search"
+	"time"
+)
+
+// searchOptions - флаги команды search
+type searchOptions struct {
+	categories string
+	districts  string
+	minPrice   int
+	procType   string
+	format     string
+	out        string
+	timeout    int
+	rejected   bool
+	enrich     bool
+}
+
+// searchOutput - результат поиска в формате json
+type searchOutput struct {
+	Stats   map[string]int      `json:"stats"`
+	Sources []search.SourceInfo `json:"sources"`
+	Partial bool                `json:"partial"`
+	Errors  []string            `json:"errors,omitempty"`
+	Tenders []outputTender      `json:"tenders"`
+	// Rejected - отсеянные закупки, только с флагом -rejected
+	Rejected []models.Rejection `json:"rejected,omitempty"`
+}
+
+type outputTender struct {
+	models.Tender
+	Source   string `json:"source"`
+	Category string `json:"category"`
+}
+
+// searchCommand выполняет поиск без веб-сервера и записывает отчет.
+// Код завершения различает полный успех, частичный результат и неудачу
+func searchCommand(args []string) int {
+	var options searchOptions
+
+	flags := flag.NewFlagSet("search", flag.ContinueOnError)
+	flags.StringVar(&options.categories, "categories", "", "категории через запятую (по умолчанию - отмеченные в файле категорий)")
+	flags.StringVar(&options.districts, "districts", "", "коды федеральных округов через запятую, например OKER30,OKER31 (по умолчанию - вся Россия)")
+	flags.IntVar(&options.minPrice, "min-price", -1, "минимальная цена в рублях для всех категорий (по умолчанию - из файла категорий)")
+	flags.StringVar(&options.procType, "type", "active", "закупки: active или completed")
+	flags.StringVar(&options.format, "format", "xlsx", "формат отчета: xlsx или json")
+	flags.StringVar(&options.out, "out", "", "файл отчета, - для stdout (по умолчанию xlsx пишется в Закупки_<дата>_<время>.xlsx, json - в stdout)")
+	flags.IntVar(&options.timeout, "timeout", -1, "ограничение времени поиска в минутах, 0 - без ограничения (по умолчанию - из настроек)")
+	flags.BoolVar(&options.rejected, "rejected", false, "добавить в отчет отсеянные закупки с причиной отсева (лист \"Отфильтровано\" или поле rejected в json)")
+	flags.BoolVar(&options.enrich, "enrich", false, "разобрать извещения zakupki.gov.ru целиком: сроки, обеспечение, контакты, ОКПД2, лоты, документы")
+	configFlags := config.RegisterFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "лишние аргументы: %s\n", strings.Join(flags.Args(), " "))
+		return exitUsage
+	}
+
+	// Журнал консольной команды по умолчанию короче, чем у веб-сервера
+	defaults := config.Default()
+	defaults.Log.Level = "info"
+
+	cfg, err := configFlags.Load(defaults)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	logger.InitLoggerTo(cfg.Log.Level, os.Stderr)
+	defer logger.Close()
+
+	// База может быть открыта веб-сервером, поэтому без постоянного кэша
+	searcher, err := newSearcher(cfg, fetcher.New(), nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailed
+	}
+	if options.timeout >= 0 {
+		searcher.Timeout = time.Duration(options.timeout) * time.Minute
+	}
+
+	config, err := searchConfig(options, searcher.Categories)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	// Ctrl+C или SIGTERM останавливают поиск, найденное к этому моменту попадет в отчет
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result := searcher.Run(ctx, config, nil)
+
+	if err := writeSearchOutput(options, config, searcher.Categories, result); err != nil {
+		fmt.Fprintf(os.Stderr, "не удалось записать отчет: %v\n", err)
+		return exitFailed
+	}
+
+	printSummary(os.Stderr, config, searcher.Categories, result)
+
+	switch {
+	case len(result.FailedSources) == len(result.Sources) && result.Stats["totalFound"] == 0:
+		return exitFailed
+	case result.Partial || len(result.Errors) > 0:
+		return exitPartial
+	default:
+		return exitOK
+	}
+}
+
+// searchConfig собирает models.Config из флагов так же, как форма поиска
+func searchConfig(options searchOptions, list categories.List) (*models.Config, error) {
+	config := &models.Config{
+		MinPrices:       make(map[string]int),
+		ProcurementType: options.procType,
+		ReportRejected:  options.rejected,
+		EnrichNotices:   options.enrich,
+	}
+
+	if options.procType != "active" && options.procType != "completed" {
+		return nil, fmt.Errorf("неизвестный тип закупок %q, допустимо active или completed", options.procType)
+	}
+	if options.format != "xlsx" && options.format != "json" {
+		return nil, fmt.Errorf("неизвестный формат %q, допустимо xlsx или json", options.format)
+	}
+
+	selected := splitList(options.categories)
+	for _, name := range selected {
+		if list.Get(name) == nil {
+			return nil, fmt.Errorf("неизвестная категория %q, доступны: %s", name, strings.Join(list.Names(), ", "))
+		}
+	}
+
+	// Категории в порядке файла категорий, как в форме
+	for _, category := range list {
+		if (len(selected) == 0 && category.Default) || slices.Contains(selected, category.Name) {
+			config.Categories = append(config.Categories, category.Name)
+
+			config.MinPrices[category.Name] = category.MinPrice
+			if options.minPrice >= 0 {
+				config.MinPrices[category.Name] = options.minPrice
+			}
+		}
+	}
+	if len(config.Categories) == 0 {
+		return nil, fmt.Errorf("не выбрано ни одной категории")
+	}
+
+	for _, value := range splitList(options.districts) {
+		code := districts.Code(value)
+		if code == "" {
+			return nil, fmt.Errorf("неизвестный федеральный округ %q, доступны: %s", value, strings.Join(districts.Codes, ", "))
+		}
+		config.VentCustomerPlace = append(config.VentCustomerPlace, code)
+	}
+
+	return config, nil
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
+
+// writeSearchOutput записывает отчет в выбранном формате в файл или stdout.
+// Файл сначала пишется во временный рядом и переименовывается только после
+// успешной записи, чтобы при ошибке не остался пустой или обрезанный отчет
+func writeSearchOutput(options searchOptions, config *models.Config, list categories.List, result *search.Result) error {
+	out := options.out
+	if out == "" {
+		out = "-"
+		if options.format == "xlsx" {
+			out = reports.Filename(time.Now())
+		}
+	}
+
+	if out == "-" {
+		return writeReport(os.Stdout, options.format, config, list, result)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(out), "."+filepath.Base(out)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := file.Name()
+
+	err = writeReport(file, options.format, config, list, result)
+	if err == nil {
+		err = file.Chmod(0o644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, out)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Отчет записан в %s\n", out)
+	return nil
+}
+
+// writeReport записывает отчет в формате json или xlsx
+func writeReport(w io.Writer, format string, config *models.Config, list categories.List, result *search.Result) error {
+	if format == "json" {
+		output := searchOutput{
+			Stats:   result.Stats,
+			Sources: result.Sources,
+			Partial: result.Partial,
+			Errors:  result.Errors,
+			Tenders: []outputTender{},
+		}
+		if config.ReportRejected {
+			output.Rejected = result.Tenders.Rejected
+		}
+		for _, category := range config.Enabled() {
+			for _, site := range result.Tenders.Sites {
+				for _, tender := range site.Tenders[category] {
+					output.Tenders = append(output.Tenders, outputTender{Tender: tender, Source: site.Name, Category: category})
+				}
+			}
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	}
+
+	file, err := excel.ToExcel(*config, result.Tenders, list)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteTo(w)
+	return err
+}
+
+// printSummary выводит итог по категориям и площадкам
+func printSummary(w io.Writer, config *models.Config, list categories.List, result *search.Result) {
+	for _, name := range config.Enabled() {
+		var bySource []string
+		total := 0
+		for _, source := range result.Sources {
+			found := result.Stats[name+"Found"+source.Name]
+			bySource = append(bySource, fmt.Sprintf("%s: %d", source.Title, found))
+			total += found
+		}
+		fmt.Fprintf(w, "%s: %d (%s)\n", list.Get(name).Title, total, strings.Join(bySource, ", "))
+	}
+
+	fmt.Fprintf(w, "Всего найдено: %d\n", result.Stats["totalFound"])
+	fmt.Fprintf(w, "Отсеяно: %d\n", result.Stats["totalRejected"])
+	for _, message := range result.Errors {
+		fmt.Fprintf(w, "Ошибка: %s\n", message)
+	}
+}
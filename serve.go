@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"tendertracker/internal/config"
+	"tendertracker/internal/documents"
+	"tendertracker/internal/fetcher"
+	"tendertracker/internal/handlers"
+	"tendertracker/internal/jobs"
+	"tendertracker/internal/logger"
+	"tendertracker/internal/notify"
+	"tendertracker/internal/reports"
+	"tendertracker/internal/scheduler"
+	"tendertracker/internal/storage"
+	"tendertracker/internal/telegram"
+	"time"
+)
+
+// shutdownTimeout - сколько ждать завершения запросов при остановке сервера
+const shutdownTimeout = 10 * time.Second
+
+// serve запускает веб-интерфейс, API, планировщик и бота
+func serve(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	configFlags := config.RegisterFlags(flags)
+	configFlags.RegisterServerFlags()
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	cfg, err := configFlags.Load(config.Default())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	logger.InitLogger(cfg.Log.Level)
+	defer logger.Close()
+
+	// Отменяется по Ctrl+C или SIGTERM: останавливает фоновые задачи и сервер
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := storage.Open(cfg.Paths.Database)
+	if err != nil {
+		logger.SugaredLogger.Errorf("Не удалось открыть базу тендеров: %v", err)
+		return exitFailed
+	}
+	defer store.Close()
+
+	if removed, err := store.PruneCache(time.Now()); err != nil {
+		logger.SugaredLogger.Warnf("Не удалось очистить кэш площадок: %v", err)
+	} else if removed > 0 {
+		logger.SugaredLogger.Infof("Удалено устаревших записей кэша площадок: %d", removed)
+	}
+
+	// Один клиент на все площадки и скачивание документов: общие соединения
+	// и ограничения по хостам
+	client := fetcher.New()
+
+	searcher, err := newSearcher(cfg, client, store)
+	if err != nil {
+		logger.SugaredLogger.Errorf(err.Error())
+		return exitFailed
+	}
+	list := searcher.Categories
+
+	reportStore, err := reports.Open(cfg.Paths.Reports, cfg.Search.ReportRetention.Duration())
+	if err != nil {
+		logger.SugaredLogger.Errorf("Не удалось открыть хранилище отчетов: %v", err)
+		return exitFailed
+	}
+
+	documentArchive, err := documents.Open(cfg.Paths.Documents, client)
+	if err != nil {
+		logger.SugaredLogger.Errorf("Не удалось открыть каталог документов: %v", err)
+		return exitFailed
+	}
+	documentArchive.Index = store
+	go documentArchive.IndexMissing(ctx)
+
+	searcher.Store = store
+	searcher.Reports = reportStore
+	searcher.Rules.History = store
+	searcher.Rules.Watch(ctx, cfg.Search.RulesReload.Duration())
+	manager := jobs.NewManager(ctx, cfg.Search.JobRetention.Duration())
+
+	email := notify.NewEmail(cfg.SMTP.Settings(), store, reportStore, list)
+	webhooks := notify.NewWebhooks(store)
+
+	sched := scheduler.New(store, searcher, manager)
+	sched.AddNotifier(email)
+	sched.AddNotifier(webhooks)
+
+	if botConfig := cfg.Telegram.Settings(); botConfig.Enabled() {
+		bot := telegram.NewBot(botConfig, searcher, manager, store, reportStore, list)
+		sched.AddNotifier(bot)
+		bot.Start(ctx)
+	}
+
+	sched.Start(ctx)
+
+	router := handlers.SetupRouter(&handlers.Services{
+		Config:     cfg,
+		Searcher:   searcher,
+		Categories: list,
+		Jobs:       manager,
+		Reports:    reportStore,
+		Store:      store,
+		Scheduler:  sched,
+		Email:      email,
+		Webhooks:   webhooks,
+		Rules:      searcher.Rules,
+		Documents:  documentArchive,
+	})
+
+	server := &http.Server{Addr: cfg.Server.Addr, Handler: router}
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.SugaredLogger.Infof("Сервер слушает %s", cfg.Server.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		logger.SugaredLogger.Errorf(err.Error())
+		return exitFailed
+	case <-ctx.Done():
+	}
+
+	logger.SugaredLogger.Infof("Остановка сервера")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.SugaredLogger.Warnf("Сервер остановлен не полностью: %v", err)
+	}
+
+	// Задачи уже отменены через ctx. База закрывается только после них
+	if err := manager.Wait(shutdownCtx); err != nil {
+		logger.SugaredLogger.Warnf("Фоновые задачи не завершились за %v: %v", shutdownTimeout, err)
+	}
+
+	return exitOK
+}
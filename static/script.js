@@ -19,47 +19,57 @@ document.getElementById('searchForm').addEventListener('submit', function(e) {
     document.getElementById('resultSection').style.display = 'none';
     document.getElementById('errorSection').style.display = 'none';
 
+    const formData = buildSearchFormData(this);
+
+    // Запускаем поиск в фоне и опрашиваем состояние задачи
+    document.getElementById('searchProgress').innerHTML = '';
+
+    fetch('/tender/searchTenders', {
+        method: 'POST',
+        body: formData
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (data.error) {
+            document.getElementById('loadingSection').style.display = 'none';
+            showError(data.error);
+            return;
+        }
+
+        currentJobUrl = data.status_url;
+
+        if (window.EventSource && data.stream_url) {
+            streamJob(data.stream_url, data.status_url);
+        } else {
+            pollJob(data.status_url);
+        }
+    })
+    .catch(handleNetworkError);
+});
+
+// Параметры поиска из формы: категории, минимальные суммы, регионы
+function buildSearchFormData(form) {
     // Собираем данные формы
-    const formData = new FormData(this);
+    const formData = new FormData(form);
     
     // Добавляем тип закупок (активные/завершенные)
     const procurementType = getProcurementType();
     formData.append('procurement_type', procurementType);
     
-    // Добавляем булевы значения для переключателей
-    formData.set('search_vent', document.getElementById('searchVent').checked);
-    formData.set('search_doors', document.getElementById('searchDoors').checked);
-    formData.set('search_build', document.getElementById('searchBuild').checked);
-    formData.set('search_metal', document.getElementById('searchMetal').checked);
-
-    // Добавляем минимальные суммы (только если переключатель активен и значение указано)
-    if (document.getElementById('searchVent').checked) {
-        const minPriceVent = document.getElementById('minPriceVent').value;
-        if (minPriceVent && minPriceVent > 0) {
-            formData.set('min_price_vent', minPriceVent);
-        }
-    }
-    
-    if (document.getElementById('searchDoors').checked) {
-        const minPriceDoors = document.getElementById('minPriceDoors').value;
-        if (minPriceDoors && minPriceDoors > 0) {
-            formData.set('min_price_doors', minPriceDoors);
-        }
-    }
-    
-    if (document.getElementById('searchBuild').checked) {
-        const minPriceBuild = document.getElementById('minPriceBuild').value;
-        if (minPriceBuild && minPriceBuild > 0) {
-            formData.set('min_price_build', minPriceBuild);
-        }
-    }
-    
-    if (document.getElementById('searchMetal').checked) {
-        const minPriceMetal = document.getElementById('minPriceMetal').value;
-        if (minPriceMetal && minPriceMetal > 0) {
-            formData.set('min_price_metal', minPriceMetal);
+    // Добавляем булевы значения для переключателей и минимальные суммы
+    // (только если переключатель активен и значение указано)
+    getCategorySwitches().forEach(switchElement => {
+        const category = switchElement.dataset.category;
+        formData.set('search_' + category, switchElement.checked);
+        formData.delete('min_price_' + category);
+
+        if (switchElement.checked) {
+            const minPrice = document.getElementById('min_price_' + category).value;
+            if (minPrice && minPrice > 0) {
+                formData.set('min_price_' + category, minPrice);
+            }
         }
-    }
+    });
 
     console.log('FormData содержимое:');
     for (let [key, value] of formData.entries()) {
@@ -82,167 +92,280 @@ document.getElementById('searchForm').addEventListener('submit', function(e) {
         formData.append('vent_del_kladr_ids', value);
     });
 
-    // Отправляем запрос
-    fetch('/tender/searchTenders', {
-        method: 'POST',
-        body: formData
-    })
+    return formData;
+}
+
+const jobPollInterval = 2000;
+
+// Адрес текущей задачи поиска, пока она выполняется
+let currentJobUrl = null;
+
+// Остановка поиска: отчет будет построен по уже найденным закупкам
+function cancelSearch() {
+    if (!currentJobUrl) {
+        return;
+    }
+
+    fetch(currentJobUrl + '/cancel', { method: 'POST' })
+    .catch(error => console.log('Не удалось остановить поиск:', error));
+}
+
+// Закрытие вкладки останавливает поиск, чтобы не нагружать площадки впустую
+window.addEventListener('pagehide', () => {
+    if (currentJobUrl && navigator.sendBeacon) {
+        navigator.sendBeacon(currentJobUrl + '/cancel');
+    }
+});
+
+// Живой ход поиска через Server-Sent Events. Если поток оборвался,
+// переходим на опрос задачи
+function streamJob(streamUrl, statusUrl) {
+    const source = new EventSource(streamUrl);
+    let board = [];
+
+    source.addEventListener('snapshot', event => {
+        board = JSON.parse(event.data).progress || [];
+        showProgress(board);
+    });
+
+    source.addEventListener('progress', event => {
+        applyProgressEvent(board, JSON.parse(event.data));
+        showProgress(board);
+    });
+
+    source.addEventListener('finished', event => {
+        source.close();
+        finishJob(JSON.parse(event.data));
+    });
+
+    source.onerror = () => {
+        source.close();
+        pollJob(statusUrl);
+    };
+}
+
+// Учитывает событие площадки так же, как это делает сервер в задаче
+function applyProgressEvent(board, event) {
+    let item = board.find(item => item.source === event.source && item.category === event.category);
+    if (!item) {
+        item = { source: event.source, category: event.category, pages: 0, cards: 0, found: 0, retries: 0, done: false };
+        board.push(item);
+    }
+
+    switch (event.kind) {
+        case 'page':
+            item.pages++;
+            item.cards += event.cards || 0;
+            item.found += event.kept || 0;
+            break;
+        case 'retry':
+            item.retries++;
+            break;
+        case 'warning':
+            item.warnings = (item.warnings || []).concat(event.message);
+            break;
+        case 'done':
+            item.done = true;
+            item.found = event.kept || 0;
+            break;
+        case 'error':
+            item.done = true;
+            item.errors = (item.errors || []).concat(event.message);
+            break;
+    }
+}
+
+function finishJob(job) {
+    currentJobUrl = null;
+    loadSchedules();
+    showProgress(job.progress || []);
+    document.getElementById('loadingSection').style.display = 'none';
+
+    // Отмененный поиск тоже строит отчет по тому, что успел найти
+    if ((job.state === 'done' || job.state === 'canceled') && job.result) {
+        showSuccess(job.result);
+    } else {
+        showError(job.error || 'Поиск остановлен');
+    }
+    scrollToBottom();
+}
+
+// Опрос задачи поиска до ее завершения
+function pollJob(statusUrl) {
+    fetch(statusUrl)
     .then(response => response.json())
-    .then(data => {
-        document.getElementById('loadingSection').style.display = 'none';
-        
-        if (data.error) {
-            showError(data.error);
+    .then(job => {
+        if (job.error && !job.state) {
+            document.getElementById('loadingSection').style.display = 'none';
+            showError(job.error);
+            return;
+        }
+
+        if (job.state === 'done' || job.state === 'failed' || job.state === 'canceled') {
+            finishJob(job);
         } else {
-            showSuccess(data);
-            // Прокрутка вниз после успешного поиска
-            setTimeout(() => {
-                window.scrollTo({
-                    top: document.body.scrollHeight,
-                    behavior: 'smooth'
-                });
-            }, 100);
+            showProgress(job.progress || []);
+            setTimeout(() => pollJob(statusUrl), jobPollInterval);
         }
     })
-    .catch(error => {
-        document.getElementById('loadingSection').style.display = 'none';
-        showError('Ошибка сети: ' + error.message);
-        // Прокрутка вниз при ошибке
-        setTimeout(() => {
-            window.scrollTo({
-                top: document.body.scrollHeight,
-                behavior: 'smooth'
-            });
-        }, 100);
+    .catch(handleNetworkError);
+}
+
+function handleNetworkError(error) {
+    currentJobUrl = null;
+    document.getElementById('loadingSection').style.display = 'none';
+    showError('Ошибка сети: ' + error.message);
+    // Прокрутка вниз при ошибке
+    scrollToBottom();
+}
+
+function scrollToBottom() {
+    setTimeout(() => {
+        window.scrollTo({
+            top: document.body.scrollHeight,
+            behavior: 'smooth'
+        });
+    }, 100);
+}
+
+// Таблица хода поиска по площадкам и категориям
+function showProgress(progress) {
+    const progressElement = document.getElementById('searchProgress');
+    if (progress.length === 0) {
+        progressElement.innerHTML = '';
+        return;
+    }
+
+    const titles = {};
+    getCategorySwitches().forEach(switchElement => {
+        titles[switchElement.dataset.category] = switchElement.dataset.title;
     });
-});
+
+    const rows = progress.map(item => `
+        <tr>
+            <td>${item.source}</td>
+            <td>${titles[item.category] || item.category}</td>
+            <td class="text-center">${item.pages}</td>
+            <td class="text-center">${item.found}</td>
+            <td class="text-center">${item.retries || 0}</td>
+            <td class="text-center">${item.done ? (item.errors ? '<span class="text-danger">ошибка</span>' : '<span class="text-success">готово</span>') : '<span class="text-muted">идет</span>'}</td>
+        </tr>
+        ${(item.warnings || []).map(warning => `<tr><td colspan="6" class="text-warning small">${warning}</td></tr>`).join('')}
+        ${(item.errors || []).map(error => `<tr><td colspan="6" class="text-danger small">${error}</td></tr>`).join('')}
+    `).join('');
+
+    progressElement.innerHTML = `
+        <table class="table table-bordered table-sm mt-3">
+            <thead class="table-light">
+                <tr>
+                    <th>Площадка</th>
+                    <th>Категория</th>
+                    <th class="text-center">Страниц</th>
+                    <th class="text-center">Найдено</th>
+                    <th class="text-center">Повторов</th>
+                    <th class="text-center">Состояние</th>
+                </tr>
+            </thead>
+            <tbody>${rows}</tbody>
+        </table>
+    `;
+}
+
+// Переключатели категорий, сформированные из файла категорий
+function getCategorySwitches() {
+    return Array.from(document.querySelectorAll('.category-switch'));
+}
+
+const sourceColors = ['text-primary', 'text-info', 'text-warning', 'text-secondary'];
 
 function showSuccess(data) {
     document.getElementById('resultSection').style.display = 'block';
+    // Запуск по расписанию возвращает только идентификатор отчета
+    lastReport = {
+        download_url: data.download_url || '/tender/download?id=' + data.report_id,
+        filename: data.filename || 'Закупки.xlsx'
+    };
+    document.getElementById('reportFilename').textContent = lastReport.filename;
 
-    const searchVent = document.getElementById('searchVent').checked
-    const searchDoors = document.getElementById('searchDoors').checked
-    const searchBuild = document.getElementById('searchBuild').checked
-    const searchMetal = document.getElementById('searchMetal').checked
-    
     const statsElement = document.getElementById('searchStats');
     
     if (data.stats !== undefined) {
         statsElement.style.display = 'block';
-        
-        let statsHTML = '<div class="row">';
 
-        // Общая статистика по всем источникам
-        if (data.stats.totalFoundZakupkiGovRu !== undefined || data.stats.totalFoundSber !== undefined) {
-            const totalZakupki = data.stats.totalFoundZakupkiGovRu || 0;
-            const totalSber = data.stats.totalFoundSber || 0;
-            const totalAll = totalZakupki + totalSber;
-            
-            statsHTML += `
-                <div class="col-md-4">
-                    <div class="card bg-light">
-                        <div class="card-body text-center">
-                            <h4 class="text-success">${totalAll}</h4>
-                            <small class="text-muted">Всего найдено закупок</small>
-                            <div class="mt-2">
-                                <small class="text-primary">Zakupki.gov.ru: ${totalZakupki}</small><br>
-                                <small class="text-info">Sber-AST: ${totalSber}</small>
-                            </div>
-                        </div>
-                    </div>
-                </div>
-            `;
-        }
+        const stats = data.stats;
+        const sources = data.sources || [];
+        const categories = getCategorySwitches()
+            .filter(switchElement => switchElement.checked)
+            .map(switchElement => ({ key: switchElement.dataset.category, title: switchElement.dataset.title }));
 
-        // Статистика по вентиляции
-        if (searchVent) {
-            const ventZakupki = data.stats.ventFoundZakupkiGovRu || 0;
-            const ventSber = data.stats.ventFoundSber || 0;
-            const ventTotal = ventZakupki + ventSber;
-            
-            statsHTML += `
-                <div class="col-md-4">
-                    <div class="card bg-light">
-                        <div class="card-body text-center">
-                            <h4 class="text-primary">${ventTotal}</h4>
-                            <small class="text-muted">Найдено закупок по вентиляции</small>
-                            <div class="mt-2">
-                                <small class="text-primary">Zakupki.gov.ru: ${ventZakupki}</small><br>
-                                <small class="text-info">Sber-AST: ${ventSber}</small>
-                            </div>
-                        </div>
-                    </div>
-                </div>
-            `;
-        }
+        // Разбивка числа по площадкам для карточки
+        const sourceLines = (statKey) => sources.map((source, i) =>
+            `<small class="${sourceColors[i % sourceColors.length]}">${source.title}: ${stats[statKey(source)] || 0}</small>`
+        ).join('<br>');
 
-        // Статистика по дверям
-        if (searchDoors) {
-            const doorsZakupki = data.stats.doorsFoundZakupkiGovRu || 0;
-            const doorsSber = data.stats.doorsFoundSber || 0;
-            const doorsTotal = doorsZakupki + doorsSber;
-            
+        const sumBySources = (statKey) => sources.reduce((sum, source) => sum + (stats[statKey(source)] || 0), 0);
+
+        let statsHTML = '';
+
+        if (data.partial || (data.warnings && data.warnings.length > 0)) {
             statsHTML += `
-                <div class="col-md-4">
-                    <div class="card bg-light">
-                        <div class="card-body text-center">
-                            <h4 class="text-primary">${doorsTotal}</h4>
-                            <small class="text-muted">Найдено закупок по монтажу дверей</small>
-                            <div class="mt-2">
-                                <small class="text-primary">Zakupki.gov.ru: ${doorsZakupki}</small><br>
-                                <small class="text-info">Sber-AST: ${doorsSber}</small>
-                            </div>
-                        </div>
-                    </div>
+                <div class="alert alert-warning">
+                    ${data.partial ? '<strong>Поиск прерван, результаты неполные.</strong>' : ''}
+                    ${(data.warnings || []).map(warning => `<div class="small">${warning}</div>`).join('')}
                 </div>
             `;
         }
 
-        // Статистика по строительству
-        if (searchBuild) {
-            const buildZakupki = data.stats.buildFoundZakupkiGovRu || 0;
-            const buildSber = data.stats.buildFoundSber || 0;
-            const buildTotal = buildZakupki + buildSber;
-            
-            statsHTML += `
-                <div class="col-md-4">
-                    <div class="card bg-light">
-                        <div class="card-body text-center">
-                            <h4 class="text-primary">${buildTotal}</h4>
-                            <small class="text-muted">Найдено закупок по строительству/реконструкции</small>
-                            <div class="mt-2">
-                                <small class="text-primary">Zakupki.gov.ru: ${buildZakupki}</small><br>
-                                <small class="text-info">Sber-AST: ${buildSber}</small>
-                            </div>
+        statsHTML += '<div class="row">';
+
+        // Общая статистика по всем источникам
+        statsHTML += `
+            <div class="col-md-4">
+                <div class="card bg-light">
+                    <div class="card-body text-center">
+                        <h4 class="text-success">${stats.totalFound || 0}</h4>
+                        <small class="text-muted">Всего найдено закупок</small>
+                        <div class="mt-2">
+                            ${sourceLines(source => 'totalFound' + source.name)}
                         </div>
+                        ${stats.totalNew !== undefined ? `<div class="mt-2"><small class="text-success fw-bold">Новых: ${stats.totalNew}</small></div>` : ''}
+                        ${stats.totalRejected ? `<div><small class="text-muted">Отсеяно: ${stats.totalRejected}</small></div>` : ''}
                     </div>
                 </div>
-            `;
-        }
+            </div>
+        `;
+
+        // Статистика по категориям
+        categories.forEach(category => {
+            const statKey = source => category.key + 'Found' + source.name;
 
-        // Статистика по металлоконструкциям
-        if (searchMetal) {
-            const metalZakupki = data.stats.metalFoundZakupkiGovRu || 0;
-            const metalSber = data.stats.metalFoundSber || 0;
-            const metalTotal = metalZakupki + metalSber;
-            
             statsHTML += `
                 <div class="col-md-4">
                     <div class="card bg-light">
                         <div class="card-body text-center">
-                            <h4 class="text-primary">${metalTotal}</h4>
-                            <small class="text-muted">Найдено закупок по поставке металлоконструкций</small>
+                            <h4 class="text-primary">${sumBySources(statKey)}</h4>
+                            <small class="text-muted">Найдено закупок: ${category.title}</small>
                             <div class="mt-2">
-                                <small class="text-primary">Zakupki.gov.ru: ${metalZakupki}</small><br>
-                                <small class="text-info">Sber-AST: ${metalSber}</small>
+                                ${sourceLines(statKey)}
                             </div>
                         </div>
                     </div>
                 </div>
             `;
-        }
+        });
 
         statsHTML += '</div>';
+
+        const rows = categories.map(category => {
+            const statKey = source => category.key + 'Found' + source.name;
+            return `
+                <tr>
+                    <td>${category.title}</td>
+                    ${sources.map(source => `<td class="text-center">${stats[statKey(source)] || 0}</td>`).join('')}
+                    <td class="text-center fw-bold">${sumBySources(statKey)}</td>
+                </tr>
+            `;
+        }).join('');
         
         statsHTML += `
             <div class="row mt-4">
@@ -257,49 +380,16 @@ function showSuccess(data) {
                                     <thead class="table-light">
                                         <tr>
                                             <th>Категория</th>
-                                            <th class="text-center">Zakupki.gov.ru</th>
-                                            <th class="text-center">Sber-AST</th>
+                                            ${sources.map(source => `<th class="text-center">${source.title}</th>`).join('')}
                                             <th class="text-center">Всего</th>
                                         </tr>
                                     </thead>
                                     <tbody>
-                                        ${searchVent ? `
-                                        <tr>
-                                            <td>Вентиляция</td>
-                                            <td class="text-center">${data.stats.ventFoundZakupkiGovRu || 0}</td>
-                                            <td class="text-center">${data.stats.ventFoundSber || 0}</td>
-                                            <td class="text-center fw-bold">${(data.stats.ventFoundZakupkiGovRu || 0) + (data.stats.ventFoundSber || 0)}</td>
-                                        </tr>
-                                        ` : ''}
-                                        ${searchDoors ? `
-                                        <tr>
-                                            <td>Монтаж дверей</td>
-                                            <td class="text-center">${data.stats.doorsFoundZakupkiGovRu || 0}</td>
-                                            <td class="text-center">${data.stats.doorsFoundSber || 0}</td>
-                                            <td class="text-center fw-bold">${(data.stats.doorsFoundZakupkiGovRu || 0) + (data.stats.doorsFoundSber || 0)}</td>
-                                        </tr>
-                                        ` : ''}
-                                        ${searchBuild ? `
-                                        <tr>
-                                            <td>Строительство/Реконструкция</td>
-                                            <td class="text-center">${data.stats.buildFoundZakupkiGovRu || 0}</td>
-                                            <td class="text-center">${data.stats.buildFoundSber || 0}</td>
-                                            <td class="text-center fw-bold">${(data.stats.buildFoundZakupkiGovRu || 0) + (data.stats.buildFoundSber || 0)}</td>
-                                        </tr>
-                                        ` : ''}
-                                        ${searchMetal ? `
-                                        <tr>
-                                            <td>Металлоконструкции</td>
-                                            <td class="text-center">${data.stats.metalFoundZakupkiGovRu || 0}</td>
-                                            <td class="text-center">${data.stats.metalFoundSber || 0}</td>
-                                            <td class="text-center fw-bold">${(data.stats.metalFoundZakupkiGovRu || 0) + (data.stats.metalFoundSber || 0)}</td>
-                                        </tr>
-                                        ` : ''}
+                                        ${rows}
                                         <tr class="table-primary">
                                             <td class="fw-bold">ИТОГО</td>
-                                            <td class="text-center fw-bold">${data.stats.totalFoundZakupkiGovRu || 0}</td>
-                                            <td class="text-center fw-bold">${data.stats.totalFoundSber || 0}</td>
-                                            <td class="text-center fw-bold">${(data.stats.totalFoundZakupkiGovRu || 0) + (data.stats.totalFoundSber || 0)}</td>
+                                            ${sources.map(source => `<td class="text-center fw-bold">${stats['totalFound' + source.name] || 0}</td>`).join('')}
+                                            <td class="text-center fw-bold">${stats.totalFound || 0}</td>
                                         </tr>
                                     </tbody>
                                 </table>
@@ -322,11 +412,19 @@ function showError(message) {
     document.getElementById('errorMessage').textContent = message;
 }
 
+// Отчет последнего поиска
+let lastReport = null;
+
 function downloadFile() {
+    if (!lastReport) {
+        showError('Отчет еще не сформирован');
+        return;
+    }
+
     // Создаем временную ссылку для скачивания
     const link = document.createElement('a');
-    link.href = '/tender/download?filename=Закупки.xlsx&t=' + new Date().getTime();
-    link.download = 'Закупки_' + new Date().toISOString().split('T')[0] + '.xlsx';
+    link.href = lastReport.download_url;
+    link.download = lastReport.filename;
     document.body.appendChild(link);
     link.click();
     document.body.removeChild(link);
@@ -347,39 +445,22 @@ function changePort(port, path = '') {
 }
 
 function setMinPrices(amount) {
-    const priceInputs = [
-        'minPriceVent', 'minPriceDoors', 'minPriceBuild', 'minPriceMetal'
-    ];
-
-    priceInputs.forEach(inputId => {
-        const input = document.getElementById(inputId);
-        if (input) {
-            input.value = amount;
-        }
+    document.querySelectorAll('.min-price').forEach(input => {
+        input.value = amount;
     });
 }
 
 function clearMinPrices() {
-    const priceInputs = [
-        'minPriceVent', 'minPriceDoors', 'minPriceBuild', 'minPriceMetal'
-    ];
-
-    priceInputs.forEach(inputId => {
-        const input = document.getElementById(inputId);
-        if (input) {
-            input.value = '';
-        }
+    document.querySelectorAll('.min-price').forEach(input => {
+        input.value = '';
     });
 }
 
 // Инициализация при загрузке страницы
 document.addEventListener('DOMContentLoaded', function() {
     // Управление состоянием полей ввода минимальных сумм
-    const switches = ['searchVent', 'searchDoors', 'searchBuild', 'searchMetal'];
-
-    switches.forEach(switchId => {
-        const switchElement = document.getElementById(switchId);
-        const priceInput = document.getElementById('minPrice' + switchId.charAt(6) + switchId.slice(7));
+    getCategorySwitches().forEach(switchElement => {
+        const priceInput = document.getElementById('min_price_' + switchElement.dataset.category);
         
         if (switchElement && priceInput) {
             priceInput.disabled = !switchElement.checked;
@@ -431,4 +512,475 @@ function getProcurementType() {
         return 'completed';
     }
     return 'active';
-}
\ No newline at end of file
+}
+
+// Сохраненные поиски по расписанию
+function loadSchedules() {
+    fetch('/tender/schedules')
+    .then(response => response.json())
+    .then(data => showSchedules(data.schedules || []))
+    .catch(error => console.log('Не удалось загрузить расписания:', error));
+}
+
+function showSchedules(schedules) {
+    const listElement = document.getElementById('scheduleList');
+    if (schedules.length === 0) {
+        listElement.innerHTML = '<p class="text-muted mb-0">Сохраненных поисков нет</p>';
+        return;
+    }
+
+    const formatTime = value => value ? new Date(value).toLocaleString('ru-RU') : '—';
+
+    const rows = schedules.map(item => `
+        <tr>
+            <td>${item.search.name}${item.search.emails ? `<br><small class="text-muted">${item.search.emails.join(', ')}</small>` : ''}</td>
+            <td><code>${item.search.schedule}</code></td>
+            <td>${item.search.config.categories.join(', ')}</td>
+            <td>${formatTime(item.search.last_run)}</td>
+            <td>${item.search.enabled ? formatTime(item.next_run) : '<span class="text-muted">выключен</span>'}</td>
+            <td class="text-nowrap">
+                <button class="btn btn-sm btn-outline-primary" onclick="runSchedule('${item.search.id}')" title="Запустить сейчас"><i class="fas fa-play"></i></button>
+                <button class="btn btn-sm btn-outline-secondary" onclick="showScheduleRuns('${item.search.id}')" title="История запусков"><i class="fas fa-history"></i></button>
+                <button class="btn btn-sm btn-outline-danger" onclick="deleteSchedule('${item.search.id}')" title="Удалить"><i class="fas fa-trash"></i></button>
+            </td>
+        </tr>
+        <tr id="runs_${item.search.id}" style="display: none;"><td colspan="6"></td></tr>
+    `).join('');
+
+    listElement.innerHTML = `
+        <table class="table table-bordered table-sm">
+            <thead class="table-light">
+                <tr>
+                    <th>Название</th>
+                    <th>Расписание</th>
+                    <th>Категории</th>
+                    <th>Последний запуск</th>
+                    <th>Следующий запуск</th>
+                    <th></th>
+                </tr>
+            </thead>
+            <tbody>${rows}</tbody>
+        </table>
+    `;
+}
+
+// Сохраняет параметры формы поиска как поиск по расписанию
+function saveSchedule() {
+    const formData = buildSearchFormData(document.getElementById('searchForm'));
+    formData.append('name', document.getElementById('scheduleName').value);
+    formData.append('schedule', document.getElementById('scheduleSpec').value);
+    formData.append('emails', document.getElementById('scheduleEmails').value);
+    formData.append('attach_report', document.getElementById('scheduleAttachReport').checked);
+
+    fetch('/tender/schedules', {
+        method: 'POST',
+        body: formData
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (data.error) {
+            showError(data.error + (data.details ? ': ' + data.details : ''));
+            return;
+        }
+        document.getElementById('scheduleName').value = '';
+        loadSchedules();
+    })
+    .catch(error => showError('Ошибка сети: ' + error.message));
+}
+
+function runSchedule(id) {
+    fetch('/tender/schedules/' + id + '/run', { method: 'POST' })
+    .then(response => response.json())
+    .then(data => {
+        if (data.error) {
+            showError(data.error);
+            return;
+        }
+
+        document.getElementById('loadingSection').style.display = 'block';
+        document.getElementById('resultSection').style.display = 'none';
+        document.getElementById('errorSection').style.display = 'none';
+        document.getElementById('searchProgress').innerHTML = '';
+
+        currentJobUrl = data.status_url;
+        pollJob(data.status_url);
+    })
+    .catch(error => showError('Ошибка сети: ' + error.message));
+}
+
+function deleteSchedule(id) {
+    if (!confirm('Удалить сохраненный поиск вместе с историей запусков?')) {
+        return;
+    }
+
+    fetch('/tender/schedules/' + id, { method: 'DELETE' })
+    .then(() => loadSchedules())
+    .catch(error => showError('Ошибка сети: ' + error.message));
+}
+
+// История запусков: новые, изменившиеся и исчезнувшие закупки
+function showScheduleRuns(id) {
+    const row = document.getElementById('runs_' + id);
+    if (row.style.display !== 'none') {
+        row.style.display = 'none';
+        return;
+    }
+
+    fetch('/tender/schedules/' + id + '/runs')
+    .then(response => response.json())
+    .then(data => {
+        const runs = data.runs || [];
+        const items = runs.map(item => {
+            const run = item.run;
+            const summary = Object.entries(run.summary || {})
+                .map(([category, bySource]) => category + ': ' + Object.entries(bySource).map(([source, count]) => `${source} ${count}`).join(', '))
+                .join('; ');
+
+            return `
+                <li>
+                    ${new Date(run.started_at).toLocaleString('ru-RU')}:
+                    найдено ${run.found}, новых ${(run.new || []).length}${run.first_run ? ' (первый запуск)' : ''},
+                    изменилось ${(run.changed || []).length}, исчезло ${(run.gone || []).length}
+                    ${summary ? `<small class="text-muted">(${summary})</small>` : ''}
+                    ${run.partial ? '<span class="text-warning">неполный</span>' : ''}
+                    ${item.download_url ? `<a href="${item.download_url}">отчет</a>` : ''}
+                </li>
+            `;
+        }).join('');
+
+        row.querySelector('td').innerHTML = items ? `<ul class="mb-0 small">${items}</ul>` : '<span class="text-muted small">Запусков еще не было</span>';
+        row.style.display = '';
+    })
+    .catch(error => showError('Ошибка сети: ' + error.message));
+}
+
+// Правила отсева
+function loadRules() {
+    fetch('/tender/rules')
+    .then(response => response.json())
+    .then(data => {
+        if (data.error) {
+            showRulesStatus(false, data.error + (data.details ? ': ' + data.details : ''));
+            return;
+        }
+        document.getElementById('rulesText').value = data.text;
+        showRulesStatus(true, `Действует правил: ${(data.rules || []).length} (файл ${data.path})`);
+    })
+    .catch(error => console.log('Не удалось загрузить правила:', error));
+
+    loadRulesVersions();
+}
+
+function showRulesStatus(ok, message, errors = []) {
+    const status = document.getElementById('rulesStatus');
+    status.className = 'small mb-2 ' + (ok ? 'text-success' : 'text-danger');
+    status.textContent = message;
+
+    if (errors.length > 0) {
+        const list = document.createElement('ul');
+        list.className = 'mb-0';
+        errors.forEach(error => {
+            const item = document.createElement('li');
+            item.textContent = error;
+            list.appendChild(item);
+        });
+        status.appendChild(list);
+    }
+}
+
+function rulesRequest() {
+    return JSON.stringify({
+        text: document.getElementById('rulesText').value,
+        author: document.getElementById('rulesAuthor').value,
+        comment: document.getElementById('rulesComment').value
+    });
+}
+
+// Разбирает ответ на проверку или сохранение правил, при ошибках выводит их по строкам
+function handleRulesResponse(data, message) {
+    if (data.errors) {
+        showRulesStatus(false, 'Правила содержат ошибки:', data.errors);
+        return false;
+    }
+    if (data.error) {
+        showRulesStatus(false, data.error + (data.details ? ': ' + data.details : ''));
+        return false;
+    }
+    showRulesStatus(true, message + data.rules);
+    return true;
+}
+
+function validateRules() {
+    fetch('/tender/rules/validate', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: rulesRequest()
+    })
+    .then(response => response.json())
+    .then(data => handleRulesResponse(data, 'Ошибок нет, правил: '))
+    .catch(error => showRulesStatus(false, 'Ошибка сети: ' + error.message));
+}
+
+// Пробный прогон: что изменится для сохраненных закупок, если применить правила из редактора
+function dryRunRules() {
+    fetch('/tender/rules/dry-run', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: rulesRequest()
+    })
+    .then(response => response.json())
+    .then(data => {
+        document.getElementById('rulesDryRun').innerHTML = '';
+        if (handleRulesResponse(data, `Проверено закупок в базе: ${data.checked}, будут отсеяны: ${data.excluded}, перестанут отсеиваться: ${data.allowed}. Правил: `)) {
+            showRulesDryRun(data.categories || []);
+        }
+    })
+    .catch(error => showRulesStatus(false, 'Ошибка сети: ' + error.message));
+}
+
+function showRulesDryRun(categories) {
+    const container = document.getElementById('rulesDryRun');
+    const changed = categories.filter(category => category.excluded.length > 0 || category.allowed.length > 0);
+    if (changed.length === 0) {
+        container.innerHTML = '<p class="text-muted mb-0">Для сохраненных закупок ничего не изменится</p>';
+        return;
+    }
+
+    const row = (change, className) => {
+        const tr = document.createElement('tr');
+        tr.className = className;
+
+        const title = document.createElement('td');
+        const link = document.createElement('a');
+        link.href = change.tender.link;
+        link.target = '_blank';
+        link.textContent = change.tender.title;
+        title.appendChild(link);
+
+        const source = document.createElement('td');
+        source.textContent = change.source;
+        const reason = document.createElement('td');
+        reason.textContent = change.reason;
+
+        tr.append(title, source, reason);
+        return tr;
+    };
+
+    changed.forEach(category => {
+        const header = document.createElement('h6');
+        header.textContent = `${category.title}: будут отсеяны ${category.excluded.length}, перестанут отсеиваться ${category.allowed.length} (проверено ${category.checked})`;
+
+        const table = document.createElement('table');
+        table.className = 'table table-bordered table-sm small';
+        table.innerHTML = `
+            <thead class="table-light">
+                <tr><th>Закупка</th><th>Площадка</th><th>Правило</th></tr>
+            </thead>
+        `;
+        const body = document.createElement('tbody');
+        category.excluded.forEach(change => body.appendChild(row(change, 'table-danger')));
+        category.allowed.forEach(change => body.appendChild(row(change, 'table-success')));
+        table.appendChild(body);
+
+        container.append(header, table);
+    });
+}
+
+function saveRules() {
+    fetch('/tender/rules', {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: rulesRequest()
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (handleRulesResponse(data, 'Правила сохранены и применены, правил: ')) {
+            document.getElementById('rulesComment').value = '';
+            loadRulesVersions();
+        }
+    })
+    .catch(error => showRulesStatus(false, 'Ошибка сети: ' + error.message));
+}
+
+function loadRulesVersions() {
+    fetch('/tender/rules/versions')
+    .then(response => response.json())
+    .then(data => showRulesVersions(data.versions || []))
+    .catch(error => console.log('Не удалось загрузить историю правил:', error));
+}
+
+function showRulesVersions(versions) {
+    const listElement = document.getElementById('rulesVersions');
+    if (versions.length === 0) {
+        listElement.innerHTML = '<p class="text-muted mb-0">Изменений еще не было</p>';
+        return;
+    }
+
+    const table = document.createElement('table');
+    table.className = 'table table-bordered table-sm';
+    table.innerHTML = `
+        <thead class="table-light">
+            <tr><th>Версия</th><th>Когда</th><th>Кто</th><th>Комментарий</th><th></th></tr>
+        </thead>
+    `;
+
+    const body = document.createElement('tbody');
+    versions.forEach((version, index) => {
+        const row = document.createElement('tr');
+        [version.id, new Date(version.created_at).toLocaleString('ru-RU'), version.author, version.comment || ''].forEach(value => {
+            const cell = document.createElement('td');
+            cell.textContent = value;
+            row.appendChild(cell);
+        });
+
+        const actions = document.createElement('td');
+        actions.className = 'text-nowrap';
+        actions.innerHTML = `
+            <button class="btn btn-sm btn-outline-secondary" onclick="showRulesVersion(${version.id})" title="Открыть в редакторе"><i class="fas fa-eye"></i></button>
+            ${index > 0 ? `<button class="btn btn-sm btn-outline-primary" onclick="restoreRulesVersion(${version.id})" title="Восстановить"><i class="fas fa-undo"></i></button>` : ''}
+        `;
+        row.appendChild(actions);
+        body.appendChild(row);
+    });
+    table.appendChild(body);
+
+    listElement.innerHTML = '';
+    listElement.appendChild(table);
+}
+
+// Открывает текст версии в редакторе без сохранения
+function showRulesVersion(id) {
+    fetch('/tender/rules/versions/' + id)
+    .then(response => response.json())
+    .then(data => {
+        if (data.error) {
+            showRulesStatus(false, data.error);
+            return;
+        }
+        document.getElementById('rulesText').value = data.text;
+        showRulesStatus(true, `Открыта версия ${data.id}. Чтобы применить ее, нажмите "Сохранить"`);
+    })
+    .catch(error => showRulesStatus(false, 'Ошибка сети: ' + error.message));
+}
+
+function restoreRulesVersion(id) {
+    if (!confirm(`Восстановить правила версии ${id}?`)) {
+        return;
+    }
+
+    fetch('/tender/rules/versions/' + id + '/restore', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ author: document.getElementById('rulesAuthor').value })
+    })
+    .then(response => response.json())
+    .then(data => {
+        if (handleRulesResponse(data, 'Правила восстановлены, правил: ')) {
+            loadRules();
+        }
+    })
+    .catch(error => showRulesStatus(false, 'Ошибка сети: ' + error.message));
+}
+
+// Поиск по документации
+function searchDocuments() {
+    const query = document.getElementById('documentsQuery').value.trim();
+    const container = document.getElementById('documentsResults');
+    if (!query) {
+        container.innerHTML = '<p class="text-muted mb-0">Введите слова для поиска</p>';
+        return;
+    }
+
+    const params = new URLSearchParams({ q: query });
+    const category = document.getElementById('documentsCategory').value;
+    if (category) {
+        params.append('category', category);
+    }
+
+    fetch('/api/v1/documents-search?' + params.toString())
+    .then(response => response.json())
+    .then(data => {
+        if (data.error) {
+            container.innerHTML = '';
+            const error = document.createElement('p');
+            error.className = 'text-danger mb-0';
+            error.textContent = data.error + (data.details ? ': ' + data.details : '');
+            container.appendChild(error);
+            return;
+        }
+        showDocumentMatches(data.matches || [], data.total, query);
+    })
+    .catch(error => showError('Ошибка сети: ' + error.message));
+}
+
+// Выводит фрагмент с выделенными словами запроса
+function highlightSnippet(snippet, words) {
+    const element = document.createElement('div');
+    element.className = 'small text-muted';
+
+    const escaped = words.map(word => word.replace(/[.*+?^${}()|[\]\\]/g, '\\$&'));
+    const pattern = new RegExp('(^|[^\\p{L}\\p{N}])(' + escaped.join('|') + ')', 'giu');
+    let last = 0;
+    for (const match of snippet.matchAll(pattern)) {
+        const start = match.index + match[1].length;
+        element.appendChild(document.createTextNode(snippet.slice(last, start)));
+        const mark = document.createElement('mark');
+        mark.textContent = match[2];
+        element.appendChild(mark);
+        last = start + match[2].length;
+    }
+    element.appendChild(document.createTextNode(snippet.slice(last)));
+    return element;
+}
+
+function showDocumentMatches(matches, total, query) {
+    const container = document.getElementById('documentsResults');
+    container.innerHTML = '';
+    if (matches.length === 0) {
+        container.innerHTML = '<p class="text-muted mb-0">В документах ничего не найдено</p>';
+        return;
+    }
+
+    const words = query.toLowerCase().split(/[^\p{L}\p{N}]+/u).filter(word => word);
+    const header = document.createElement('h6');
+    header.textContent = `Найдено закупок: ${total}` + (total > matches.length ? `, показаны первые ${matches.length}` : '');
+    container.appendChild(header);
+
+    matches.forEach(match => {
+        const item = document.createElement('div');
+        item.className = 'border rounded p-2 mb-2';
+
+        const title = document.createElement('div');
+        const link = document.createElement('a');
+        link.href = match.tender.tender.link;
+        link.target = '_blank';
+        link.textContent = match.tender.tender.title;
+        title.appendChild(link);
+
+        const info = document.createElement('small');
+        info.className = 'text-muted ms-2';
+        info.textContent = `${match.tender.source}, ${(match.tender.categories || []).join(', ')}`;
+        title.appendChild(info);
+
+        const zip = document.createElement('a');
+        zip.href = match.zip_url;
+        zip.className = 'btn btn-sm btn-outline-secondary ms-2';
+        zip.title = 'Скачать все документы';
+        zip.innerHTML = '<i class="fas fa-file-archive"></i>';
+        title.appendChild(zip);
+        item.appendChild(title);
+
+        match.files.forEach(file => {
+            const name = document.createElement('div');
+            name.className = 'small mt-1';
+            name.textContent = `${file.path} (совпадений: ${file.hits})`;
+            item.appendChild(name);
+            file.snippets.forEach(snippet => item.appendChild(highlightSnippet(snippet, words)));
+        });
+
+        container.appendChild(item);
+    });
+}
+
+document.addEventListener('DOMContentLoaded', loadSchedules);
+document.addEventListener('DOMContentLoaded', loadRules);